@@ -567,13 +567,19 @@ func claudeImageBlockFromDataURL(imageURL string) ContentBlock {
 	}
 }
 
+// schemaCompat is applied to tool input schemas sent to Claude. Definitions
+// are inlined so that tool schemas never depend on "$ref" resolution.
+var schemaCompat = openai.SchemaCompat{
+	InlineRefs: true,
+}
+
 func (c *Client) convertTools(tools []openai.Tool) []ClaudeTool {
 	claudeTools := make([]ClaudeTool, len(tools))
 	for i, tool := range tools {
 		claudeTools[i] = ClaudeTool{
 			Name:        tool.Function.Name,
 			Description: tool.Function.Description,
-			InputSchema: tool.Function.Parameters,
+			InputSchema: openai.DowngradeSchema(tool.Function.Parameters, schemaCompat),
 		}
 	}
 	return claudeTools
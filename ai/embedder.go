@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paularlott/mcp"
+)
+
+// Embedder adapts a Client's CreateEmbedding to mcp.Embedder, so any provider
+// with an embeddings endpoint (OpenAI, Ollama, ...) can back semantic
+// tool_search.
+type Embedder struct {
+	client Client
+	model  string
+}
+
+// Ensure Embedder implements mcp.Embedder.
+var _ mcp.Embedder = (*Embedder)(nil)
+
+// NewEmbedder returns an mcp.Embedder that embeds text with the given model:
+//
+//	client, _ := ai.NewClient(ai.Config{Provider: ai.ProviderOllama})
+//	server.SetSemanticToolSearch(ai.NewEmbedder(client, "nomic-embed-text"))
+func NewEmbedder(client Client, model string) *Embedder {
+	return &Embedder{client: client, model: model}
+}
+
+// EmbeddingModel returns the model name.
+func (e *Embedder) EmbeddingModel() string {
+	return e.model
+}
+
+// Embed embeds all texts in a single request.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	resp, err := e.client.CreateEmbedding(ctx, EmbeddingRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding response has %d vectors for %d inputs", len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(texts) || vectors[d.Index] != nil {
+			return nil, fmt.Errorf("embedding response has an invalid index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// embeddingClient answers CreateEmbedding with vectors in reverse order, as
+// providers are free to do; the other Client methods are not used.
+type embeddingClient struct {
+	Client
+	req EmbeddingRequest
+}
+
+func (c *embeddingClient) CreateEmbedding(ctx context.Context, req EmbeddingRequest) (*EmbeddingResponse, error) {
+	c.req = req
+	texts := req.Input.([]string)
+	resp := &EmbeddingResponse{}
+	for i := len(texts) - 1; i >= 0; i-- {
+		resp.Data = append(resp.Data, Embedding{Index: i, Embedding: []float64{float64(len(texts[i]))}})
+	}
+	return resp, nil
+}
+
+func TestEmbedderOrdersVectorsByIndex(t *testing.T) {
+	client := &embeddingClient{}
+	embedder := NewEmbedder(client, "nomic-embed-text")
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "bbb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vectors, [][]float64{{1}, {3}}) {
+		t.Errorf("vectors not ordered by index: %v", vectors)
+	}
+	if client.req.Model != "nomic-embed-text" || embedder.EmbeddingModel() != "nomic-embed-text" {
+		t.Errorf("model not passed through: %+v", client.req)
+	}
+}
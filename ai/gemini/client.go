@@ -19,6 +19,21 @@ const (
 	providerName   = "gemini"
 )
 
+// schemaCompat rewrites tool input schemas into the OpenAPI subset accepted by
+// Gemini function declarations, which has no $ref, oneOf, map or type list
+// support.
+var schemaCompat = openai.SchemaCompat{
+	InlineRefs:    true,
+	MergeUnions:   true,
+	FlattenMaps:   true,
+	NullableTypes: true,
+	AllowedKeywords: []string{
+		"type", "format", "title", "description", "nullable", "enum", "default",
+		"properties", "required", "items", "minItems", "maxItems",
+		"minimum", "maximum", "minLength", "maxLength", "pattern",
+	},
+}
+
 type Client struct {
 	apiKey             string
 	baseURL            string
@@ -58,6 +73,7 @@ func New(config openai.Config) (*Client, error) {
 		RetryBackoff:        config.RetryBackoff,
 		RetryOnRateLimit:    config.RetryOnRateLimit,
 		RetryOnServerError:  config.RetryOnServerError,
+		SchemaCompat:        &schemaCompat,
 	})
 	if err != nil {
 		return nil, err
@@ -117,14 +133,30 @@ func (c *Client) SupportsCapability(cap string) bool {
 
 // ChatCompletion delegates to OpenAI client (uses /openai/ endpoint)
 func (c *Client) ChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
+	req.Tools = downgradeTools(req.Tools)
 	return c.chatClient.ChatCompletion(ctx, req)
 }
 
 // StreamChatCompletion delegates to OpenAI client (uses /openai/ endpoint)
 func (c *Client) StreamChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) *openai.ChatStream {
+	req.Tools = downgradeTools(req.Tools)
 	return c.chatClient.StreamChatCompletion(ctx, req)
 }
 
+// downgradeTools applies schemaCompat to caller-supplied tools. Tools the chat
+// client injects from MCP servers are downgraded by the chat client itself.
+func downgradeTools(tools []openai.Tool) []openai.Tool {
+	if len(tools) == 0 {
+		return tools
+	}
+	out := make([]openai.Tool, len(tools))
+	for i, tool := range tools {
+		tool.Function.Parameters = openai.DowngradeSchema(tool.Function.Parameters, schemaCompat)
+		out[i] = tool
+	}
+	return out
+}
+
 // GetModels fetches the list of available models from Gemini API
 func (c *Client) GetModels(ctx context.Context) (*openai.ModelsResponse, error) {
 	type geminiModelResponse struct {
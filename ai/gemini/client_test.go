@@ -0,0 +1,28 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+
+	"github.com/paularlott/mcp"
+	"github.com/paularlott/mcp/ai/openai"
+)
+
+func TestDowngradeToolsNullableFields(t *testing.T) {
+	type input struct {
+		Query string `json:"query" required:"true"`
+		Limit *int   `json:"limit"`
+	}
+	tool, _ := mcp.NewTypedTool("search", "Search", func(ctx context.Context, in input) (string, error) {
+		return "", nil
+	})
+	tools := downgradeTools(openai.MCPToolsToOpenAI([]mcp.MCPTool{tool.ToMCPTool()}))
+
+	props := tools[0].Function.Parameters["properties"].(map[string]any)
+	if limit := props["limit"].(map[string]any); limit["type"] != "integer" || limit["nullable"] != true {
+		t.Errorf("pointer field should be a nullable integer: %v", limit)
+	}
+	if query := props["query"].(map[string]any); query["type"] != "string" || query["nullable"] != nil {
+		t.Errorf("plain field should not be nullable: %v", query)
+	}
+}
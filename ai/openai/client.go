@@ -75,6 +75,7 @@ type Client struct {
 	retryBackoff       time.Duration // Base backoff for retries
 	retryOnRateLimit   bool          // Retry on 429
 	retryOnServerError bool          // Retry on 5xx
+	schemaCompat       SchemaCompat  // Schema rewriting applied to injected MCP tools
 }
 
 // RemoteServerConfig holds configuration for a remote MCP server
@@ -105,6 +106,7 @@ type Config struct {
 	RetryBackoff        time.Duration        // Base backoff duration for retry (omit for 1s, must be >= 0)
 	RetryOnRateLimit    *bool                // Whether to retry on 429 rate limit errors (omit for true)
 	RetryOnServerError  *bool                // Whether to retry on 5xx server errors (omit for true)
+	SchemaCompat        *SchemaCompat        // Schema rewriting for injected MCP tools (nil = OpenAISchemaCompat)
 }
 
 // New creates a new OpenAI client using the shared HTTP pool
@@ -170,6 +172,11 @@ func New(config Config) (*Client, error) {
 		retryOnServerError = *config.RetryOnServerError
 	}
 
+	schemaCompat := OpenAISchemaCompat
+	if config.SchemaCompat != nil {
+		schemaCompat = *config.SchemaCompat
+	}
+
 	// Ensure BaseURL has a trailing slash for proper URL resolution
 	if !strings.HasSuffix(config.BaseURL, "/") {
 		config.BaseURL = config.BaseURL + "/"
@@ -209,6 +216,7 @@ func New(config Config) (*Client, error) {
 		retryBackoff:       retryBackoff,
 		retryOnRateLimit:   retryOnRateLimit,
 		retryOnServerError: retryOnServerError,
+		schemaCompat:       schemaCompat,
 	}, nil
 }
 
@@ -321,7 +329,7 @@ func (c *Client) ChatCompletion(ctx context.Context, req ChatCompletionRequest)
 		// Add tools from all servers
 		tools, err := c.getAllTools(ctx)
 		if err == nil && len(tools) > 0 {
-			req.Tools = MCPToolsToOpenAI(tools)
+			req.Tools = MCPToolsToOpenAIWithCompat(tools, nil, c.schemaCompat)
 		}
 	}
 
@@ -449,7 +457,7 @@ func (c *Client) StreamChatCompletion(ctx context.Context, req ChatCompletionReq
 			tools, err := c.getAllTools(ctx)
 
 			if err == nil && hasServers && len(tools) > 0 {
-				req.Tools = MCPToolsToOpenAI(tools)
+				req.Tools = MCPToolsToOpenAIWithCompat(tools, nil, c.schemaCompat)
 			}
 		}
 
@@ -655,7 +663,7 @@ func (c *Client) createResponseSync(ctx context.Context, req CreateResponseReque
 		// Add tools from all servers
 		tools, err := c.getAllTools(ctx)
 		if err == nil && len(tools) > 0 {
-			req.Tools = MCPToolsToOpenAI(tools)
+			req.Tools = MCPToolsToOpenAIWithCompat(tools, nil, c.schemaCompat)
 		}
 	}
 
@@ -165,7 +165,7 @@ func (c *Client) streamResponseNative(ctx context.Context, req CreateResponseReq
 	if !requestHasTools {
 		tools, err := c.getAllTools(ctx)
 		if err == nil && len(tools) > 0 {
-			req.Tools = MCPToolsToOpenAI(tools)
+			req.Tools = MCPToolsToOpenAIWithCompat(tools, nil, c.schemaCompat)
 		}
 	}
 
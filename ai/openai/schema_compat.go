@@ -0,0 +1,351 @@
+package openai
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SchemaCompat describes how to rewrite a tool's JSON Schema for a provider
+// that only understands part of JSON Schema. The zero value leaves the schema
+// unchanged.
+type SchemaCompat struct {
+	// InlineRefs replaces local "$ref" pointers with the referenced
+	// "$defs"/"definitions" entry and drops the definitions. Recursive
+	// references are cut off with a plain object.
+	InlineRefs bool
+
+	// OneOfAsAnyOf rewrites "oneOf" as "anyOf".
+	OneOfAsAnyOf bool
+
+	// MergeUnions replaces "oneOf"/"anyOf" with a single schema: the union of
+	// the variants' properties when they are all objects, otherwise the first
+	// variant.
+	MergeUnions bool
+
+	// FlattenMaps replaces schema-valued "additionalProperties" (maps) with a
+	// plain object and describes the value type in the description instead.
+	FlattenMaps bool
+
+	// NullableTypes replaces a "type" list such as ["string", "null"] with
+	// the single type and "nullable": true, the OpenAPI 3.0 form. When the
+	// list has several non-null types the first is kept.
+	NullableTypes bool
+
+	// AllowedKeywords, when non-nil, drops every keyword not in the list. A
+	// "const" keyword is turned into a single-value "enum" when "const" is not
+	// allowed.
+	AllowedKeywords []string
+}
+
+// OpenAISchemaCompat is applied by MCPToolsToOpenAI. OpenAI function calling
+// accepts "anyOf" but not "oneOf".
+var OpenAISchemaCompat = SchemaCompat{
+	OneOfAsAnyOf: true,
+}
+
+// maxDowngradeDepth bounds recursion through nested and inlined schemas.
+const maxDowngradeDepth = 32
+
+// DowngradeSchema returns a copy of schema rewritten according to compat. The
+// input schema is not modified.
+func DowngradeSchema(schema map[string]any, compat SchemaCompat) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	d := &schemaDowngrader{compat: compat, active: make(map[string]bool)}
+	if compat.AllowedKeywords != nil {
+		d.allowed = make(map[string]bool, len(compat.AllowedKeywords))
+		for _, k := range compat.AllowedKeywords {
+			d.allowed[k] = true
+		}
+	}
+	if compat.InlineRefs {
+		d.root = schema
+	}
+	return d.convert(schema, 0)
+}
+
+type schemaDowngrader struct {
+	compat  SchemaCompat
+	allowed map[string]bool
+	root    map[string]any
+	active  map[string]bool // refs being inlined, to cut off recursion
+}
+
+func (d *schemaDowngrader) convert(schema map[string]any, depth int) map[string]any {
+	if depth > maxDowngradeDepth {
+		return map[string]any{"type": "object"}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok && d.compat.InlineRefs {
+		return d.inlineRef(ref, schema, depth)
+	}
+
+	out := make(map[string]any, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "$defs", "definitions":
+			if d.compat.InlineRefs {
+				continue
+			}
+			out[key] = d.convertMap(value, depth)
+		case "properties", "patternProperties":
+			out[key] = d.convertMap(value, depth)
+		case "items", "additionalProperties", "not":
+			out[key] = d.convertValue(value, depth)
+		case "oneOf", "anyOf", "allOf", "prefixItems":
+			out[key] = d.convertList(value, depth)
+		default:
+			out[key] = value
+		}
+	}
+
+	if d.compat.NullableTypes {
+		if types, ok := out["type"].([]any); ok {
+			nonNull := slices.DeleteFunc(slices.Clone(types), func(t any) bool { return t == "null" })
+			if len(nonNull) > 0 {
+				out["type"] = nonNull[0]
+				if len(nonNull) < len(types) {
+					out["nullable"] = true
+				}
+			}
+		}
+	}
+
+	if d.compat.OneOfAsAnyOf {
+		if oneOf, ok := out["oneOf"]; ok {
+			if _, exists := out["anyOf"]; !exists {
+				out["anyOf"] = oneOf
+				delete(out, "oneOf")
+			}
+		}
+	}
+
+	if d.compat.MergeUnions {
+		for _, key := range []string{"oneOf", "anyOf"} {
+			variants, ok := out[key].([]any)
+			if !ok {
+				continue
+			}
+			delete(out, key)
+			for k, v := range mergeVariants(variants) {
+				if _, exists := out[k]; !exists {
+					out[k] = v
+				}
+			}
+		}
+	}
+
+	if d.compat.FlattenMaps {
+		if value, ok := out["additionalProperties"].(map[string]any); ok {
+			delete(out, "additionalProperties")
+			out["type"] = "object"
+			hint := "object with arbitrary keys"
+			if t, ok := value["type"].(string); ok {
+				hint += " and " + t + " values"
+			}
+			if desc, ok := out["description"].(string); ok && desc != "" {
+				out["description"] = desc + " (" + hint + ")"
+			} else {
+				out["description"] = strings.ToUpper(hint[:1]) + hint[1:]
+			}
+		}
+	}
+
+	if d.allowed != nil {
+		if c, ok := out["const"]; ok && !d.allowed["const"] {
+			if _, exists := out["enum"]; !exists {
+				out["enum"] = []any{c}
+			}
+		}
+		for key := range out {
+			if !d.allowed[key] {
+				delete(out, key)
+			}
+		}
+	}
+
+	return out
+}
+
+// inlineRef replaces a "$ref" with the definition it points to. Sibling
+// keywords of the "$ref" (such as a description) take precedence.
+func (d *schemaDowngrader) inlineRef(ref string, schema map[string]any, depth int) map[string]any {
+	target := d.resolveRef(ref)
+	if target == nil || d.active[ref] {
+		// Unknown or recursive reference; fall back to a generic object.
+		fallback := map[string]any{"type": "object"}
+		if desc, ok := schema["description"]; ok {
+			fallback["description"] = desc
+		}
+		return fallback
+	}
+
+	merged := make(map[string]any, len(target)+len(schema))
+	for k, v := range target {
+		merged[k] = v
+	}
+	for k, v := range schema {
+		if k != "$ref" {
+			merged[k] = v
+		}
+	}
+
+	d.active[ref] = true
+	defer delete(d.active, ref)
+	return d.convert(merged, depth+1)
+}
+
+// resolveRef resolves a local "#/$defs/name" or "#/definitions/name" pointer.
+func (d *schemaDowngrader) resolveRef(ref string) map[string]any {
+	for _, key := range []string{"$defs", "definitions"} {
+		if name, ok := strings.CutPrefix(ref, "#/"+key+"/"); ok {
+			defs, _ := d.root[key].(map[string]any)
+			target, _ := defs[name].(map[string]any)
+			return target
+		}
+	}
+	return nil
+}
+
+func (d *schemaDowngrader) convertValue(value any, depth int) any {
+	if m, ok := value.(map[string]any); ok {
+		return d.convert(m, depth+1)
+	}
+	if list, ok := value.([]any); ok {
+		return d.convertList(list, depth)
+	}
+	return value
+}
+
+func (d *schemaDowngrader) convertMap(value any, depth int) any {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = d.convertValue(v, depth)
+	}
+	return out
+}
+
+func (d *schemaDowngrader) convertList(value any, depth int) any {
+	list, ok := value.([]any)
+	if !ok {
+		return value
+	}
+	out := make([]any, len(list))
+	for i, v := range list {
+		out[i] = d.convertValue(v, depth)
+	}
+	return out
+}
+
+// mergeVariants collapses union variants into one schema. Object variants are
+// merged into a single object with the union of their properties; a property
+// is only required when every variant requires it, and a property declared
+// with an enum in several variants (such as a discriminator) accepts the
+// values of all of them. Otherwise the first variant is used.
+func mergeVariants(variants []any) map[string]any {
+	var objects []map[string]any
+	for _, v := range variants {
+		m, ok := v.(map[string]any)
+		if !ok || m["type"] != "object" {
+			objects = nil
+			break
+		}
+		objects = append(objects, m)
+	}
+
+	if len(objects) == 0 {
+		if len(variants) > 0 {
+			if first, ok := variants[0].(map[string]any); ok {
+				return first
+			}
+		}
+		return map[string]any{}
+	}
+
+	properties := make(map[string]any)
+	requiredCount := make(map[string]int)
+	var order []string
+	for _, obj := range objects {
+		if props, ok := obj["properties"].(map[string]any); ok {
+			for name, prop := range props {
+				if existing, exists := properties[name]; exists {
+					properties[name] = mergeEnums(existing, prop)
+				} else {
+					properties[name] = prop
+				}
+			}
+		}
+		for _, name := range requiredNames(obj["required"]) {
+			if requiredCount[name] == 0 {
+				order = append(order, name)
+			}
+			requiredCount[name]++
+		}
+	}
+
+	merged := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	var required []any
+	for _, name := range order {
+		if requiredCount[name] == len(objects) {
+			required = append(required, name)
+		}
+	}
+	if len(required) > 0 {
+		merged["required"] = required
+	}
+	return merged
+}
+
+func requiredNames(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []any:
+		names := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// mergeEnums returns a with its enum extended by the values of b's enum when
+// both property schemas are enums; otherwise a is returned unchanged.
+func mergeEnums(a, b any) any {
+	am, ok := a.(map[string]any)
+	if !ok {
+		return a
+	}
+	aEnum, aOK := am["enum"].([]any)
+	bm, _ := b.(map[string]any)
+	bEnum, bOK := bm["enum"].([]any)
+	if !aOK || !bOK {
+		return a
+	}
+
+	merged := make(map[string]any, len(am))
+	for k, v := range am {
+		merged[k] = v
+	}
+	values := append([]any{}, aEnum...)
+	for _, v := range bEnum {
+		// Enum values may be objects or arrays, which == cannot compare.
+		if !slices.ContainsFunc(values, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+			values = append(values, v)
+		}
+	}
+	merged["enum"] = values
+	return merged
+}
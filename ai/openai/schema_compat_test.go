@@ -0,0 +1,163 @@
+package openai
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/paularlott/mcp"
+)
+
+func compositeTool() mcp.MCPTool {
+	return mcp.NewTool("draw", "Draw shapes",
+		mcp.Def("point", mcp.Object("", "",
+			mcp.Number("x", "x", mcp.Required()),
+			mcp.Number("y", "y", mcp.Required()),
+		)),
+		mcp.OneOf("shape", "Shape",
+			mcp.Object("", "",
+				mcp.String("kind", "", mcp.Enum("circle"), mcp.Required()),
+				mcp.Ref("center", "Centre", "point", mcp.Required()),
+			),
+			mcp.Object("", "",
+				mcp.String("kind", "", mcp.Enum("square"), mcp.Required()),
+				mcp.Number("side", "Side", mcp.ExclusiveMinimum(0)),
+			),
+		),
+		mcp.Map("labels", "Labels", mcp.String("", "")),
+	).ToMCPTool()
+}
+
+func TestMCPToolsToOpenAIRewritesOneOf(t *testing.T) {
+	tool := compositeTool()
+	params := MCPToolsToOpenAI([]mcp.MCPTool{tool})[0].Function.Parameters
+
+	shape := params["properties"].(map[string]any)["shape"].(map[string]any)
+	if _, ok := shape["oneOf"]; ok {
+		t.Fatalf("oneOf should be rewritten: %v", shape)
+	}
+	if len(shape["anyOf"].([]any)) != 2 {
+		t.Fatalf("expected anyOf with two variants: %v", shape)
+	}
+	if _, ok := params["$defs"]; !ok {
+		t.Error("OpenAI profile should keep $defs")
+	}
+
+	original := tool.InputSchema.(map[string]any)["properties"].(map[string]any)["shape"].(map[string]any)
+	if _, ok := original["oneOf"]; !ok {
+		t.Error("the MCP tool schema must not be modified")
+	}
+}
+
+func TestDowngradeSchemaLimitedProvider(t *testing.T) {
+	compat := SchemaCompat{
+		InlineRefs:      true,
+		MergeUnions:     true,
+		FlattenMaps:     true,
+		AllowedKeywords: []string{"type", "description", "enum", "properties", "required", "items"},
+	}
+	params := DowngradeSchema(compositeTool().InputSchema.(map[string]any), compat)
+
+	if _, ok := params["$defs"]; ok {
+		t.Error("$defs should be dropped once refs are inlined")
+	}
+	if _, ok := params["additionalProperties"]; ok {
+		t.Error("disallowed keywords should be dropped")
+	}
+
+	shape := params["properties"].(map[string]any)["shape"].(map[string]any)
+	if shape["type"] != "object" || shape["description"] != "Shape" {
+		t.Fatalf("expected merged object, got %v", shape)
+	}
+	props := shape["properties"].(map[string]any)
+	if !reflect.DeepEqual(props["kind"].(map[string]any)["enum"], []any{"circle", "square"}) {
+		t.Errorf("discriminator enums should be merged: %v", props["kind"])
+	}
+	if !reflect.DeepEqual(shape["required"], []any{"kind"}) {
+		t.Errorf("only properties required by every variant stay required: %v", shape["required"])
+	}
+	center := props["center"].(map[string]any)
+	if center["type"] != "object" || center["description"] != "Centre" || center["properties"].(map[string]any)["x"] == nil {
+		t.Errorf("ref should be inlined: %v", center)
+	}
+	if _, ok := props["side"].(map[string]any)["exclusiveMinimum"]; ok {
+		t.Errorf("exclusiveMinimum is not allowed: %v", props["side"])
+	}
+
+	labels := params["properties"].(map[string]any)["labels"].(map[string]any)
+	if labels["type"] != "object" || labels["description"] != "Labels (object with arbitrary keys and string values)" {
+		t.Errorf("unexpected flattened map: %v", labels)
+	}
+}
+
+func TestDowngradeSchemaRecursiveRef(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"$defs": map[string]any{
+			"node": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"children": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/node"}},
+				},
+			},
+		},
+		"properties": map[string]any{"root": map[string]any{"$ref": "#/$defs/node"}},
+	}
+	out := DowngradeSchema(schema, SchemaCompat{InlineRefs: true})
+	root := out["properties"].(map[string]any)["root"].(map[string]any)
+	items := root["properties"].(map[string]any)["children"].(map[string]any)["items"].(map[string]any)
+	if !reflect.DeepEqual(items, map[string]any{"type": "object"}) {
+		t.Fatalf("recursive ref should be cut off, got %v", items)
+	}
+}
+
+func TestDowngradeSchemaMergesObjectEnums(t *testing.T) {
+	origin := map[string]any{"x": 0.0, "y": 0.0}
+	variant := func(values ...any) map[string]any {
+		return map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"at": map[string]any{"enum": values}},
+		}
+	}
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"point": map[string]any{"oneOf": []any{
+				variant(origin, []any{1.0, 2.0}),
+				variant(map[string]any{"x": 0.0, "y": 0.0}, []any{3.0}),
+			}},
+		},
+	}
+	out := DowngradeSchema(schema, SchemaCompat{MergeUnions: true})
+	point := out["properties"].(map[string]any)["point"].(map[string]any)
+	enum := point["properties"].(map[string]any)["at"].(map[string]any)["enum"].([]any)
+	if len(enum) != 3 {
+		t.Fatalf("expected the duplicate object to be merged away, got %v", enum)
+	}
+}
+
+func TestDowngradeSchemaNullableTypes(t *testing.T) {
+	type note struct {
+		Text string `json:"text"`
+	}
+	type input struct {
+		Limit *int     `json:"limit"`
+		Tags  []string `json:"tags"`
+		Note  *note    `json:"note"`
+	}
+	tool, _ := mcp.NewTypedTool("find", "Find things", func(ctx context.Context, in input) (string, error) {
+		return "", nil
+	})
+	out := DowngradeSchema(tool.ToMCPTool().InputSchema.(map[string]any), SchemaCompat{NullableTypes: true})
+
+	props := out["properties"].(map[string]any)
+	for name, want := range map[string]string{"limit": "integer", "tags": "array", "note": "object"} {
+		prop := props[name].(map[string]any)
+		if prop["type"] != want || prop["nullable"] != true {
+			t.Errorf("%s: got %v, want type %q and nullable", name, prop, want)
+		}
+	}
+	if items := props["tags"].(map[string]any)["items"].(map[string]any); items["type"] != "string" || items["nullable"] != nil {
+		t.Errorf("non-null items should be left alone: %v", items)
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"github.com/paularlott/mcp"
 )
 
-// MCPToolsToOpenAI converts MCP tools to OpenAI function calling format
+// MCPToolsToOpenAI converts MCP tools to OpenAI function calling format.
+// Input schemas are downgraded with OpenAISchemaCompat.
 func MCPToolsToOpenAI(tools []mcp.MCPTool) []Tool {
 	return MCPToolsToOpenAIFiltered(tools, nil)
 }
@@ -12,6 +13,12 @@ func MCPToolsToOpenAI(tools []mcp.MCPTool) []Tool {
 // MCPToolsToOpenAIFiltered converts MCP tools to OpenAI format with optional filtering.
 // If filter is nil, all tools are included. Otherwise, only tools where filter(name) returns true are included.
 func MCPToolsToOpenAIFiltered(tools []mcp.MCPTool, filter func(name string) bool) []Tool {
+	return MCPToolsToOpenAIWithCompat(tools, filter, OpenAISchemaCompat)
+}
+
+// MCPToolsToOpenAIWithCompat converts MCP tools to OpenAI format, rewriting
+// each input schema with compat for providers with limited JSON Schema support.
+func MCPToolsToOpenAIWithCompat(tools []mcp.MCPTool, filter func(name string) bool, compat SchemaCompat) []Tool {
 	var openAITools []Tool
 
 	for _, tool := range tools {
@@ -23,7 +30,7 @@ func MCPToolsToOpenAIFiltered(tools []mcp.MCPTool, filter func(name string) bool
 		var parameters map[string]any
 		if tool.InputSchema != nil {
 			if params, ok := tool.InputSchema.(map[string]any); ok {
-				parameters = params
+				parameters = DowngradeSchema(params, compat)
 			} else {
 				parameters = make(map[string]any)
 			}
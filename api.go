@@ -9,7 +9,7 @@ type Parameter interface {
 
 // Option interface for parameter options
 type Option interface {
-	applyToParam(param parameterBase)
+	applyToParam(param *parameterBase)
 }
 
 // Base parameter structure
@@ -17,33 +17,51 @@ type parameterBase struct {
 	name        string
 	description string
 	required    bool
+	constraints paramConstraints
+}
+
+// newParameterBase builds a parameterBase and applies the given options to it.
+func newParameterBase(name, description string, options []Option) parameterBase {
+	base := parameterBase{name: name, description: description}
+	for _, opt := range options {
+		if opt != nil {
+			opt.applyToParam(&base)
+		}
+	}
+	return base
 }
 
 // Parameter builder for constructing schemas
 type paramBuilder struct {
 	params       []paramDef
 	outputParams []paramDef
+	defs         []paramDef
 }
 
 // Required option
 type requiredOption struct{}
 
-func (r requiredOption) applyToParam(param parameterBase) {
-	// This will be handled by each parameter type
+func (r requiredOption) applyToParam(param *parameterBase) {
+	param.required = true
 }
 
 func Required() Option {
 	return requiredOption{}
 }
 
-// processOptions applies options to a parameterBase and returns true if required
-func processOptions(options []Option) bool {
-	for _, opt := range options {
-		if _, ok := opt.(requiredOption); ok {
-			return true
+// splitPropertiesAndOptions separates the mixed variadic arguments accepted by
+// Object and ObjectArray into nested parameters and options.
+func splitPropertiesAndOptions(propertiesAndOptions []any) ([]Parameter, []Option) {
+	var properties []Parameter
+	var options []Option
+	for _, item := range propertiesAndOptions {
+		if param, ok := item.(Parameter); ok {
+			properties = append(properties, param)
+		} else if opt, ok := item.(Option); ok {
+			options = append(options, opt)
 		}
 	}
-	return false
+	return properties, options
 }
 
 // buildPropertiesFromParams builds a properties map from a slice of Parameter
@@ -68,6 +86,7 @@ func (s *stringParam) toParamDef() paramDef {
 		description: s.description,
 		required:    s.required,
 		properties:  make(map[string]*paramDef),
+		constraints: s.constraints,
 	}
 }
 
@@ -86,6 +105,7 @@ func (n *numberParam) toParamDef() paramDef {
 		description: n.description,
 		required:    n.required,
 		properties:  make(map[string]*paramDef),
+		constraints: n.constraints,
 	}
 }
 
@@ -104,6 +124,7 @@ func (n *integerParam) toParamDef() paramDef {
 		description: n.description,
 		required:    n.required,
 		properties:  make(map[string]*paramDef),
+		constraints: n.constraints,
 	}
 }
 
@@ -122,6 +143,7 @@ func (b *booleanParam) toParamDef() paramDef {
 		description: b.description,
 		required:    b.required,
 		properties:  make(map[string]*paramDef),
+		constraints: b.constraints,
 	}
 }
 
@@ -140,6 +162,7 @@ func (s *stringArrayParam) toParamDef() paramDef {
 		description: s.description,
 		required:    s.required,
 		properties:  make(map[string]*paramDef),
+		constraints: s.constraints,
 	}
 }
 
@@ -158,6 +181,7 @@ func (n *numberArrayParam) toParamDef() paramDef {
 		description: n.description,
 		required:    n.required,
 		properties:  make(map[string]*paramDef),
+		constraints: n.constraints,
 	}
 }
 
@@ -176,6 +200,7 @@ func (n *integerArrayParam) toParamDef() paramDef {
 		description: n.description,
 		required:    n.required,
 		properties:  make(map[string]*paramDef),
+		constraints: n.constraints,
 	}
 }
 
@@ -194,6 +219,7 @@ func (b *booleanArrayParam) toParamDef() paramDef {
 		description: b.description,
 		required:    b.required,
 		properties:  make(map[string]*paramDef),
+		constraints: b.constraints,
 	}
 }
 
@@ -213,6 +239,7 @@ func (o *objectParam) toParamDef() paramDef {
 		description: o.description,
 		required:    o.required,
 		properties:  buildPropertiesFromParams(o.properties),
+		constraints: o.constraints,
 	}
 }
 
@@ -237,6 +264,7 @@ func (o *objectArrayParam) toParamDef() paramDef {
 		description: o.description,
 		required:    o.required,
 		itemSchema:  itemSchema,
+		constraints: o.constraints,
 	}
 }
 
@@ -244,6 +272,95 @@ func (o *objectArrayParam) apply(builder *paramBuilder) {
 	builder.params = append(builder.params, o.toParamDef())
 }
 
+// unionParam is a value matching one (oneOf) or at least one (anyOf) of
+// several variant schemas
+type unionParam struct {
+	parameterBase
+	kind     string // "oneOf" or "anyOf"
+	variants []Parameter
+}
+
+func (u *unionParam) toParamDef() paramDef {
+	variants := make([]*paramDef, len(u.variants))
+	for i, variant := range u.variants {
+		def := variant.toParamDef()
+		variants[i] = &def
+	}
+	return paramDef{
+		name:        u.name,
+		paramType:   u.kind,
+		description: u.description,
+		required:    u.required,
+		variants:    variants,
+		constraints: u.constraints,
+	}
+}
+
+func (u *unionParam) apply(builder *paramBuilder) {
+	builder.params = append(builder.params, u.toParamDef())
+}
+
+// containerParam is an array or map whose elements are described by another
+// parameter
+type containerParam struct {
+	parameterBase
+	kind string // "array" or "map"
+	item Parameter
+}
+
+func (c *containerParam) toParamDef() paramDef {
+	item := c.item.toParamDef()
+	return paramDef{
+		name:        c.name,
+		paramType:   c.kind,
+		description: c.description,
+		required:    c.required,
+		itemSchema:  &item,
+		constraints: c.constraints,
+	}
+}
+
+func (c *containerParam) apply(builder *paramBuilder) {
+	builder.params = append(builder.params, c.toParamDef())
+}
+
+type refParam struct {
+	parameterBase
+	definition string
+}
+
+func (r *refParam) toParamDef() paramDef {
+	return paramDef{
+		name:        r.name,
+		paramType:   "ref",
+		description: r.description,
+		required:    r.required,
+		ref:         r.definition,
+		constraints: r.constraints,
+	}
+}
+
+func (r *refParam) apply(builder *paramBuilder) {
+	builder.params = append(builder.params, r.toParamDef())
+}
+
+// Definition wrapper, emitted under $defs
+type defParam struct {
+	name   string
+	schema Parameter
+}
+
+// toParamDef is not applicable for defParam as it's a container
+func (d *defParam) toParamDef() paramDef {
+	return paramDef{} // Not used directly
+}
+
+func (d *defParam) apply(builder *paramBuilder) {
+	def := d.schema.toParamDef()
+	def.name = d.name
+	builder.defs = append(builder.defs, def)
+}
+
 // Output wrapper
 type outputParam struct {
 	parameters []Parameter
@@ -268,11 +385,7 @@ func Output(parameters ...Parameter) Parameter {
 // String creates a string parameter
 func String(name, description string, options ...Option) Parameter {
 	return &stringParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    processOptions(options),
-		},
+		parameterBase: newParameterBase(name, description, options),
 	}
 }
 
@@ -280,11 +393,7 @@ func String(name, description string, options ...Option) Parameter {
 // Emitted as JSON Schema {"type": "number"}.
 func Number(name, description string, options ...Option) Parameter {
 	return &numberParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    processOptions(options),
-		},
+		parameterBase: newParameterBase(name, description, options),
 	}
 }
 
@@ -292,33 +401,21 @@ func Number(name, description string, options ...Option) Parameter {
 // Emitted as JSON Schema {"type": "integer"}.
 func Integer(name, description string, options ...Option) Parameter {
 	return &integerParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    processOptions(options),
-		},
+		parameterBase: newParameterBase(name, description, options),
 	}
 }
 
 // Boolean creates a boolean parameter
 func Boolean(name, description string, options ...Option) Parameter {
 	return &booleanParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    processOptions(options),
-		},
+		parameterBase: newParameterBase(name, description, options),
 	}
 }
 
 // StringArray creates a string array parameter
 func StringArray(name, description string, options ...Option) Parameter {
 	return &stringArrayParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    processOptions(options),
-		},
+		parameterBase: newParameterBase(name, description, options),
 	}
 }
 
@@ -326,11 +423,7 @@ func StringArray(name, description string, options ...Option) Parameter {
 // Emitted as JSON Schema {"type": "array", "items": {"type": "number"}}.
 func NumberArray(name, description string, options ...Option) Parameter {
 	return &numberArrayParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    processOptions(options),
-		},
+		parameterBase: newParameterBase(name, description, options),
 	}
 }
 
@@ -338,73 +431,99 @@ func NumberArray(name, description string, options ...Option) Parameter {
 // Emitted as JSON Schema {"type": "array", "items": {"type": "integer"}}.
 func IntegerArray(name, description string, options ...Option) Parameter {
 	return &integerArrayParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    processOptions(options),
-		},
+		parameterBase: newParameterBase(name, description, options),
 	}
 }
 
 // BooleanArray creates a boolean array parameter
 func BooleanArray(name, description string, options ...Option) Parameter {
 	return &booleanArrayParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    processOptions(options),
-		},
+		parameterBase: newParameterBase(name, description, options),
 	}
 }
 
 // Object creates an object parameter with properties
 func Object(name, description string, propertiesAndOptions ...any) Parameter {
-	var properties []Parameter
-	required := false
-
-	// Separate properties from options
-	for _, item := range propertiesAndOptions {
-		if param, ok := item.(Parameter); ok {
-			properties = append(properties, param)
-		} else if _, ok := item.(requiredOption); ok {
-			required = true
-		}
-	}
-
+	properties, options := splitPropertiesAndOptions(propertiesAndOptions)
 	return &objectParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    required,
-		},
-		properties: properties,
+		parameterBase: newParameterBase(name, description, options),
+		properties:    properties,
 	}
 }
 
 // ObjectArray creates an array of objects parameter
 func ObjectArray(name, description string, propertiesAndOptions ...any) Parameter {
-	var properties []Parameter
-	required := false
+	properties, options := splitPropertiesAndOptions(propertiesAndOptions)
+	return &objectArrayParam{
+		parameterBase: newParameterBase(name, description, options),
+		properties:    properties,
+	}
+}
 
-	// Separate properties from options
-	for _, item := range propertiesAndOptions {
-		if param, ok := item.(Parameter); ok {
-			properties = append(properties, param)
-		} else if _, ok := item.(requiredOption); ok {
-			required = true
-		}
+// OneOf creates a parameter that must match exactly one of the given variants,
+// e.g. a discriminated union of objects. Variants are parameters whose names
+// are ignored; options such as Required() may be mixed in.
+// Emitted as JSON Schema {"oneOf": [...]}.
+func OneOf(name, description string, variantsAndOptions ...any) Parameter {
+	variants, options := splitPropertiesAndOptions(variantsAndOptions)
+	return &unionParam{
+		parameterBase: newParameterBase(name, description, options),
+		kind:          "oneOf",
+		variants:      variants,
 	}
+}
 
-	return &objectArrayParam{
-		parameterBase: parameterBase{
-			name:        name,
-			description: description,
-			required:    required,
-		},
-		properties: properties,
+// AnyOf creates a parameter that must match at least one of the given
+// variants. Variants are parameters whose names are ignored; options such as
+// Required() may be mixed in.
+// Emitted as JSON Schema {"anyOf": [...]}.
+func AnyOf(name, description string, variantsAndOptions ...any) Parameter {
+	variants, options := splitPropertiesAndOptions(variantsAndOptions)
+	return &unionParam{
+		parameterBase: newParameterBase(name, description, options),
+		kind:          "anyOf",
+		variants:      variants,
+	}
+}
+
+// ArrayOf creates an array parameter whose elements are described by item
+// (whose name is ignored). Items may themselves be arrays, maps, unions or refs.
+// Emitted as JSON Schema {"type": "array", "items": {...}}.
+func ArrayOf(name, description string, item Parameter, options ...Option) Parameter {
+	return &containerParam{
+		parameterBase: newParameterBase(name, description, options),
+		kind:          "array",
+		item:          item,
 	}
 }
 
+// Map creates an object parameter with arbitrary keys whose values are
+// described by value (whose name is ignored).
+// Emitted as JSON Schema {"type": "object", "additionalProperties": {...}}.
+func Map(name, description string, value Parameter, options ...Option) Parameter {
+	return &containerParam{
+		parameterBase: newParameterBase(name, description, options),
+		kind:          "map",
+		item:          value,
+	}
+}
+
+// Ref creates a parameter that reuses a schema declared with Def.
+// Emitted as JSON Schema {"$ref": "#/$defs/<definition>"}.
+func Ref(name, description, definition string, options ...Option) Parameter {
+	return &refParam{
+		parameterBase: newParameterBase(name, description, options),
+		definition:    definition,
+	}
+}
+
+// Def declares a reusable schema under $defs that parameters can point to
+// with Ref. The name of schema itself is ignored. Definitions are emitted in
+// both the input and the output schema.
+func Def(name string, schema Parameter) Parameter {
+	return &defParam{name: name, schema: schema}
+}
+
 // NewTool creates a new tool with the declarative API
 func NewTool(name, description string, parameters ...Parameter) *ToolBuilder {
 	builder := &paramBuilder{}
@@ -418,5 +537,6 @@ func NewTool(name, description string, parameters ...Parameter) *ToolBuilder {
 		description:  description,
 		params:       builder.params,
 		outputParams: builder.outputParams,
+		defs:         builder.defs,
 	}
 }
@@ -0,0 +1,309 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/paularlott/mcp/pool"
+)
+
+// DefaultApprovalTimeout is how long a call waits for its approver, when
+// ApprovalConfig does not say, before it is rejected.
+const DefaultApprovalTimeout = 5 * time.Minute
+
+// errNoElicitation is returned by the elicitation approver when the client
+// cannot be asked.
+var errNoElicitation = errors.New("client does not support elicitation")
+
+// ApprovalRequest describes a tool call waiting for approval.
+type ApprovalRequest struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Message   string         `json:"message,omitempty"`   // From the tool's ApprovalConfig
+	SessionID string         `json:"sessionId,omitempty"` // MCP session of the call, if any
+	Principal string         `json:"principal,omitempty"` // ID of the principal on the request, if any
+}
+
+// ApprovalDecision is an approver's answer.
+type ApprovalDecision struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Approver decides whether a tool call may go ahead. Approve may block while a
+// human decides; it should return when ctx is done. An error rejects the call.
+type Approver interface {
+	Approve(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error)
+}
+
+// ApproverFunc adapts a function to the Approver interface, for approvals
+// decided in-process.
+type ApproverFunc func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error)
+
+// Approve calls f.
+func (f ApproverFunc) Approve(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+	return f(ctx, req)
+}
+
+// ApprovalConfig makes a tool wait for approval before each call. Set it with
+// ToolBuilder.RequireApproval.
+type ApprovalConfig struct {
+	// Approver decides. Nil uses the server's approver (see SetApprover).
+	Approver Approver
+
+	// Message is passed to the approver to explain what is being approved.
+	Message string
+
+	// TTL caches an approval for the rest of the session for this long, so
+	// later calls to the tool in the same session go ahead without asking.
+	// Zero asks for every call. Calls without a session are never cached.
+	TTL time.Duration
+
+	// Timeout rejects the call if the approver has not decided by then. Zero
+	// uses DefaultApprovalTimeout.
+	Timeout time.Duration
+}
+
+// ApprovalRecord is one approval decision, kept for audit.
+type ApprovalRecord struct {
+	Time      time.Time `json:"time"`
+	Tool      string    `json:"tool"`
+	SessionID string    `json:"sessionId,omitempty"`
+	Principal string    `json:"principal,omitempty"`
+	Approved  bool      `json:"approved"`
+	Reason    string    `json:"reason,omitempty"`
+	Cached    bool      `json:"cached,omitempty"` // Approved by an earlier approval in the session
+}
+
+// SetApprover sets the approver for tools that require approval without
+// naming their own. Without one, such calls are rejected.
+func (s *Server) SetApprover(approver Approver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.approver = approver
+}
+
+// SetApprovalRecorder sets a function called with every approval decision,
+// including those answered from the session cache, for audit. It must not
+// block.
+func (s *Server) SetApprovalRecorder(record func(ApprovalRecord)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.approvalRecorder = record
+}
+
+// approve asks for approval of a call to the tool called name and records the
+// decision. It returns the isError response to send if the call is rejected,
+// or nil if it may go ahead.
+func (s *Server) approve(ctx context.Context, name string, cfg *ApprovalConfig, args map[string]any) *ToolResponse {
+	req := ApprovalRequest{
+		Tool:      name,
+		Arguments: args,
+		Message:   cfg.Message,
+		SessionID: sessionIDFromContext(ctx),
+	}
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		req.Principal = principal.ID
+	}
+
+	s.mu.RLock()
+	approver := cfg.Approver
+	if approver == nil {
+		approver = s.approver
+	}
+	record := s.approvalRecorder
+	audit := s.audit
+	s.mu.RUnlock()
+
+	start := time.Now()
+	decision, cached := s.decideApproval(ctx, approver, cfg, req)
+	if audit != nil && audit.recordApproval(ctx, name, args, start, decision) != nil {
+		decision = ApprovalDecision{Reason: "the decision could not be recorded in the audit log"}
+	}
+	if record != nil {
+		record(ApprovalRecord{
+			Time:      time.Now(),
+			Tool:      name,
+			SessionID: req.SessionID,
+			Principal: req.Principal,
+			Approved:  decision.Approved,
+			Reason:    decision.Reason,
+			Cached:    cached,
+		})
+	}
+	if decision.Approved {
+		return nil
+	}
+	msg := fmt.Sprintf("Call to %s was rejected", name)
+	if decision.Reason != "" {
+		msg += ": " + decision.Reason
+	}
+	return NewToolResponseError(msg)
+}
+
+// decideApproval returns the decision for req, from the session's cache or
+// from approver, and whether it came from the cache.
+func (s *Server) decideApproval(ctx context.Context, approver Approver, cfg *ApprovalConfig, req ApprovalRequest) (ApprovalDecision, bool) {
+	if cfg.TTL > 0 && req.SessionID != "" && s.approvals.approved(req.SessionID, req.Tool) {
+		return ApprovalDecision{Approved: true}, true
+	}
+	if approver == nil {
+		return ApprovalDecision{Reason: "no approver is configured"}, false
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultApprovalTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	decision, err := approver.Approve(ctx, req)
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return ApprovalDecision{Reason: "approval timed out"}, false
+	case err != nil:
+		return ApprovalDecision{Reason: "approval failed: " + err.Error()}, false
+	}
+	if decision.Approved && cfg.TTL > 0 && req.SessionID != "" {
+		s.approvals.add(req.SessionID, req.Tool, cfg.TTL)
+	}
+	return decision, false
+}
+
+// approvalCache remembers approvals per session and tool until they expire.
+type approvalCache struct {
+	mu      sync.Mutex
+	entries map[approvalKey]time.Time // Expiry
+}
+
+type approvalKey struct{ session, tool string }
+
+func (c *approvalCache) approved(session, tool string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := approvalKey{session, tool}
+	expires, ok := c.entries[key]
+	if ok && time.Now().After(expires) {
+		delete(c.entries, key)
+		return false
+	}
+	return ok
+}
+
+func (c *approvalCache) add(session, tool string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[approvalKey]time.Time)
+	}
+	now := time.Now()
+	for key, expires := range c.entries {
+		if now.After(expires) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[approvalKey{session, tool}] = now.Add(ttl)
+}
+
+// webhookApprover asks an HTTP endpoint for approval.
+type webhookApprover struct {
+	url  string
+	auth AuthProvider
+}
+
+// NewWebhookApprover returns an approver that POSTs each ApprovalRequest as
+// JSON to url and expects an ApprovalDecision as JSON in reply. The request
+// is held open until the endpoint answers, so it may wait for a human. auth,
+// if not nil, supplies the Authorization header.
+func NewWebhookApprover(url string, auth AuthProvider) Approver {
+	return &webhookApprover{url: url, auth: auth}
+}
+
+func (a *webhookApprover) Approve(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return ApprovalDecision{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return ApprovalDecision{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if a.auth != nil {
+		header, err := a.auth.GetAuthHeader()
+		if err != nil {
+			return ApprovalDecision{}, &authHeaderError{err: err}
+		}
+		httpReq.Header.Set("Authorization", header)
+	}
+
+	resp, err := pool.GetPool().GetHTTPClient().Do(httpReq)
+	if err != nil {
+		return ApprovalDecision{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ApprovalDecision{}, &httpStatusError{StatusCode: resp.StatusCode}
+	}
+	var decision ApprovalDecision
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return ApprovalDecision{}, fmt.Errorf("decode approval: %w", err)
+	}
+	return decision, nil
+}
+
+// elicitationApprover asks the user through the client, with an MCP
+// elicitation/create request.
+type elicitationApprover struct{}
+
+// NewElicitationApprover returns an approver that asks the user of the client
+// that made the call, with an elicitation/create request carrying a yes/no
+// form. The call is approved if the user accepts with approve set. Clients
+// are asked only over stdio streams (ServeStdio, ServeStream) and only if
+// they declared the elicitation capability; otherwise the call is rejected.
+func NewElicitationApprover() Approver {
+	return elicitationApprover{}
+}
+
+func (elicitationApprover) Approve(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+	requester := clientRequesterFromContext(ctx)
+	if requester == nil || !requester.supportsElicitation() {
+		return ApprovalDecision{}, errNoElicitation
+	}
+
+	message := req.Message
+	if message == "" {
+		message = fmt.Sprintf("Allow the tool %s to run?", req.Tool)
+	}
+	params := map[string]any{
+		"message": message,
+		"requestedSchema": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"approve": map[string]any{"type": "boolean", "title": "Approve", "description": "Allow this call to " + req.Tool},
+			},
+			"required": []string{"approve"},
+		},
+	}
+	var result struct {
+		Action  string         `json:"action"`
+		Content map[string]any `json:"content"`
+	}
+	if err := requester.request(ctx, "elicitation/create", params, &result); err != nil {
+		return ApprovalDecision{}, err
+	}
+	if result.Action != "accept" {
+		return ApprovalDecision{Reason: "user chose " + result.Action}, nil
+	}
+	if approve, _ := result.Content["approve"].(bool); !approve {
+		return ApprovalDecision{Reason: "user did not approve"}, nil
+	}
+	return ApprovalDecision{Approved: true}, nil
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func approvalTestServer(cfg ApprovalConfig, runs *int) *Server {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("drop_table", "Drop a table").RequireApproval(cfg),
+		func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+			*runs++
+			return NewToolResponseText("dropped"), nil
+		})
+	return server
+}
+
+func TestApprovalCallback(t *testing.T) {
+	var runs int
+	var asked []ApprovalRequest
+	server := approvalTestServer(ApprovalConfig{
+		Message: "Drop a table",
+		Approver: ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+			asked = append(asked, req)
+			if req.Arguments["table"] == "users" {
+				return ApprovalDecision{Reason: "not the users table"}, nil
+			}
+			return ApprovalDecision{Approved: true}, nil
+		}),
+	}, &runs)
+	var records []ApprovalRecord
+	server.SetApprovalRecorder(func(r ApprovalRecord) { records = append(records, r) })
+
+	ctx := WithPrincipal(context.Background(), Principal{ID: "alice"})
+	resp, err := server.CallTool(ctx, "drop_table", map[string]any{"table": "users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || !strings.Contains(resp.Content[0].Text, "not the users table") || runs != 0 {
+		t.Errorf("rejected call: got %+v after %d runs", resp, runs)
+	}
+
+	resp, err = server.CallTool(ctx, "drop_table", map[string]any{"table": "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsError || runs != 1 {
+		t.Errorf("approved call: got %+v after %d runs", resp, runs)
+	}
+
+	if len(asked) != 2 || asked[0].Message != "Drop a table" || asked[0].Principal != "alice" {
+		t.Errorf("unexpected approval requests %+v", asked)
+	}
+	if len(records) != 2 || records[0].Approved || !records[1].Approved || records[0].Reason != "not the users table" {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestApprovalCachedPerSession(t *testing.T) {
+	var runs, asks int
+	server := approvalTestServer(ApprovalConfig{
+		TTL: time.Minute,
+		Approver: ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+			asks++
+			return ApprovalDecision{Approved: true}, nil
+		}),
+	}, &runs)
+	var records []ApprovalRecord
+	server.SetApprovalRecorder(func(r ApprovalRecord) { records = append(records, r) })
+
+	a := withSessionID(context.Background(), "a")
+	b := withSessionID(context.Background(), "b")
+	server.CallTool(a, "drop_table", nil)
+	server.CallTool(a, "drop_table", nil)
+	server.CallTool(b, "drop_table", nil)
+	server.CallTool(context.Background(), "drop_table", nil)
+	server.CallTool(context.Background(), "drop_table", nil)
+
+	if runs != 5 || asks != 4 {
+		t.Errorf("got %d runs and %d approvals, want 5 and 4", runs, asks)
+	}
+	if len(records) != 5 || !records[1].Cached || records[2].Cached {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestApprovalRejectsWithoutApprover(t *testing.T) {
+	var runs int
+	server := approvalTestServer(ApprovalConfig{}, &runs)
+	resp, err := server.CallTool(context.Background(), "drop_table", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || !strings.Contains(resp.Content[0].Text, "no approver") || runs != 0 {
+		t.Errorf("got %+v after %d runs", resp, runs)
+	}
+
+	// The server's approver is used when the tool does not name one.
+	server.SetApprover(ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+		return ApprovalDecision{Approved: true}, nil
+	}))
+	if resp, _ := server.CallTool(context.Background(), "drop_table", nil); resp.IsError || runs != 1 {
+		t.Errorf("got %+v after %d runs", resp, runs)
+	}
+}
+
+func TestApprovalTimeout(t *testing.T) {
+	var runs int
+	server := approvalTestServer(ApprovalConfig{
+		Timeout: 20 * time.Millisecond,
+		Approver: ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+			<-ctx.Done()
+			return ApprovalDecision{}, ctx.Err()
+		}),
+	}, &runs)
+	resp, err := server.CallTool(context.Background(), "drop_table", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || !strings.Contains(resp.Content[0].Text, "timed out") || runs != 0 {
+		t.Errorf("got %+v after %d runs", resp, runs)
+	}
+}
+
+func TestWebhookApprover(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req ApprovalRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(ApprovalDecision{Approved: req.Arguments["table"] == "logs", Reason: "checked"})
+	}))
+	defer hook.Close()
+
+	var runs int
+	server := approvalTestServer(ApprovalConfig{Approver: NewWebhookApprover(hook.URL, NewBearerTokenAuth("secret"))}, &runs)
+	if resp, _ := server.CallTool(context.Background(), "drop_table", map[string]any{"table": "users"}); !resp.IsError {
+		t.Errorf("webhook rejection: got %+v", resp)
+	}
+	if resp, _ := server.CallTool(context.Background(), "drop_table", map[string]any{"table": "logs"}); resp.IsError || runs != 1 {
+		t.Errorf("webhook approval: got %+v after %d runs", resp, runs)
+	}
+
+	server = approvalTestServer(ApprovalConfig{Approver: NewWebhookApprover(hook.URL, nil)}, &runs)
+	if resp, _ := server.CallTool(context.Background(), "drop_table", map[string]any{"table": "logs"}); !resp.IsError {
+		t.Errorf("failed webhook should reject: got %+v", resp)
+	}
+}
+
+func TestElicitationApprover(t *testing.T) {
+	var runs int
+	server := approvalTestServer(ApprovalConfig{Approver: NewElicitationApprover()}, &runs)
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = server.ServeStream(context.Background(), serverReader, serverWriter)
+	}()
+	defer func() {
+		clientWriter.Close()
+		<-done
+		serverWriter.Close()
+	}()
+
+	var writeMu sync.Mutex
+	send := func(msg string) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		clientWriter.Write([]byte(msg + "\n"))
+	}
+	lines := bufio.NewScanner(clientReader)
+	next := func() map[string]any {
+		if !lines.Scan() {
+			t.Fatal("stream ended")
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(lines.Bytes(), &msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"elicitation":{}}}}`)
+	next()
+
+	for _, approve := range []bool{false, true} {
+		send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"drop_table"}}`)
+		elicit := next()
+		if elicit["method"] != "elicitation/create" {
+			t.Fatalf("expected an elicitation request, got %v", elicit)
+		}
+		id, _ := json.Marshal(elicit["id"])
+		send(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"action":"accept","content":{"approve":` + strconv.FormatBool(approve) + `}}}`)
+
+		result, _ := next()["result"].(map[string]any)
+		if isError, _ := result["isError"].(bool); isError == approve {
+			t.Errorf("approve=%v: got result %v", approve, result)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("tool ran %d times, want 1", runs)
+	}
+}
+
+func TestElicitationApproverWithoutClientSupport(t *testing.T) {
+	var runs int
+	server := approvalTestServer(ApprovalConfig{Approver: NewElicitationApprover()}, &runs)
+	resp, err := server.CallTool(context.Background(), "drop_table", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || !strings.Contains(resp.Content[0].Text, "elicitation") || runs != 0 {
+		t.Errorf("got %+v after %d runs", resp, runs)
+	}
+}
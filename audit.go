@@ -0,0 +1,420 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAuditFailed is returned for a tool call or resource read whose audit
+// entry could not be stored when the audit log fails closed (see
+// AuditConfig.FailClosed).
+var ErrAuditFailed = &ToolError{Code: ErrorCodeInternalError, Message: "request could not be recorded in the audit log"}
+
+// AuditKind is what an audit entry records.
+type AuditKind string
+
+const (
+	AuditToolCall     AuditKind = "tool_call"
+	AuditResourceRead AuditKind = "resource_read"
+	AuditApproval     AuditKind = "approval"
+)
+
+// AuditOutcome is how an audited request ended.
+type AuditOutcome string
+
+const (
+	AuditStarted  AuditOutcome = "started"  // A tool call is about to run; written first when failing closed
+	AuditOK       AuditOutcome = "ok"       // Succeeded; an approval was granted
+	AuditError    AuditOutcome = "error"    // The tool returned an isError result
+	AuditFailed   AuditOutcome = "failed"   // Failed with an error
+	AuditDenied   AuditOutcome = "denied"   // Denied by the server's policy
+	AuditRejected AuditOutcome = "rejected" // An approval was refused
+)
+
+// AuditEntry records one tool call, resource read or approval decision.
+type AuditEntry struct {
+	Time      time.Time      `json:"time"`
+	Kind      AuditKind      `json:"kind"`
+	SessionID string         `json:"sessionId,omitempty"`
+	Principal string         `json:"principal,omitempty"`
+	Tool      string         `json:"tool,omitempty"`
+	URI       string         `json:"uri,omitempty"`    // Resource read
+	Remote    string         `json:"remote,omitempty"` // Remote server the call was forwarded to, by namespace or URL
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Duration  time.Duration  `json:"duration"` // Nanoseconds in JSON
+	Outcome   AuditOutcome   `json:"outcome"`
+	ErrorCode int            `json:"errorCode,omitempty"` // Code of a ToolError
+	Error     string         `json:"error,omitempty"`     // Error message, or the reason for an approval decision
+	Result    *ToolResult    `json:"result,omitempty"`    // Set when AuditConfig.IncludeResults is
+}
+
+// AuditSink stores audit entries. WriteAudit is called synchronously, in the
+// order requests finish, and must be safe for concurrent use.
+type AuditSink interface {
+	WriteAudit(ctx context.Context, entry AuditEntry) error
+}
+
+// AuditRedaction hides a parameter's value in audited arguments and results.
+type AuditRedaction struct {
+	// Tool is a glob (see PolicyRule) over tool names. Empty matches all.
+	Tool string
+
+	// Param names the parameter. Dots reach into objects: "auth.token".
+	// Along the path, an array applies the rest of it to each element.
+	Param string
+
+	// Replace returns what to record instead of the value. Nil records
+	// "[REDACTED]"; RedactSHA256 records a hash that still lets entries be
+	// correlated.
+	Replace func(value any) any
+}
+
+// RedactSHA256 replaces a value with the hex SHA-256 of its JSON encoding,
+// for use as AuditRedaction.Replace.
+func RedactSHA256(value any) any {
+	data, _ := json.Marshal(value)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// AuditConfig configures the server's audit log.
+type AuditConfig struct {
+	// Sink stores the entries. Nil turns auditing off.
+	Sink AuditSink
+
+	// Redactions hide sensitive arguments before they are recorded.
+	Redactions []AuditRedaction
+
+	// IncludeResults records what each tool call returned. Redactions apply
+	// to structured content and to text content that holds JSON.
+	IncludeResults bool
+
+	// OnError is called when the sink fails to store an entry. Without it
+	// such errors are discarded.
+	OnError func(error)
+
+	// FailClosed fails each request whose entry cannot be stored: a tool call
+	// or resource read returns ErrAuditFailed in place of its result, and an
+	// approval is treated as refused. Tool calls also get an AuditStarted
+	// entry before they run, and do not run if it cannot be stored, so no
+	// call has side effects without a record. Without it requests go ahead
+	// unrecorded.
+	FailClosed bool
+}
+
+// SetAuditLog sets the audit log that records every tool call, resource read
+// and approval decision, whether made over HTTP, stdio or execute_tool, and
+// whether served locally, by a provider or by a remote server. The discovery
+// meta-tools themselves are not recorded. A config with a nil Sink turns
+// auditing off.
+func (s *Server) SetAuditLog(cfg AuditConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cfg.Sink == nil {
+		s.audit = nil
+		return
+	}
+	s.audit = &cfg
+}
+
+// auditLog returns the audit config under read lock, or nil when off.
+func (s *Server) auditLog() *AuditConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.audit
+}
+
+// write stores entry, filling in the request's session and principal. It
+// returns ErrAuditFailed if the entry could not be stored and the log fails
+// closed.
+func (a *AuditConfig) write(ctx context.Context, entry AuditEntry) error {
+	entry.SessionID = sessionIDFromContext(ctx)
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		entry.Principal = principal.ID
+	}
+	err := a.Sink.WriteAudit(context.WithoutCancel(ctx), entry)
+	if err == nil {
+		return nil
+	}
+	if a.OnError != nil {
+		a.OnError(err)
+	}
+	if a.FailClosed {
+		return ErrAuditFailed
+	}
+	return nil
+}
+
+// recordToolStart records that a call to the tool called name is about to
+// run.
+func (a *AuditConfig) recordToolStart(ctx context.Context, name, remote string, args map[string]any, start time.Time) error {
+	return a.write(ctx, AuditEntry{
+		Time:      start,
+		Kind:      AuditToolCall,
+		Tool:      name,
+		Remote:    remote,
+		Arguments: a.redact(name, args),
+		Outcome:   AuditStarted,
+	})
+}
+
+// recordToolCall records a call to the tool called name.
+func (a *AuditConfig) recordToolCall(ctx context.Context, name, remote string, args map[string]any, start time.Time, response *ToolResponse, err error) error {
+	entry := AuditEntry{
+		Time:      start,
+		Kind:      AuditToolCall,
+		Tool:      name,
+		Remote:    remote,
+		Arguments: a.redact(name, args),
+		Duration:  time.Since(start),
+		Outcome:   AuditOK,
+	}
+	setAuditError(&entry, err)
+	if err == nil && response != nil {
+		if response.IsError {
+			entry.Outcome = AuditError
+		}
+		if a.IncludeResults {
+			entry.Result = a.redactResult(name, response)
+		}
+	}
+	return a.write(ctx, entry)
+}
+
+// recordResourceRead records a read of the resource at uri.
+func (a *AuditConfig) recordResourceRead(ctx context.Context, uri string, start time.Time, err error) error {
+	entry := AuditEntry{
+		Time:     start,
+		Kind:     AuditResourceRead,
+		URI:      uri,
+		Duration: time.Since(start),
+		Outcome:  AuditOK,
+	}
+	setAuditError(&entry, err)
+	return a.write(ctx, entry)
+}
+
+// recordApproval records an approval decision for a call to the tool called
+// name.
+func (a *AuditConfig) recordApproval(ctx context.Context, name string, args map[string]any, start time.Time, decision ApprovalDecision) error {
+	entry := AuditEntry{
+		Time:      start,
+		Kind:      AuditApproval,
+		Tool:      name,
+		Arguments: a.redact(name, args),
+		Duration:  time.Since(start),
+		Outcome:   AuditOK,
+		Error:     decision.Reason,
+	}
+	if !decision.Approved {
+		entry.Outcome = AuditRejected
+	}
+	return a.write(ctx, entry)
+}
+
+// setAuditError sets the outcome and error fields of entry for err.
+func setAuditError(entry *AuditEntry, err error) {
+	if err == nil {
+		return
+	}
+	entry.Outcome = AuditFailed
+	if errors.Is(err, ErrPolicyDenied) {
+		entry.Outcome = AuditDenied
+	}
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		entry.ErrorCode = toolErr.Code
+		entry.Error = toolErr.Message
+	} else {
+		entry.Error = err.Error()
+	}
+}
+
+// redact returns args for the tool called name with the configured
+// redactions applied. args is not modified.
+func (a *AuditConfig) redact(name string, args map[string]any) map[string]any {
+	if len(args) == 0 {
+		return nil
+	}
+	out, _ := a.redactValue(name, args).(map[string]any)
+	return out
+}
+
+// redactResult returns the response of a call to the tool called name for
+// the audit log, with the redactions applied to its structured content and
+// to text content holding JSON. response is not modified.
+func (a *AuditConfig) redactResult(name string, response *ToolResponse) *ToolResult {
+	result := &ToolResult{
+		Content:           response.Content,
+		StructuredContent: response.StructuredContent,
+		IsError:           response.IsError,
+	}
+	if !a.redacts(name) {
+		return result
+	}
+	if response.StructuredContent != nil {
+		result.StructuredContent = a.redactValue(name, jsonValue(response.StructuredContent))
+	}
+	cloned := false
+	for i, content := range response.Content {
+		var value any
+		if content.Type != "text" || json.Unmarshal([]byte(content.Text), &value) != nil {
+			continue
+		}
+		data, err := json.Marshal(a.redactValue(name, value))
+		if err != nil || string(data) == content.Text {
+			continue
+		}
+		if !cloned {
+			result.Content = slices.Clone(response.Content)
+			cloned = true
+		}
+		result.Content[i].Text = string(data)
+	}
+	return result
+}
+
+// redacts reports whether any redaction applies to the tool called name.
+func (a *AuditConfig) redacts(name string) bool {
+	for _, r := range a.Redactions {
+		if r.Tool == "" || matchGlob(r.Tool, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue returns value with the redactions for the tool called name
+// applied.
+func (a *AuditConfig) redactValue(name string, value any) any {
+	for _, r := range a.Redactions {
+		if r.Tool == "" || matchGlob(r.Tool, name) {
+			value = redactPath(value, strings.Split(r.Param, "."), r.Replace)
+		}
+	}
+	return value
+}
+
+// redactPath returns value with the value at path replaced. Objects along the
+// way are cloned so the caller's data is left alone, and each element of an
+// array is treated as the next step on the path.
+func redactPath(value any, path []string, replace func(any) any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		child, ok := v[path[0]]
+		if !ok {
+			return value
+		}
+		v = maps.Clone(v)
+		switch {
+		case len(path) > 1:
+			v[path[0]] = redactPath(child, path[1:], replace)
+		case replace == nil:
+			v[path[0]] = "[REDACTED]"
+		default:
+			v[path[0]] = replace(child)
+		}
+		return v
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = redactPath(elem, path, replace)
+		}
+		return out
+	}
+	return value
+}
+
+// jsonValue returns v as the maps and slices it decodes to from JSON, so
+// structs and typed slices nested anywhere in it can be redacted.
+func jsonValue(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if json.Unmarshal(data, &out) != nil {
+		return v
+	}
+	return out
+}
+
+// remoteOf returns the remote server a call to the tool called name is
+// forwarded to, by namespace or else URL, or "" if it is not a registered
+// remote's tool.
+func (s *Server) remoteOf(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rc, ok := s.toolToServer[name]
+	if !ok {
+		for _, c := range s.remoteClients {
+			if c.namespace != "" && strings.HasPrefix(name, c.namespace+c.client.separator) {
+				rc, ok = c, true
+				break
+			}
+		}
+	}
+	switch {
+	case !ok:
+		return s.principalRemoteOfLocked(name)
+	case rc.namespace != "":
+		return rc.namespace
+	}
+	return rc.client.baseURL
+}
+
+// JSONLAuditSink is an AuditSink that appends each entry to a writer as one
+// line of JSON.
+type JSONLAuditSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer // Set when the sink opened the file itself
+}
+
+// NewJSONLAuditSink returns a sink that writes entries to w.
+func NewJSONLAuditSink(w io.Writer) *JSONLAuditSink {
+	return &JSONLAuditSink{w: w}
+}
+
+// OpenJSONLAuditSink opens the file at path for appending, creating it if
+// need be, and returns a sink that writes entries to it. Close the sink to
+// close the file.
+func OpenJSONLAuditSink(path string) (*JSONLAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &JSONLAuditSink{w: f, closer: f}, nil
+}
+
+// WriteAudit implements AuditSink.
+func (s *JSONLAuditSink) WriteAudit(ctx context.Context, entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file the sink opened; a sink given a writer leaves it
+// open.
+func (s *JSONLAuditSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
@@ -0,0 +1,346 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// auditEntries decodes the JSONL written to buf.
+func auditEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	lines := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for lines.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(lines.Bytes(), &entry); err != nil {
+			t.Fatalf("bad audit line %q: %v", lines.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func auditTestServer(buf *bytes.Buffer) *Server {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("login", "Log in").Discoverable("login"),
+		func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+			return NewToolResponseText("welcome"), nil
+		})
+	server.RegisterTool(NewTool("flaky", "Fails"),
+		func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+			if req.BoolOr("hard", false) {
+				return nil, NewToolErrorInvalidParams("bad input")
+			}
+			return NewToolResponseError("soft failure"), nil
+		})
+	server.RegisterResource(NewResource("config://app", "App Config", "", "application/json"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), "{}", "application/json"), nil
+		})
+	server.SetAuditLog(AuditConfig{
+		Sink: NewJSONLAuditSink(buf),
+		Redactions: []AuditRedaction{
+			{Tool: "log*", Param: "password"},
+			{Param: "auth.token", Replace: RedactSHA256},
+		},
+		IncludeResults: true,
+	})
+	return server
+}
+
+func TestAuditToolCalls(t *testing.T) {
+	var buf bytes.Buffer
+	server := auditTestServer(&buf)
+	ctx := WithPrincipal(withSessionID(context.Background(), "s1"), Principal{ID: "alice"})
+
+	args := map[string]any{
+		"user":     "alice",
+		"password": "hunter2",
+		"auth":     map[string]any{"token": "abc", "kind": "bearer"},
+	}
+	if _, err := server.CallTool(ctx, ExecuteToolName, map[string]any{"name": "login", "parameters": args}); err != nil {
+		t.Fatal(err)
+	}
+	server.CallTool(ctx, "flaky", nil)
+	server.CallTool(ctx, "flaky", map[string]any{"hard": true})
+	server.CallTool(ctx, "missing", nil)
+
+	if args["password"] != "hunter2" || args["auth"].(map[string]any)["token"] != "abc" {
+		t.Error("redaction modified the caller's arguments")
+	}
+
+	entries := auditEntries(t, &buf)
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4 (execute_tool itself is not audited): %v", len(entries), entries)
+	}
+
+	login := entries[0]
+	if login["tool"] != "login" || login["kind"] != "tool_call" || login["outcome"] != "ok" ||
+		login["sessionId"] != "s1" || login["principal"] != "alice" {
+		t.Errorf("unexpected entry %v", login)
+	}
+	loginArgs := login["arguments"].(map[string]any)
+	if loginArgs["password"] != "[REDACTED]" || loginArgs["user"] != "alice" {
+		t.Errorf("unexpected arguments %v", loginArgs)
+	}
+	auth := loginArgs["auth"].(map[string]any)
+	if token, _ := auth["token"].(string); !strings.HasPrefix(token, "sha256:") || auth["kind"] != "bearer" {
+		t.Errorf("unexpected nested arguments %v", auth)
+	}
+	if result, _ := login["result"].(map[string]any); result == nil || !strings.Contains(string(mustJSON(t, result)), "welcome") {
+		t.Errorf("unexpected result %v", login["result"])
+	}
+
+	if entries[1]["outcome"] != "error" {
+		t.Errorf("isError result: %v", entries[1])
+	}
+	if entries[2]["outcome"] != "failed" || entries[2]["errorCode"] != float64(ErrorCodeInvalidParams) || entries[2]["error"] != "bad input" {
+		t.Errorf("ToolError: %v", entries[2])
+	}
+	if entries[3]["outcome"] != "failed" || entries[3]["error"] != ErrUnknownTool.Error() {
+		t.Errorf("unknown tool: %v", entries[3])
+	}
+}
+
+func TestAuditRedactsArraysAndResults(t *testing.T) {
+	type key struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	var buf bytes.Buffer
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("rotate_keys", "Rotate keys"),
+		func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+			return NewToolResponseStructured(map[string]any{"keys": []key{{"a", "new-a"}, {"b", "new-b"}}}), nil
+		})
+	server.SetAuditLog(AuditConfig{
+		Sink:           NewJSONLAuditSink(&buf),
+		Redactions:     []AuditRedaction{{Param: "keys.secret"}},
+		IncludeResults: true,
+	})
+
+	args := map[string]any{"keys": []any{
+		map[string]any{"id": "a", "secret": "old-a"},
+		map[string]any{"id": "b", "secret": "old-b"},
+	}}
+	resp, err := server.CallTool(context.Background(), "rotate_keys", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args["keys"].([]any)[0].(map[string]any)["secret"] != "old-a" || !strings.Contains(resp.Content[0].Text, "new-a") {
+		t.Error("redaction modified the caller's arguments or the response")
+	}
+
+	entry := auditEntries(t, &buf)[0]
+	for _, k := range entry["arguments"].(map[string]any)["keys"].([]any) {
+		if k := k.(map[string]any); k["secret"] != "[REDACTED]" || k["id"] == nil {
+			t.Errorf("array element not redacted: %v", k)
+		}
+	}
+	result := entry["result"].(map[string]any)
+	for _, k := range result["structuredContent"].(map[string]any)["keys"].([]any) {
+		if k := k.(map[string]any); k["secret"] != "[REDACTED]" || k["id"] == nil {
+			t.Errorf("structured result not redacted: %v", k)
+		}
+	}
+	if text := string(mustJSON(t, result["content"])); strings.Contains(text, "new-a") || !strings.Contains(text, "REDACTED") {
+		t.Errorf("JSON text result not redacted: %s", text)
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestAuditDeniedAndApproval(t *testing.T) {
+	var buf bytes.Buffer
+	server := auditTestServer(&buf)
+	server.RegisterTool(NewTool("wipe", "Wipe").RequireApproval(ApprovalConfig{
+		Approver: ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+			return ApprovalDecision{Reason: "no"}, nil
+		}),
+	}), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("wiped"), nil
+	})
+	server.SetPolicy(&Policy{Rules: []PolicyRule{{Effect: PolicyDeny, Tools: []string{"flaky"}, Resources: []string{"config://*"}}}})
+
+	ctx := context.Background()
+	server.CallTool(ctx, "wipe", nil)
+	server.CallTool(ctx, "flaky", nil)
+	if _, err := server.ReadResource(ctx, "config://app"); !errors.Is(err, ErrPolicyDenied) {
+		t.Fatalf("got %v, want ErrPolicyDenied", err)
+	}
+
+	entries := auditEntries(t, &buf)
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4: %v", len(entries), entries)
+	}
+	if entries[0]["kind"] != "approval" || entries[0]["outcome"] != "rejected" || entries[0]["error"] != "no" {
+		t.Errorf("approval: %v", entries[0])
+	}
+	if entries[1]["tool"] != "wipe" || entries[1]["outcome"] != "error" {
+		t.Errorf("rejected call: %v", entries[1])
+	}
+	if entries[2]["outcome"] != "denied" || entries[2]["errorCode"] != float64(ErrorCodePolicyDenied) {
+		t.Errorf("denied call: %v", entries[2])
+	}
+	if entries[3]["kind"] != "resource_read" || entries[3]["uri"] != "config://app" || entries[3]["outcome"] != "denied" {
+		t.Errorf("denied read: %v", entries[3])
+	}
+}
+
+func TestAuditRemoteCalls(t *testing.T) {
+	remote := NewServer("remote", "1")
+	remote.RegisterTool(NewTool("rt", "remote tool"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("remote"), nil
+	})
+	ts := httptest.NewServer(http.HandlerFunc(remote.HandleRequest))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	host := auditTestServer(&buf)
+	if err := host.RegisterRemoteServer(NewClient(ts.URL, nil, "ns")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Over HTTP, as a client of the host would call it.
+	hostTS := httptest.NewServer(http.HandlerFunc(host.HandleRequest))
+	defer hostTS.Close()
+	client := NewClient(hostTS.URL, nil, "")
+	if _, err := client.CallTool(context.Background(), "ns__rt", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t, &buf)
+	if len(entries) != 1 || entries[0]["tool"] != "ns__rt" || entries[0]["remote"] != "ns" || entries[0]["outcome"] != "ok" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+	if d, _ := entries[0]["duration"].(float64); d <= 0 {
+		t.Errorf("duration not recorded: %v", entries[0])
+	}
+}
+
+// failingAuditSink is an AuditSink that cannot store anything.
+type failingAuditSink struct{}
+
+func (failingAuditSink) WriteAudit(ctx context.Context, entry AuditEntry) error {
+	return errors.New("disk full")
+}
+
+func TestAuditFailClosed(t *testing.T) {
+	server := auditTestServer(&bytes.Buffer{})
+	server.RegisterTool(NewTool("wipe", "Wipe").RequireApproval(ApprovalConfig{
+		Approver: ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+			return ApprovalDecision{Approved: true}, nil
+		}),
+	}), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("wiped"), nil
+	})
+	var reported int
+	cfg := AuditConfig{Sink: failingAuditSink{}, OnError: func(error) { reported++ }}
+	ctx := context.Background()
+
+	// By default a request the sink cannot record goes ahead.
+	server.SetAuditLog(cfg)
+	if resp, err := server.CallTool(ctx, "login", nil); err != nil || resp.Content[0].Text != "welcome" {
+		t.Fatalf("fail-open call: %v, %v", resp, err)
+	}
+	if reported != 1 {
+		t.Errorf("OnError called %d times, want 1", reported)
+	}
+
+	cfg.FailClosed = true
+	server.SetAuditLog(cfg)
+	if _, err := server.CallTool(ctx, "login", nil); !errors.Is(err, ErrAuditFailed) {
+		t.Errorf("call: got %v, want ErrAuditFailed", err)
+	}
+	if _, err := server.ReadResource(ctx, "config://app"); !errors.Is(err, ErrAuditFailed) {
+		t.Errorf("read: got %v, want ErrAuditFailed", err)
+	}
+
+	// An approval that cannot be recorded is refused, so the tool never runs.
+	server.SetAuditLog(AuditConfig{Sink: approvalOnlyFailingSink{}, FailClosed: true})
+	resp, err := server.CallTool(ctx, "wipe", nil)
+	if err != nil || !resp.IsError || !strings.Contains(resp.Content[0].Text, "audit log") {
+		t.Errorf("approval: %v, %v", resp, err)
+	}
+}
+
+func TestAuditFailClosedSkipsUnrecordedCalls(t *testing.T) {
+	server := NewServer("test", "1.0")
+	ran := 0
+	server.RegisterTool(NewTool("wipe", "Wipe"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		ran++
+		return NewToolResponseText("wiped"), nil
+	})
+
+	server.SetAuditLog(AuditConfig{Sink: failingAuditSink{}, FailClosed: true})
+	if _, err := server.CallTool(context.Background(), "wipe", nil); !errors.Is(err, ErrAuditFailed) {
+		t.Errorf("got %v, want ErrAuditFailed", err)
+	}
+	if ran != 0 {
+		t.Errorf("handler ran %d times without being recorded", ran)
+	}
+
+	// A call that runs is recorded as started and then with its outcome.
+	var buf bytes.Buffer
+	server.SetAuditLog(AuditConfig{Sink: NewJSONLAuditSink(&buf), FailClosed: true})
+	if _, err := server.CallTool(context.Background(), "wipe", map[string]any{"all": true}); err != nil {
+		t.Fatal(err)
+	}
+	entries := auditEntries(t, &buf)
+	if len(entries) != 2 || entries[0]["outcome"] != "started" || entries[1]["outcome"] != "ok" || ran != 1 {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+	if entries[0]["tool"] != "wipe" || entries[0]["arguments"] == nil {
+		t.Errorf("start entry should name the call: %v", entries[0])
+	}
+}
+
+// approvalOnlyFailingSink stores everything but approval decisions.
+type approvalOnlyFailingSink struct{}
+
+func (approvalOnlyFailingSink) WriteAudit(ctx context.Context, entry AuditEntry) error {
+	if entry.Kind == AuditApproval {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func TestOpenJSONLAuditSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for i := range 2 {
+		sink, err := OpenJSONLAuditSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.WriteAudit(context.Background(), AuditEntry{Time: time.Now(), Kind: AuditToolCall, Tool: "t", Outcome: AuditOK, Duration: time.Duration(i)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("got %d lines, want 2 appended: %s", lines, data)
+	}
+}
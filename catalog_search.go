@@ -0,0 +1,252 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ResourceSearchResult is a resource or resource template found by
+// resource_search. Exactly one of URI and URITemplate is set.
+type ResourceSearchResult struct {
+	URI         string  `json:"uri,omitempty"`
+	URITemplate string  `json:"uriTemplate,omitempty"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	MimeType    string  `json:"mimeType,omitempty"`
+	Score       float64 `json:"score"`
+}
+
+// PromptSearchResult is a prompt found by prompt_search.
+type PromptSearchResult struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Arguments   []MCPPromptArgument `json:"arguments,omitempty"`
+	Score       float64             `json:"score"`
+}
+
+// nativeOnly returns the entries with native visibility.
+func nativeOnly[T any](entries []T, visibility func(T) ToolVisibility) []T {
+	out := entries[:0]
+	for _, e := range entries {
+		if visibility(e) == ToolVisibilityNative {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// hasDiscoverableResources reports whether any resource or template, static,
+// from providers on ctx, or from a remote, is discoverable.
+func (s *Server) hasDiscoverableResources(ctx context.Context) bool {
+	for _, res := range s.listResources(ctx, true) {
+		if res.Visibility == ToolVisibilityDiscoverable {
+			return true
+		}
+	}
+	for _, tmpl := range s.listResourceTemplates(ctx, true) {
+		if tmpl.Visibility == ToolVisibilityDiscoverable {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDiscoverablePrompts reports whether any prompt, static, from providers on
+// ctx, or from a remote, is discoverable.
+func (s *Server) hasDiscoverablePrompts(ctx context.Context) bool {
+	for _, prompt := range s.listPrompts(ctx, true) {
+		if prompt.Visibility == ToolVisibilityDiscoverable {
+			return true
+		}
+	}
+	return false
+}
+
+// getCatalogDiscoveryTools returns the resource and prompt meta-tools to add to
+// tools/list: resource_search and read_resource when any resource is
+// discoverable, prompt_search and get_prompt when any prompt is.
+func (s *Server) getCatalogDiscoveryTools(ctx context.Context) []MCPTool {
+	var builders []*ToolBuilder
+	if s.hasDiscoverableResources(ctx) {
+		builders = append(builders,
+			NewTool(ResourceSearchName, "Search for available resources and resource templates by name, URI, description, or keywords. Returns matching entries with their URI (or URI template), description, MIME type, and relevance score (0.0 to 1.0). Read a resource with read_resource; for a template, replace each {placeholder} in the URI template first. Omit query to list everything.",
+				String("query", "Search query to find relevant resources. Omit to list all resources."),
+				Number("max_results", "Maximum number of results to return (default: 5)"),
+			),
+			NewTool(ReadResourceName, "Read a resource by URI, as found with resource_search.",
+				String("uri", "The URI of the resource to read", Required()),
+			),
+		)
+	}
+	if s.hasDiscoverablePrompts(ctx) {
+		builders = append(builders,
+			NewTool(PromptSearchName, "Search for available prompt templates by name, description, or keywords. Returns matching prompts with their descriptions, arguments, and relevance score (0.0 to 1.0). Render a prompt with get_prompt. Omit query to list all prompts.",
+				String("query", "Search query to find relevant prompts. Omit to list all prompts."),
+				Number("max_results", "Maximum number of results to return (default: 5)"),
+			),
+			NewTool(GetPromptName, "Render a prompt by name with its arguments, as found with prompt_search. Returns the prompt's messages.",
+				String("name", "The exact name of the prompt", Required()),
+				Object("arguments", "The prompt's arguments as string values"),
+			),
+		)
+	}
+
+	tools := make([]MCPTool, 0, len(builders))
+	for _, b := range builders {
+		tools = append(tools, MCPTool{Name: b.Name(), Description: b.Description(), InputSchema: b.BuildSchema()})
+	}
+	return tools
+}
+
+// searchLimit returns the max_results argument of a search meta-tool,
+// defaulting to 5 and capped at 100 as for tool_search.
+func searchLimit(req *ToolRequest) int {
+	maxResults := req.IntOr("max_results", 5)
+	if maxResults <= 0 {
+		maxResults = 5
+	}
+	return min(maxResults, 100)
+}
+
+// scoreCatalogEntry scores a resource or prompt against a lowercased query
+// with the heuristic tool_search scorer, matching the URI as well as the
+// name. An empty query matches everything.
+func scoreCatalogEntry(queryLower, name, uri, description string, keywords []string) float64 {
+	if queryLower == "" {
+		return 1.0
+	}
+	score := calculateScore(queryLower, name, description, keywords)
+	if uri != "" {
+		score = max(score, calculateScore(queryLower, uri, description, keywords))
+	}
+	return score
+}
+
+// handleResourceSearch handles the resource_search meta-tool.
+func (s *Server) handleResourceSearch(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+	queryLower := strings.ToLower(strings.TrimSpace(req.StringOr("query", "")))
+
+	var results []ResourceSearchResult
+	for _, res := range s.listResources(ctx, true) {
+		if score := scoreCatalogEntry(queryLower, res.Name, res.URI, res.Description, res.Keywords); score > 0 {
+			results = append(results, ResourceSearchResult{
+				URI:         res.URI,
+				Name:        res.Name,
+				Description: res.Description,
+				MimeType:    res.MimeType,
+				Score:       score,
+			})
+		}
+	}
+	for _, tmpl := range s.listResourceTemplates(ctx, true) {
+		if score := scoreCatalogEntry(queryLower, tmpl.Name, tmpl.URITemplate, tmpl.Description, tmpl.Keywords); score > 0 {
+			results = append(results, ResourceSearchResult{
+				URITemplate: tmpl.URITemplate,
+				Name:        tmpl.Name,
+				Description: tmpl.Description,
+				MimeType:    tmpl.MimeType,
+				Score:       score,
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].URI+results[i].URITemplate < results[j].URI+results[j].URITemplate
+	})
+	if limit := searchLimit(req); len(results) > limit {
+		results = results[:limit]
+	}
+
+	if len(results) == 0 {
+		return NewToolResponseText("No resources found. Try different keywords or a broader search term."), nil
+	}
+	return NewToolResponseJSON(results), nil
+}
+
+// handleReadResource handles the read_resource meta-tool, returning the
+// resource's contents as embedded resources.
+func (s *Server) handleReadResource(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+	uri, err := req.String("uri")
+	if err != nil || uri == "" {
+		return nil, NewToolErrorInvalidParams("uri is required")
+	}
+
+	resp, err := s.ReadResource(ctx, uri)
+	if err != nil {
+		if errors.Is(err, ErrUnknownResource) {
+			return nil, NewToolErrorInvalidParams("resource not found: " + uri)
+		}
+		return nil, err
+	}
+
+	response := &ToolResponse{}
+	for i := range resp.Contents {
+		response.Content = append(response.Content, ToolContent{Type: "resource", Resource: &resp.Contents[i]})
+	}
+	return response, nil
+}
+
+// handlePromptSearch handles the prompt_search meta-tool.
+func (s *Server) handlePromptSearch(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+	queryLower := strings.ToLower(strings.TrimSpace(req.StringOr("query", "")))
+
+	var results []PromptSearchResult
+	for _, prompt := range s.listPrompts(ctx, true) {
+		if score := scoreCatalogEntry(queryLower, prompt.Name, "", prompt.Description, prompt.Keywords); score > 0 {
+			results = append(results, PromptSearchResult{
+				Name:        prompt.Name,
+				Description: prompt.Description,
+				Arguments:   prompt.Arguments,
+				Score:       score,
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Name < results[j].Name
+	})
+	if limit := searchLimit(req); len(results) > limit {
+		results = results[:limit]
+	}
+
+	if len(results) == 0 {
+		return NewToolResponseText("No prompts found. Try different keywords or a broader search term."), nil
+	}
+	return NewToolResponseJSON(results), nil
+}
+
+// handleGetPrompt handles the get_prompt meta-tool, returning the rendered
+// prompt as JSON.
+func (s *Server) handleGetPrompt(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+	name, err := req.String("name")
+	if err != nil || name == "" {
+		return nil, NewToolErrorInvalidParams("name is required")
+	}
+
+	args := make(map[string]string)
+	raw, _ := req.Object("arguments")
+	for k, v := range raw {
+		str, ok := v.(string)
+		if !ok {
+			return nil, NewToolErrorInvalidParams("prompt argument " + k + " must be a string")
+		}
+		args[k] = str
+	}
+
+	resp, err := s.GetPrompt(ctx, name, args)
+	if err != nil {
+		if errors.Is(err, ErrUnknownPrompt) {
+			return nil, NewToolErrorInvalidParams("prompt not found: " + name)
+		}
+		return nil, err
+	}
+	return NewToolResponseJSON(resp), nil
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func catalogTestServer() *Server {
+	server := NewServer("test", "1.0")
+	server.RegisterResource(NewResource("file:///readme", "readme", "Project readme", "text/plain"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), "hello", "text/plain"), nil
+		})
+	server.RegisterResource(NewResource("db://schema", "schema", "Database schema", "text/plain").Discoverable("tables"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), "CREATE TABLE users", "text/plain"), nil
+		})
+	server.RegisterResourceTemplate(NewResourceTemplate("db://tables/{name}", "table", "A database table", "application/json").Discoverable("rows"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), `{"table":"`+req.URI()+`"}`, "application/json"), nil
+		})
+	server.RegisterPrompt(NewPrompt("review", "Review a pull request").Argument("pr", "Pull request number", true).Discoverable("code"),
+		func(ctx context.Context, req *PromptRequest) (*PromptResponse, error) {
+			pr, _ := req.String("pr")
+			return NewPromptResponseText("Review PR " + pr), nil
+		})
+	return server
+}
+
+func TestDiscoverableResourcesAndPromptsHidden(t *testing.T) {
+	server := catalogTestServer()
+	ctx := context.Background()
+
+	resources := server.ListResources(ctx)
+	if len(resources) != 1 || resources[0].URI != "file:///readme" {
+		t.Errorf("only the native resource should be listed, got %+v", resources)
+	}
+	if templates := server.ListResourceTemplates(ctx); len(templates) != 0 {
+		t.Errorf("discoverable template should not be listed, got %+v", templates)
+	}
+	if prompts := server.ListPrompts(ctx); len(prompts) != 0 {
+		t.Errorf("discoverable prompt should not be listed, got %+v", prompts)
+	}
+
+	names := toolNames(server.ListToolsWithContext(ctx))
+	for _, name := range []string{ResourceSearchName, ReadResourceName, PromptSearchName, GetPromptName} {
+		if !slices.Contains(names, name) {
+			t.Errorf("%s should be listed", name)
+		}
+	}
+	if slices.Contains(names, ToolSearchName) {
+		t.Error("tool_search should not be listed without discoverable tools")
+	}
+
+	// Show-all lists everything and drops the meta-tools.
+	all := WithShowAllTools(ctx)
+	if got := len(server.ListResources(all)); got != 2 {
+		t.Errorf("show-all should list 2 resources, got %d", got)
+	}
+	if got := len(server.ListPrompts(all)); got != 1 {
+		t.Errorf("show-all should list 1 prompt, got %d", got)
+	}
+	if slices.Contains(toolNames(server.ListToolsWithContext(all)), ResourceSearchName) {
+		t.Error("resource_search should not be listed in show-all mode")
+	}
+
+	// Without discoverable entries there are no meta-tools.
+	plain := NewServer("plain", "1.0")
+	plain.RegisterPrompt(NewPrompt("hello", "Say hello"), nil)
+	if names := toolNames(plain.ListToolsWithContext(ctx)); slices.Contains(names, PromptSearchName) || slices.Contains(names, ResourceSearchName) {
+		t.Errorf("meta-tools listed without discoverable entries: %v", names)
+	}
+}
+
+func TestResourceSearch(t *testing.T) {
+	server := catalogTestServer()
+	resp, err := server.CallTool(context.Background(), ResourceSearchName, map[string]any{"query": "table"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []ResourceSearchResult
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &results); err != nil {
+		t.Fatalf("unexpected response %q", resp.Content[0].Text)
+	}
+	if len(results) == 0 || results[0].URITemplate != "db://tables/{name}" {
+		t.Errorf("template should rank first for %q, got %+v", "table", results)
+	}
+
+	resp, _ = server.CallTool(context.Background(), ResourceSearchName, map[string]any{"max_results": 10})
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &results); err != nil || len(results) != 3 {
+		t.Errorf("empty query should list all 3 resources: %s", resp.Content[0].Text)
+	}
+
+	resp, _ = server.CallTool(context.Background(), ResourceSearchName, map[string]any{"query": "zzz"})
+	if !strings.HasPrefix(resp.Content[0].Text, "No resources found") {
+		t.Errorf("unexpected response %q", resp.Content[0].Text)
+	}
+}
+
+func TestReadResourceTool(t *testing.T) {
+	server := catalogTestServer()
+	ctx := context.Background()
+
+	resp, err := server.CallTool(ctx, ReadResourceName, map[string]any{"uri": "db://tables/users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Content) != 1 || resp.Content[0].Type != "resource" || resp.Content[0].Resource.Text != `{"table":"db://tables/users"}` {
+		t.Errorf("unexpected content %+v", resp.Content)
+	}
+
+	// Discoverable resources are still readable over resources/read.
+	if _, err := server.ReadResource(ctx, "db://schema"); err != nil {
+		t.Errorf("discoverable resource should be readable: %v", err)
+	}
+
+	_, err = server.CallTool(ctx, ReadResourceName, map[string]any{"uri": "db://missing"})
+	if toolErr, ok := err.(*ToolError); !ok || toolErr.Code != ErrorCodeInvalidParams {
+		t.Errorf("expected invalid params error, got %v", err)
+	}
+}
+
+func TestPromptSearchAndGet(t *testing.T) {
+	server := catalogTestServer()
+	ctx := context.Background()
+
+	resp, err := server.CallTool(ctx, PromptSearchName, map[string]any{"query": "code"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []PromptSearchResult
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &results); err != nil {
+		t.Fatalf("unexpected response %q", resp.Content[0].Text)
+	}
+	if len(results) != 1 || results[0].Name != "review" || len(results[0].Arguments) != 1 {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	resp, err = server.CallTool(ctx, GetPromptName, map[string]any{"name": "review", "arguments": map[string]any{"pr": "42"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prompt PromptResponse
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &prompt); err != nil {
+		t.Fatalf("unexpected response %q", resp.Content[0].Text)
+	}
+	if len(prompt.Messages) != 1 || prompt.Messages[0].Content.Text != "Review PR 42" {
+		t.Errorf("unexpected prompt %+v", prompt)
+	}
+
+	if _, err := server.CallTool(ctx, GetPromptName, map[string]any{"name": "review"}); err == nil {
+		t.Error("missing required argument should fail")
+	}
+	_, err = server.CallTool(ctx, GetPromptName, map[string]any{"name": "missing"})
+	if toolErr, ok := err.(*ToolError); !ok || toolErr.Code != ErrorCodeInvalidParams {
+		t.Errorf("expected invalid params error, got %v", err)
+	}
+}
+
+func TestCatalogMetaToolsYieldToLocalTools(t *testing.T) {
+	server := catalogTestServer()
+	server.RegisterTool(NewTool(GetPromptName, "A local tool"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("local"), nil
+	})
+	resp, err := server.CallTool(context.Background(), GetPromptName, nil)
+	if err != nil || resp.Content[0].Text != "local" {
+		t.Errorf("local tool should win, got %+v, %v", resp, err)
+	}
+}
+
+func TestDiscoverableRemoteResourcesAndPrompts(t *testing.T) {
+	remote := NewServer("remote", "1.0")
+	remote.RegisterResource(NewResource("docs://guide", "guide", "User guide", "text/plain"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), "the guide", "text/plain"), nil
+		})
+	remote.RegisterPrompt(NewPrompt("summarize", "Summarize a document").Argument("doc", "Document", true),
+		func(ctx context.Context, req *PromptRequest) (*PromptResponse, error) {
+			doc, _ := req.String("doc")
+			return NewPromptResponseText("Summarize " + doc), nil
+		})
+	remoteTS := httptest.NewServer(http.HandlerFunc(remote.HandleRequest))
+	defer remoteTS.Close()
+
+	server := NewServer("main", "1.0")
+	if err := server.RegisterRemoteServerDiscoverable(NewClient(remoteTS.URL, nil, "docs")); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if len(server.ListResources(ctx)) != 0 || len(server.ListPrompts(ctx)) != 0 {
+		t.Error("discoverable remote resources and prompts should not be listed")
+	}
+
+	resp, err := server.CallTool(ctx, ResourceSearchName, map[string]any{"query": "guide"})
+	if err != nil || !strings.Contains(resp.Content[0].Text, "docs+docs://guide") {
+		t.Errorf("remote resource should be searchable: %+v, %v", resp, err)
+	}
+	resp, err = server.CallTool(ctx, ReadResourceName, map[string]any{"uri": "docs+docs://guide"})
+	if err != nil || resp.Content[0].Resource.Text != "the guide" {
+		t.Errorf("remote resource should be readable: %+v, %v", resp, err)
+	}
+
+	resp, err = server.CallTool(ctx, PromptSearchName, map[string]any{"query": "summarize"})
+	if err != nil || !strings.Contains(resp.Content[0].Text, "docs__summarize") {
+		t.Errorf("remote prompt should be searchable by namespaced name: %+v, %v", resp, err)
+	}
+	prompt, err := server.GetPrompt(ctx, "docs__summarize", map[string]string{"doc": "README"})
+	if err != nil || prompt.Messages[0].Content.Text != "Summarize README" {
+		t.Errorf("remote prompt should render: %+v, %v", prompt, err)
+	}
+
+	// RefreshTools picks up new remote prompts.
+	remote.RegisterPrompt(NewPrompt("translate", "Translate a document"), func(ctx context.Context, req *PromptRequest) (*PromptResponse, error) {
+		return NewPromptResponseText("Translate"), nil
+	})
+	if err := server.RefreshTools(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.GetPrompt(ctx, "docs__translate", nil); err != nil {
+		t.Errorf("refreshed remote prompt should render: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCircuitFailureThreshold is how many consecutive failed requests
+	// open a remote server's circuit, when CircuitBreakerConfig does not say.
+	DefaultCircuitFailureThreshold = 5
+
+	// DefaultCircuitOpenTimeout is how long a circuit stays open before a
+	// probe request is let through, when CircuitBreakerConfig does not say.
+	DefaultCircuitOpenTimeout = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned for requests to a remote server whose circuit is
+// open: the server failed repeatedly and is not being contacted for a while.
+var ErrCircuitOpen = errors.New("remote server circuit is open")
+
+// CircuitState is the state of a remote server's circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through; the server is healthy.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails requests straight away with ErrCircuitOpen.
+	CircuitOpen
+
+	// CircuitHalfOpen lets one probe request through to test the server. It
+	// closes the circuit if it succeeds and opens it again if it fails.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// MarshalText renders the state by name, so it reads well in JSON.
+func (s CircuitState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// CircuitBreakerConfig configures the circuit breaker that stops a remote
+// server that is down from stalling every request on its timeout. Only
+// failures that point to an outage count: network errors, timeouts, 5xx
+// responses and stdio process failures, but not 4xx responses, failed auth or
+// errors returned by tools.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is how many consecutive failures open the circuit.
+	// Zero uses DefaultCircuitFailureThreshold; negative disables the breaker.
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before a probe request
+	// is let through. Zero uses DefaultCircuitOpenTimeout.
+	OpenTimeout time.Duration
+
+	// OnStateChange, if set, is called whenever the circuit changes state. It
+	// must not block.
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitStatus reports the state of a remote server's circuit breaker.
+type CircuitStatus struct {
+	Name                string       `json:"name"`               // Namespace of the server
+	Endpoint            string       `json:"endpoint,omitempty"` // URL of the server, if it has one
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	OpenedAt            time.Time    `json:"openedAt,omitzero"` // When the circuit last opened
+	LastError           string       `json:"lastError,omitempty"`
+}
+
+// circuitBreaker implements the breaker for one remote server. It may be
+// shared by many clients of the server.
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probing   bool // A half-open probe is in flight
+	lastError string
+}
+
+// newCircuitBreaker returns a breaker for cfg, or nil if cfg disables it.
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	if cfg.FailureThreshold < 0 {
+		return nil
+	}
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = DefaultCircuitFailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = DefaultCircuitOpenTimeout
+	}
+	return &circuitBreaker{cfg: cfg}
+}
+
+// allow reports whether a request may be sent. Once the open timeout has
+// passed it lets a single probe through.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.cfg.OpenTimeout {
+			return false
+		}
+		b.setStateLocked(CircuitHalfOpen)
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// record notes the outcome of a request that allow let through. A failure
+// that does not point to an outage shows the server is answering, so it
+// counts as a success; a request cancelled by the caller counts as neither.
+func (b *circuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasProbe := b.probing && b.state == CircuitHalfOpen
+	if wasProbe {
+		b.probing = false
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The caller gave up; this says nothing about the server.
+	case err == nil || !isOutage(err):
+		// The server answered.
+		b.failures = 0
+		b.setStateLocked(CircuitClosed)
+	default:
+		b.failures++
+		b.lastError = err.Error()
+		if wasProbe || b.failures >= b.cfg.FailureThreshold {
+			b.openedAt = time.Now()
+			b.setStateLocked(CircuitOpen)
+		}
+	}
+}
+
+func (b *circuitBreaker) setStateLocked(state CircuitState) {
+	if state == b.state {
+		return
+	}
+	from := b.state
+	b.state = state
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, state)
+	}
+}
+
+// status returns the breaker's state for operators.
+func (b *circuitBreaker) status() CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return CircuitStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		OpenedAt:            b.openedAt,
+		LastError:           b.lastError,
+	}
+}
+
+// isOutage reports whether err from sending a request suggests the server is
+// down rather than that this request was refused.
+func isOutage(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var authErr *authHeaderError
+	return !errors.As(err, &authErr)
+}
+
+// RemoteCircuits reports the circuit breakers of the registered remote
+// servers, sorted by name then endpoint, so operators can see which are
+// being bypassed.
+func (s *Server) RemoteCircuits() []CircuitStatus {
+	s.mu.RLock()
+	statuses := make([]CircuitStatus, 0, len(s.remoteClients))
+	for _, rc := range s.remoteClients {
+		statuses = append(statuses, rc.client.CircuitStatus())
+	}
+	s.mu.RUnlock()
+	statuses = append(statuses, s.principalProvider.CircuitStatus()...)
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Name != statuses[j].Name {
+			return statuses[i].Name < statuses[j].Name
+		}
+		return statuses[i].Endpoint < statuses[j].Endpoint
+	})
+	return statuses
+}
@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyRemote starts a server with a "ping" tool that answers 503 while down
+// is set, counting the requests that reach it.
+func flakyRemote(t *testing.T) (*httptest.Server, *atomic.Bool, *atomic.Int32) {
+	t.Helper()
+	remote := NewServer("flaky", "1.0")
+	remote.RegisterTool(NewTool("ping", "Ping"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("pong"), nil
+	})
+	var down atomic.Bool
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		remote.HandleRequest(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &down, &hits
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	var transitions []string
+	b := newCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      20 * time.Millisecond,
+		OnStateChange:    func(from, to CircuitState) { transitions = append(transitions, from.String()+">"+to.String()) },
+	})
+	ctx := context.Background()
+	outage := errors.New("connection refused")
+
+	b.record(ctx, &httpStatusError{StatusCode: http.StatusUnauthorized})
+	b.record(ctx, outage)
+	if b.status().State != CircuitClosed {
+		t.Fatal("one outage failure should not open the circuit")
+	}
+	b.record(ctx, outage)
+	if b.status().State != CircuitOpen || b.allow() {
+		t.Fatal("the circuit should open after two consecutive failures")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("a probe should be let through after the open timeout")
+	}
+	if b.allow() {
+		t.Fatal("only one probe should be let through")
+	}
+	b.record(ctx, outage)
+	if b.status().State != CircuitOpen {
+		t.Fatal("a failed probe should open the circuit again")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	b.allow()
+	b.record(ctx, nil)
+	if status := b.status(); status.State != CircuitClosed || status.ConsecutiveFailures != 0 {
+		t.Fatalf("a successful probe should close the circuit: %+v", status)
+	}
+
+	want := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transition %d is %s, want %s", i, transitions[i], want[i])
+		}
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	ts, down, hits := flakyRemote(t)
+	client := NewClient(ts.URL, nil, "flaky").
+		WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond})
+	ctx := context.Background()
+	if _, err := client.CallTool(ctx, "ping", nil); err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+
+	down.Store(true)
+	for range 2 {
+		if _, err := client.CallTool(ctx, "ping", nil); err == nil {
+			t.Fatal("expected an error while the server is down")
+		}
+	}
+	before := hits.Load()
+	if _, err := client.CallTool(ctx, "ping", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if hits.Load() != before {
+		t.Error("an open circuit should not contact the server")
+	}
+	if status := client.CircuitStatus(); status.State != CircuitOpen || status.Name != "flaky" || status.LastError == "" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := client.CallTool(ctx, "ping", nil); err != nil {
+		t.Fatalf("the probe should reach the recovered server: %v", err)
+	}
+	if client.CircuitStatus().State != CircuitClosed {
+		t.Error("the circuit should close once the server answers")
+	}
+}
+
+func TestRemoteProviderServesStaleToolsWhenDown(t *testing.T) {
+	ts, down, hits := flakyRemote(t)
+	provider := NewRemoteProvider(func(ctx context.Context) ([]RemoteProviderConfig, error) {
+		return []RemoteProviderConfig{{
+			Name:           "flaky",
+			URL:            ts.URL,
+			CacheTTL:       time.Millisecond,
+			CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute},
+		}}, nil
+	})
+	listTools := func() []MCPTool {
+		tools, err := provider.GetTools(WithToolProviders(context.Background(), provider))
+		if err != nil {
+			t.Fatalf("GetTools: %v", err)
+		}
+		return tools
+	}
+	if tools := listTools(); len(tools) != 1 {
+		t.Fatalf("expected 1 tool, got %+v", tools)
+	}
+
+	down.Store(true)
+	time.Sleep(5 * time.Millisecond)
+	if tools := listTools(); len(tools) != 1 || tools[0].Name != "flaky__ping" {
+		t.Fatalf("the last known tools should be served while down, got %+v", tools)
+	}
+	before := hits.Load()
+	if tools := listTools(); len(tools) != 1 {
+		t.Fatalf("the last known tools should be served while the circuit is open, got %+v", tools)
+	}
+	if hits.Load() != before {
+		t.Error("an open circuit should not contact the server")
+	}
+
+	statuses := provider.CircuitStatus()
+	if len(statuses) != 1 || statuses[0].State != CircuitOpen || statuses[0].Endpoint != ts.URL {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+}
+
+func TestRefreshToolsKeepsToolsOfUnreachableRemote(t *testing.T) {
+	ts, down, _ := flakyRemote(t)
+	server := NewServer("gateway", "1.0")
+	if err := server.RegisterRemoteServer(NewClient(ts.URL, nil, "flaky"),
+		WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1})); err != nil {
+		t.Fatal(err)
+	}
+
+	down.Store(true)
+	if err := server.RefreshTools(context.Background()); err != nil {
+		t.Fatalf("RefreshTools: %v", err)
+	}
+	if tools := server.ListTools(); len(tools) != 1 || tools[0].Name != "flaky__ping" {
+		t.Errorf("the remote's last known tools should be kept, got %+v", tools)
+	}
+	if statuses := server.RemoteCircuits(); len(statuses) != 1 || statuses[0].State != CircuitOpen {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+}
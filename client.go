@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/paularlott/mcp/pool"
 )
@@ -30,17 +31,23 @@ type ToolFilterFunc func(toolName string) bool
 
 // Client represents an MCP client for connecting to remote servers
 type Client struct {
-	baseURL     string
-	httpClient  *http.Client
-	auth        AuthProvider
-	namespace   string         // Optional namespace for tool names (e.g., "scriptling.")
-	separator   string         // Separator for namespace
-	cachedTools []MCPTool      // Cached tools with namespace already applied
-	toolFilter  ToolFilterFunc // Optional filter for tools (applied to original name without namespace)
-	mu          sync.RWMutex
-	initialized bool
-	sessionID   string
-	transport   clientTransport // non-nil for non-HTTP transports (e.g. stdio)
+	baseURL      string
+	httpClient   *http.Client
+	auth         AuthProvider
+	namespace    string            // Optional namespace for tool names (e.g., "scriptling.")
+	separator    string            // Separator for namespace
+	cachedTools  []MCPTool         // Cached tools with namespace already applied
+	toolFilter   ToolFilterFunc    // Optional filter for tools (applied to original name without namespace)
+	transforms   ToolTransforms    // Optional renames and argument rewrites, by original name
+	renamed      map[string]string // Original names of renamed tools, by new name
+	transformErr error             // Why the transforms were rejected, if they were
+	retryTools   map[string]bool   // Tools safe to call again after a lost session, from the last tools/list
+	mu           sync.RWMutex
+	initialized  bool
+	sessionID    string
+	transport    clientTransport // non-nil for non-HTTP transports (e.g. stdio)
+	breaker      atomic.Pointer[circuitBreaker]
+	breakerSet   atomic.Bool // WithCircuitBreaker was called, even to disable it
 
 	// Notification reader lifecycle. Kept on its own mutex so notification
 	// handling can't deadlock with c.mu (the request/cache lock).
@@ -56,6 +63,7 @@ type Client struct {
 	onToolsChanged     func()
 	onResourcesChanged func()
 	onPromptsChanged   func()
+	onReconnect        func()
 	// onNotification is an internal hook used by the federation wiring
 	// (RegisterRemoteServer) to propagate upstream listChanged notifications
 	// downstream. It fires for every received notification, after cache handling.
@@ -147,6 +155,22 @@ func NewClientWithPool(baseURL string, auth AuthProvider, namespace string, http
 	}
 }
 
+// initializeSessionID returns the session ID an initialize response carries,
+// if any.
+func initializeSessionID(resp *MCPResponse, respHeaders http.Header) string {
+	// Check for session ID in response headers first
+	if sessionID := respHeaders.Get(headerSessionID); sessionID != "" {
+		return sessionID
+	}
+	// Check if the server provided a session ID in the response body
+	if result, ok := resp.Result.(map[string]any); ok {
+		if sessionID, ok := result["sessionId"].(string); ok {
+			return sessionID
+		}
+	}
+	return ""
+}
+
 // Initialize performs the MCP handshake with the remote server
 func (c *Client) Initialize(ctx context.Context) error {
 	c.mu.Lock()
@@ -180,18 +204,7 @@ func (c *Client) Initialize(ctx context.Context) error {
 		return fmt.Errorf("initialize error: %s", resp.Error.Message)
 	}
 
-	// Check for session ID in response headers first
-	if sessionID := respHeaders.Get(headerSessionID); sessionID != "" {
-		c.sessionID = sessionID
-	} else if result, ok := resp.Result.(map[string]any); ok {
-		// Check if the server provided a session ID in the response body
-		if sessionID, exists := result["sessionId"]; exists {
-			if sessionStr, ok := sessionID.(string); ok {
-				c.sessionID = sessionStr
-			}
-		}
-	}
-
+	c.sessionID = initializeSessionID(&resp, respHeaders)
 	c.initialized = true
 
 	// Start the notification reader (HTTP SSE) only if the caller opted in via
@@ -207,6 +220,15 @@ func (c *Client) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// registryKey identifies the client among a server's remotes: its URL, or for
+// stream clients, which have none, the client itself.
+func (c *Client) registryKey() string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return fmt.Sprintf("%p", c)
+}
+
 // Namespace returns the namespace for this client's tools.
 func (c *Client) Namespace() string {
 	return c.namespace
@@ -226,6 +248,56 @@ func (c *Client) WithToolFilter(filter ToolFilterFunc) *Client {
 	return c
 }
 
+// WithToolTransforms sets how this client's tools are presented and called
+// (see ToolTransform). ListTools returns renamed tools with rewritten
+// schemas, and CallTool maps the new names back and rewrites the arguments.
+// The filter, if any, still receives original names. Pass nil to clear the
+// transforms. Transforms that fail Validate are rejected: ListTools and
+// CallTool return the error until valid ones are set. Returns the client for
+// chaining.
+// Note: Setting transforms clears the tool cache to ensure consistency.
+func (c *Client) WithToolTransforms(transforms ToolTransforms) *Client {
+	renamed, err := transforms.reverse()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transforms = transforms
+	c.renamed = renamed
+	c.transformErr = err
+	c.cachedTools = nil // Clear cache when transforms change
+	return c
+}
+
+// WithCircuitBreaker puts a circuit breaker in front of the server (see
+// CircuitBreakerConfig): after repeated outage failures requests fail at once
+// with ErrCircuitOpen, until a probe finds the server answering again. A
+// negative FailureThreshold removes the breaker. Returns the client for
+// chaining.
+func (c *Client) WithCircuitBreaker(cfg CircuitBreakerConfig) *Client {
+	c.breaker.Store(newCircuitBreaker(cfg))
+	c.breakerSet.Store(true)
+	return c
+}
+
+// defaultCircuitBreaker gives the client a breaker with the default settings
+// unless it has been configured with WithCircuitBreaker.
+func (c *Client) defaultCircuitBreaker() {
+	if c.breakerSet.CompareAndSwap(false, true) {
+		c.breaker.Store(newCircuitBreaker(CircuitBreakerConfig{}))
+	}
+}
+
+// CircuitStatus reports the state of the client's circuit breaker. A client
+// without one is always closed.
+func (c *Client) CircuitStatus() CircuitStatus {
+	status := CircuitStatus{Name: strings.TrimSuffix(c.namespace, c.separator), Endpoint: c.baseURL}
+	if breaker := c.breaker.Load(); breaker != nil {
+		s := breaker.status()
+		s.Name, s.Endpoint = status.Name, status.Endpoint
+		return s
+	}
+	return status
+}
+
 // GetToolFilter returns the current tool filter, or nil if none is set.
 func (c *Client) GetToolFilter() ToolFilterFunc {
 	c.mu.RLock()
@@ -233,7 +305,8 @@ func (c *Client) GetToolFilter() ToolFilterFunc {
 	return c.toolFilter
 }
 
-// ListTools retrieves tools from the remote server
+// ListTools retrieves tools from the remote server, with the client's
+// namespace, filter and transforms applied
 func (c *Client) ListTools(ctx context.Context) ([]MCPTool, error) {
 	if !c.initialized {
 		if err := c.Initialize(ctx); err != nil {
@@ -243,6 +316,10 @@ func (c *Client) ListTools(ctx context.Context) ([]MCPTool, error) {
 
 	// Check cache first
 	c.mu.RLock()
+	if err := c.transformErr; err != nil {
+		c.mu.RUnlock()
+		return nil, err
+	}
 	if c.cachedTools != nil {
 		result := make([]MCPTool, len(c.cachedTools))
 		copy(result, c.cachedTools)
@@ -275,6 +352,8 @@ func (c *Client) ListTools(ctx context.Context) ([]MCPTool, error) {
 	// Add namespace to tool names, apply filter, and cache the results
 	c.mu.Lock()
 	filter := c.toolFilter
+	transforms, renamed := c.transforms, c.renamed
+	c.retryTools = retryableTools(tools)
 	c.mu.Unlock()
 
 	var namespacedTools []MCPTool
@@ -283,10 +362,18 @@ func (c *Client) ListTools(ctx context.Context) ([]MCPTool, error) {
 		if filter != nil && !filter(tool.Name) {
 			continue
 		}
+		if tr, ok := transforms[tool.Name]; ok {
+			tool = tr.apply(tool)
+			tool.Name = transforms.exposedName(tool.Name)
+		} else if orig, ok := renamed[tool.Name]; ok && (filter == nil || filter(orig)) {
+			return nil, fmt.Errorf("tool transforms: %q is renamed to %q, which the server already has", orig, tool.Name)
+		}
 		namespacedTools = append(namespacedTools, MCPTool{
-			Name:        c.namespace + tool.Name,
-			Description: tool.Description,
-			InputSchema: tool.InputSchema,
+			Name:         c.namespace + tool.Name,
+			Description:  tool.Description,
+			InputSchema:  tool.InputSchema,
+			OutputSchema: tool.OutputSchema,
+			Annotations:  tool.Annotations,
 		})
 	}
 
@@ -311,6 +398,8 @@ func (c *Client) RefreshToolCache(ctx context.Context) error {
 // If the client has a namespace, the tool name should include it (e.g., "scriptling.search").
 // The namespace will be stripped before calling the underlying tool.
 // If a tool filter is set and the tool is filtered out, returns ErrToolFiltered.
+// A tool renamed by the client's transforms is called by its new name; the
+// arguments are rewritten as the transform says before they are sent.
 func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (*ToolResponse, error) {
 	if !c.initialized {
 		if err := c.Initialize(ctx); err != nil {
@@ -318,18 +407,9 @@ func (c *Client) CallTool(ctx context.Context, name string, args map[string]any)
 		}
 	}
 
-	// Strip namespace if present
-	toolName := name
-	if c.namespace != "" && strings.HasPrefix(name, c.namespace) {
-		toolName = name[len(c.namespace):]
-	}
-
-	// Check tool filter if set
-	c.mu.RLock()
-	filter := c.toolFilter
-	c.mu.RUnlock()
-	if filter != nil && !filter(toolName) {
-		return nil, ErrToolFiltered
+	toolName, args, err := c.prepareCall(name, args)
+	if err != nil {
+		return nil, err
 	}
 
 	req := MCPRequest{
@@ -367,9 +447,39 @@ func (c *Client) CallTool(ctx context.Context, name string, args map[string]any)
 	return &ToolResponse{
 		Content:           result.Content,
 		StructuredContent: result.StructuredContent,
+		IsError:           result.IsError,
 	}, nil
 }
 
+// prepareCall strips the namespace from name, maps a transformed name back to
+// the original, checks the filter and rewrites args, returning the name and
+// arguments to send.
+func (c *Client) prepareCall(name string, args map[string]any) (string, map[string]any, error) {
+	// Strip namespace if present
+	toolName := name
+	if c.namespace != "" && strings.HasPrefix(name, c.namespace) {
+		toolName = name[len(c.namespace):]
+	}
+
+	c.mu.RLock()
+	filter := c.toolFilter
+	transforms, renamed, err := c.transforms, c.renamed, c.transformErr
+	c.mu.RUnlock()
+	if err != nil {
+		return "", nil, err
+	}
+
+	toolName, tr, ok := transforms.resolve(renamed, toolName)
+	if !ok {
+		return "", nil, ErrToolFiltered
+	}
+	// Check tool filter if set
+	if filter != nil && !filter(toolName) {
+		return "", nil, ErrToolFiltered
+	}
+	return toolName, tr.arguments(args), nil
+}
+
 // ListResources retrieves the list of resources from the remote server via
 // resources/list. Unlike tools, resources are not cached: each call performs a
 // fresh request, since resource sets can change between calls.
@@ -566,9 +676,69 @@ func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]str
 	return &result, nil
 }
 
+// httpStatusError is returned when the server answers with a status other
+// than 200 OK.
+type httpStatusError struct {
+	StatusCode int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("server returned status %d", e.StatusCode)
+}
+
+// authHeaderError is returned when the auth provider fails to supply a header.
+type authHeaderError struct {
+	err error
+}
+
+func (e *authHeaderError) Error() string { return "failed to get auth header: " + e.err.Error() }
+func (e *authHeaderError) Unwrap() error { return e.err }
+
 // sendRequest sends a request to the MCP server. When a non-HTTP transport is
 // configured (e.g. stdio) it is used; otherwise the request is sent over HTTP.
+// If the server has lost the client's session, a new one is started and the
+// request is sent again when it is safe to repeat; otherwise it fails with
+// ErrSessionLost.
 func (c *Client) sendRequest(ctx context.Context, req *MCPRequest, resp *MCPResponse, respHeaders *http.Header) error {
+	if req.Method == "initialize" || c.transport != nil {
+		return c.sendThroughBreaker(ctx, req, resp, respHeaders)
+	}
+
+	c.mu.RLock()
+	sessionID := c.sessionID
+	c.mu.RUnlock()
+
+	err := c.sendThroughBreaker(ctx, req, resp, respHeaders)
+	if sessionID == "" || !isSessionLost(err) {
+		return err
+	}
+	if err := c.renewSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("%w: %w", ErrSessionLost, err)
+	}
+	if !c.retryable(req) {
+		return ErrSessionLost
+	}
+	*resp = MCPResponse{}
+	return c.sendThroughBreaker(ctx, req, resp, respHeaders)
+}
+
+// sendThroughBreaker sends req, or while the client's circuit is open fails
+// with ErrCircuitOpen instead.
+func (c *Client) sendThroughBreaker(ctx context.Context, req *MCPRequest, resp *MCPResponse, respHeaders *http.Header) error {
+	breaker := c.breaker.Load()
+	if breaker == nil {
+		return c.send(ctx, req, resp, respHeaders)
+	}
+	if !breaker.allow() {
+		return ErrCircuitOpen
+	}
+	err := c.send(ctx, req, resp, respHeaders)
+	breaker.record(ctx, err)
+	return err
+}
+
+// send sends req over the client's transport, or over HTTP if it has none.
+func (c *Client) send(ctx context.Context, req *MCPRequest, resp *MCPResponse, respHeaders *http.Header) error {
 	if c.transport != nil {
 		return c.transport.roundTrip(ctx, req, resp, respHeaders)
 	}
@@ -594,7 +764,7 @@ func (c *Client) sendRequest(ctx context.Context, req *MCPRequest, resp *MCPResp
 	if c.auth != nil {
 		authHeader, err := c.auth.GetAuthHeader()
 		if err != nil {
-			return fmt.Errorf("failed to get auth header: %w", err)
+			return &authHeaderError{err: err}
 		}
 		httpReq.Header.Set("Authorization", authHeader)
 	}
@@ -611,7 +781,7 @@ func (c *Client) sendRequest(ctx context.Context, req *MCPRequest, resp *MCPResp
 	}
 
 	if httpResp.StatusCode != http.StatusOK {
-		return fmt.Errorf("server returned status %d", httpResp.StatusCode)
+		return &httpStatusError{StatusCode: httpResp.StatusCode}
 	}
 
 	// Read the entire response body first
@@ -662,15 +832,20 @@ func (c *Client) parseEventStream(data []byte, resp *MCPResponse) error {
 // This is useful when the server has many tools registered via a discovery registry.
 // The query searches tool names, descriptions, and keywords.
 func (c *Client) ToolSearch(ctx context.Context, query string, maxResults int) ([]map[string]any, error) {
+	return c.ToolSearchWithFilter(ctx, query, maxResults, ToolSearchFilter{})
+}
+
+// ToolSearchWithFilter is ToolSearch restricted to tools matching the filter.
+// Servers that predate faceted search ignore the filter.
+func (c *Client) ToolSearchWithFilter(ctx context.Context, query string, maxResults int, filter ToolSearchFilter) ([]map[string]any, error) {
 	if !c.initialized {
 		if err := c.Initialize(ctx); err != nil {
 			return nil, err
 		}
 	}
 
-	args := map[string]any{
-		"query": query,
-	}
+	args := filter.args()
+	args["query"] = query
 	if maxResults > 0 {
 		args["max_results"] = maxResults
 	}
@@ -681,7 +856,18 @@ func (c *Client) ToolSearch(ctx context.Context, query string, maxResults int) (
 	}
 
 	// Parse the response - tool_search returns JSON with search results
-	return parseToolSearchResponse(resp)
+	results, err := parseToolSearchResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	transforms := c.transforms
+	c.mu.RUnlock()
+	for _, result := range results {
+		transforms.applySearchResult(result)
+	}
+	return results, nil
 }
 
 // Args is a map of tool arguments. It can be used directly as a map[string]any
@@ -772,32 +958,25 @@ func (c *Client) callToolsParallel(ctx context.Context, calls []ToolCall, discov
 }
 
 // callToolsBatch sends every call as one wire-level batch via bt, applying the
-// same namespace stripping and tool-filter checks as CallTool, then decodes
+// same namespace stripping, transforms and tool-filter checks as CallTool, then decodes
 // each response back into a ParallelToolResult in call order.
 func (c *Client) callToolsBatch(ctx context.Context, bt batchTransport, calls []ToolCall, discovered bool) []ParallelToolResult {
 	results := make([]ParallelToolResult, len(calls))
 	reqs := make([]*MCPRequest, len(calls))
 
-	c.mu.RLock()
-	filter := c.toolFilter
-	c.mu.RUnlock()
-
 	for i, call := range calls {
-		toolName := call.Name
-		if c.namespace != "" && strings.HasPrefix(toolName, c.namespace) {
-			toolName = toolName[len(c.namespace):]
-		}
-		if filter != nil && !filter(toolName) {
-			results[i] = ParallelToolResult{Name: call.Name, Err: ErrToolFiltered}
+		toolName, args, err := c.prepareCall(call.Name, call.Arguments)
+		if err != nil {
+			results[i] = ParallelToolResult{Name: call.Name, Err: err}
 			continue
 		}
 
 		method := "tools/call"
-		params := map[string]any{"name": toolName, "arguments": call.Arguments}
+		params := map[string]any{"name": toolName, "arguments": args}
 		if discovered {
 			params = map[string]any{
 				"name":      "execute_tool",
-				"arguments": map[string]any{"name": toolName, "parameters": call.Arguments},
+				"arguments": map[string]any{"name": toolName, "parameters": args},
 			}
 		}
 		reqs[i] = &MCPRequest{
@@ -822,7 +1001,17 @@ func (c *Client) callToolsBatch(ctx context.Context, bt batchTransport, calls []
 		return results
 	}
 
+	breaker := c.breaker.Load()
+	if breaker != nil && !breaker.allow() {
+		for _, i := range wireIdx {
+			results[i] = ParallelToolResult{Name: calls[i].Name, Err: fmt.Errorf("call tool failed: %w", ErrCircuitOpen)}
+		}
+		return results
+	}
 	resps, err := bt.batchRoundTrip(ctx, wireReqs)
+	if breaker != nil {
+		breaker.record(ctx, err)
+	}
 	if err != nil {
 		for _, i := range wireIdx {
 			results[i] = ParallelToolResult{Name: calls[i].Name, Err: fmt.Errorf("call tool failed: %w", err)}
@@ -861,6 +1050,7 @@ func decodeToolResult(resp *MCPResponse) *ToolResponse {
 	return &ToolResponse{
 		Content:           result.Content,
 		StructuredContent: result.StructuredContent,
+		IsError:           result.IsError,
 	}
 }
 
@@ -874,9 +1064,20 @@ func (c *Client) ExecuteDiscoveredTool(ctx context.Context, name string, argumen
 		}
 	}
 
+	c.mu.RLock()
+	transforms, renamed, err := c.transforms, c.renamed, c.transformErr
+	c.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	name, tr, ok := transforms.resolve(renamed, name)
+	if !ok {
+		return nil, ErrToolFiltered
+	}
+
 	args := map[string]any{
 		"name":       name,
-		"parameters": arguments,
+		"parameters": tr.arguments(arguments),
 	}
 
 	return c.CallTool(ctx, "execute_tool", args)
@@ -914,7 +1115,14 @@ func parseToolSearchResponse(resp *ToolResponse) ([]map[string]any, error) {
 
 	var results []map[string]any
 	if err := json.Unmarshal([]byte(jsonText), &results); err != nil {
-		return nil, fmt.Errorf("failed to parse tool search response: %w", err)
+		// Faceted searches wrap the results in an object.
+		var faceted struct {
+			Results []map[string]any `json:"results"`
+		}
+		if json.Unmarshal([]byte(jsonText), &faceted) != nil || faceted.Results == nil {
+			return nil, fmt.Errorf("failed to parse tool search response: %w", err)
+		}
+		results = faceted.Results
 	}
 	for _, result := range results {
 		if _, ok := result["inputSchema"]; !ok {
@@ -950,6 +1158,9 @@ func parseToolsResult(result any) ([]MCPTool, error) {
 						if outputSchema, ok := toolMap["outputSchema"]; ok {
 							tool.OutputSchema = outputSchema
 						}
+						if annotations, ok := toolMap["annotations"].(map[string]any); ok {
+							tool.Annotations = parseToolAnnotations(annotations)
+						}
 						tools = append(tools, tool)
 					}
 				}
@@ -971,3 +1182,17 @@ func parseToolsResult(result any) ([]MCPTool, error) {
 	}
 	return parsed.Tools, nil
 }
+
+// parseToolAnnotations decodes a tool's annotations object, returning nil if
+// it is malformed.
+func parseToolAnnotations(raw map[string]any) *ToolAnnotations {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var annotations ToolAnnotations
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil
+	}
+	return &annotations
+}
@@ -87,17 +87,31 @@ func (c *Client) startNotifications() {
 }
 
 // runSSEReader maintains a long-lived GET event-stream connection, reconnecting
-// with exponential backoff after transient failures until the client is closed.
+// with jittered exponential backoff after transient failures until the client
+// is closed. Each reconnection fires the OnReconnect callback.
 func (c *Client) runSSEReader(ctx context.Context) {
 	backoff := time.Second
+	connected := false
 	for {
 		if ctx.Err() != nil {
 			return
 		}
-		err := c.connectAndReadSSE(ctx)
+		err := c.connectAndReadSSE(ctx, func() {
+			if connected {
+				c.reconnected()
+			}
+			connected = true
+			backoff = time.Second
+		})
 		if ctx.Err() != nil {
 			return
 		}
+		if err == ErrSessionLost {
+			// A new session was started, which fired OnReconnect; connect to
+			// it straight away.
+			connected = false
+			continue
+		}
 		if err == nil {
 			backoff = time.Second
 			continue
@@ -105,7 +119,7 @@ func (c *Client) runSSEReader(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(backoff):
+		case <-time.After(jitter(backoff)):
 		}
 		backoff *= 2
 		if backoff > 30*time.Second {
@@ -114,9 +128,11 @@ func (c *Client) runSSEReader(ctx context.Context) {
 	}
 }
 
-// connectAndReadSSE opens one GET event-stream request and blocks reading
-// notifications until the stream ends or the context is cancelled.
-func (c *Client) connectAndReadSSE(ctx context.Context) error {
+// connectAndReadSSE opens one GET event-stream request, calls connected once
+// the server accepts it, and blocks reading notifications until the stream
+// ends or the context is cancelled. If the server has lost the session, a new
+// one is started and ErrSessionLost returned.
+func (c *Client) connectAndReadSSE(ctx context.Context, connected func()) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL, nil)
 	if err != nil {
 		return err
@@ -142,9 +158,16 @@ func (c *Client) connectAndReadSSE(ctx context.Context) error {
 		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && sessionID != "" {
+		if err := c.renewSession(ctx, sessionID); err != nil {
+			return fmt.Errorf("%w: %w", ErrSessionLost, err)
+		}
+		return ErrSessionLost
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("event stream returned status %d", resp.StatusCode)
 	}
+	connected()
 
 	reader := bufio.NewReader(resp.Body)
 	for {
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// ErrSessionLost is returned for a request the server refused because it no
+// longer knows the client's session, typically after a restart, when the
+// request was not retried. The client has already started a new session, so
+// the request may be sent again if it is safe to repeat.
+var ErrSessionLost = errors.New("mcp: server session lost")
+
+// OnReconnect registers a callback fired whenever the client reconnects to
+// the server: after it starts a new session because the server lost the old
+// one, after its notification stream reconnects, and after a respawning
+// stdio client restarts its process (see WithClientRespawn). Notifications
+// may have been missed meanwhile, so the client also drops its tool cache.
+// The callback must not block. Returns the client for chaining.
+func (c *Client) OnReconnect(fn func()) *Client {
+	c.mu.Lock()
+	c.onReconnect = fn
+	c.mu.Unlock()
+	return c
+}
+
+// reconnected drops the tool cache and fires the OnReconnect callback.
+func (c *Client) reconnected() {
+	c.mu.Lock()
+	c.cachedTools = nil
+	cb := c.onReconnect
+	c.mu.Unlock()
+	if cb != nil {
+		cb()
+	}
+}
+
+// isSessionLost reports whether err is the 404 a server answers with for a
+// session it does not know.
+func isSessionLost(err error) bool {
+	var statusErr *httpStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// renewSession starts a new session after the server lost the one with ID
+// stale. Of several requests that find the session lost at once, the first
+// re-initializes and the others use its session.
+func (c *Client) renewSession(ctx context.Context, stale string) error {
+	c.mu.Lock()
+	reset := c.initialized && c.sessionID == stale
+	if reset {
+		c.initialized = false
+		c.sessionID = ""
+	}
+	c.mu.Unlock()
+
+	if err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	if reset {
+		c.reconnected()
+	}
+	return nil
+}
+
+// retryable reports whether req may be sent again once a new session is
+// started: anything but a tool call, and calls to tools the last tools/list
+// annotated as idempotent or read-only.
+func (c *Client) retryable(req *MCPRequest) bool {
+	if req.Method != "tools/call" {
+		return true
+	}
+	params, _ := req.Params.(map[string]any)
+	name, _ := params["name"].(string)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.retryTools[name]
+}
+
+// retryableTools returns the names of the tools annotated as idempotent or
+// read-only, which are safe to call again after a failure.
+func retryableTools(tools []MCPTool) map[string]bool {
+	retry := make(map[string]bool)
+	for _, tool := range tools {
+		if a := tool.Annotations; a != nil && (a.IdempotentHint || a.ReadOnlyHint) {
+			retry[tool.Name] = true
+		}
+	}
+	return retry
+}
+
+// jitter returns a random duration between half of d and d, so clients
+// reconnecting after the same outage spread out.
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
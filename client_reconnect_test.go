@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// restartableServer returns a server with session management whose restart
+// forgets every session, as a real restart would.
+func restartableServer(t *testing.T) (*Server, func()) {
+	t.Helper()
+	server := NewServer("remote", "1")
+	server.RegisterTool(NewTool("read", "Read").ReadOnly(), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("read"), nil
+	})
+	server.RegisterTool(NewTool("write", "Write"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("written"), nil
+	})
+	restart := func() {
+		sm, err := NewJWTSessionManagerWithAutoKey(time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server.SetSessionManager(sm)
+	}
+	restart()
+	return server, restart
+}
+
+func TestClientRenewsLostSession(t *testing.T) {
+	server, restart := restartableServer(t)
+	ts := httptest.NewServer(http.HandlerFunc(server.HandleRequest))
+	defer ts.Close()
+
+	var reconnects atomic.Int32
+	client := NewClient(ts.URL, nil, "").OnReconnect(func() { reconnects.Add(1) })
+	ctx := context.Background()
+	if _, err := client.ListTools(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	restart()
+	if err := client.RefreshToolCache(ctx); err != nil {
+		t.Fatalf("list after restart: %v", err)
+	}
+	if reconnects.Load() != 1 {
+		t.Errorf("OnReconnect fired %d times, want 1", reconnects.Load())
+	}
+
+	// A read-only tool is called again in the new session.
+	restart()
+	if resp, err := client.CallTool(ctx, "read", nil); err != nil || resp.Content[0].Text != "read" {
+		t.Fatalf("read after restart: %v, %v", resp, err)
+	}
+
+	// Any other tool is not, but the next call goes through.
+	restart()
+	if _, err := client.CallTool(ctx, "write", nil); !errors.Is(err, ErrSessionLost) {
+		t.Fatalf("got %v, want ErrSessionLost", err)
+	}
+	if _, err := client.CallTool(ctx, "write", nil); err != nil {
+		t.Fatalf("write in the new session: %v", err)
+	}
+	if reconnects.Load() != 3 {
+		t.Errorf("OnReconnect fired %d times, want 3", reconnects.Load())
+	}
+}
+
+func TestClientEventStreamReconnects(t *testing.T) {
+	server, _ := restartableServer(t)
+	var streams atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			server.HandleRequest(w, r)
+			return
+		}
+		// Accept the stream, then drop it.
+		streams.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+	}))
+	defer ts.Close()
+
+	reconnected := make(chan struct{}, 1)
+	client := NewClient(ts.URL, nil, "").EnableNotifications().OnReconnect(func() {
+		select {
+		case reconnected <- struct{}{}:
+		default:
+		}
+	})
+	defer client.Close()
+	if err := client.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no reconnect after %d streams", streams.Load())
+	}
+	if streams.Load() < 2 {
+		t.Errorf("reconnected after %d streams", streams.Load())
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for range 100 {
+		if d := jitter(time.Second); d < 500*time.Millisecond || d > time.Second {
+			t.Fatalf("jitter(1s) = %v", d)
+		}
+	}
+}
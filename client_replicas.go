@@ -0,0 +1,327 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/paularlott/mcp/pool"
+)
+
+// DefaultReplicaCooldown is how long a replica whose request failed is passed
+// over before it is tried again, unless WithReplicaCooldown says otherwise.
+const DefaultReplicaCooldown = 10 * time.Second
+
+// errNoReplicas is returned by a replicated client with no endpoints.
+var errNoReplicas = errors.New("mcp: no replicas configured")
+
+// ReplicaSelection chooses which replica of a replicated server serves a
+// request.
+type ReplicaSelection int
+
+const (
+	// ReplicaRoundRobin spreads requests evenly over the healthy replicas.
+	ReplicaRoundRobin ReplicaSelection = iota
+
+	// ReplicaLeastInFlight sends each request to the healthy replica with the
+	// fewest requests in progress.
+	ReplicaLeastInFlight
+)
+
+// ReplicaOption configures a replicated client.
+type ReplicaOption func(*replicaOptions)
+
+type replicaOptions struct {
+	selection ReplicaSelection
+	cooldown  time.Duration
+	httpPool  pool.HTTPPool
+}
+
+// WithReplicaSelection sets how replicas are chosen. The default is
+// ReplicaRoundRobin.
+func WithReplicaSelection(selection ReplicaSelection) ReplicaOption {
+	return func(o *replicaOptions) {
+		o.selection = selection
+	}
+}
+
+// WithReplicaCooldown sets how long a replica is passed over after a request
+// to it fails. A value <= 0 uses DefaultReplicaCooldown.
+func WithReplicaCooldown(d time.Duration) ReplicaOption {
+	return func(o *replicaOptions) {
+		o.cooldown = d
+	}
+}
+
+// WithReplicaHTTPPool sets the HTTP pool used to reach the replicas. Nil uses
+// the default secure pool.
+func WithReplicaHTTPPool(httpPool pool.HTTPPool) ReplicaOption {
+	return func(o *replicaOptions) {
+		o.httpPool = httpPool
+	}
+}
+
+// NewReplicatedClient creates a client for a server run as several replicas,
+// one URL each. Requests are spread over the replicas as the selection option
+// says, and a replica whose request fails is passed over for a cooldown
+// (passive health tracking). Each replica has its own session.
+//
+// A request that fails is retried on another replica only when repeating it
+// is safe: list, read and get requests always are, but a tool call only when
+// the tool is annotated idempotent or read-only. List requests go to the
+// first healthy replica in the order given, so tool lists stay consistent
+// while replicas run different versions during a rollout.
+//
+// Notifications are not received from replicated servers. The namespace
+// behaves as for NewClient.
+func NewReplicatedClient(urls []string, auth AuthProvider, namespace string, opts ...ReplicaOption) *Client {
+	return newReplicaSet(urls, opts...).client(auth, namespace)
+}
+
+// replicaSet tracks the health and load of a server's replicas, and which of
+// its tools are safe to retry. It outlives the clients made from it, so a
+// RemoteProvider keeps it across requests.
+type replicaSet struct {
+	urls []string
+	opts replicaOptions
+
+	mu         sync.Mutex
+	next       int             // Round-robin position
+	inFlight   []int           // Requests in progress, by replica
+	downUntil  []time.Time     // End of each replica's cooldown
+	retryTools map[string]bool // Tools safe to retry, from the last tools/list through any client
+}
+
+func newReplicaSet(urls []string, opts ...ReplicaOption) *replicaSet {
+	o := replicaOptions{cooldown: DefaultReplicaCooldown}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.cooldown <= 0 {
+		o.cooldown = DefaultReplicaCooldown
+	}
+	return &replicaSet{
+		urls:      urls,
+		opts:      o,
+		inFlight:  make([]int, len(urls)),
+		downUntil: make([]time.Time, len(urls)),
+	}
+}
+
+// client returns a client that reaches the set's replicas with auth.
+func (s *replicaSet) client(auth AuthProvider, namespace string) *Client {
+	t := &replicaTransport{
+		set:     s,
+		auth:    auth,
+		clients: make([]*Client, len(s.urls)),
+	}
+	for i := range s.urls {
+		t.clients[i] = t.newReplicaClient(i)
+	}
+	c := NewClientWithPool("", auth, namespace, s.opts.httpPool)
+	c.transport = t
+	return c
+}
+
+// pick chooses a replica not yet tried and counts a request against it,
+// returning -1 when every replica has been tried. Replicas cooling down are
+// only chosen when no other is left. sticky picks the first healthy replica
+// in order, whatever the selection.
+func (s *replicaSet) pick(tried []bool, sticky bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.urls)
+	roundRobin := !sticky && s.opts.selection == ReplicaRoundRobin
+	now := time.Now()
+	best := -1
+	for k := range n {
+		i := k
+		if roundRobin {
+			i = (s.next + k) % n
+		}
+		if tried[i] || now.Before(s.downUntil[i]) {
+			continue
+		}
+		if best < 0 || (!sticky && !roundRobin && s.inFlight[i] < s.inFlight[best]) {
+			best = i
+		}
+		if sticky || roundRobin {
+			break
+		}
+	}
+	if best < 0 {
+		// Every untried replica is cooling down: use the one that recovers first.
+		for i := range n {
+			if !tried[i] && (best < 0 || s.downUntil[i].Before(s.downUntil[best])) {
+				best = i
+			}
+		}
+	}
+	if best >= 0 {
+		if roundRobin {
+			s.next = (best + 1) % n
+		}
+		s.inFlight[best]++
+	}
+	return best
+}
+
+// setRetryTools notes which of tools are idempotent or read-only.
+func (s *replicaSet) setRetryTools(tools []MCPTool) {
+	retryTools := retryableTools(tools)
+	s.mu.Lock()
+	s.retryTools = retryTools
+	s.mu.Unlock()
+}
+
+// done records the outcome of a request to replica i.
+func (s *replicaSet) done(i int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight[i]--
+	if err != nil {
+		s.downUntil[i] = time.Now().Add(s.opts.cooldown)
+	} else {
+		s.downUntil[i] = time.Time{}
+	}
+}
+
+// replicaTransport is a clientTransport that sends each request to one of a
+// replica set's servers through a client per replica.
+type replicaTransport struct {
+	set  *replicaSet
+	auth AuthProvider
+
+	mu      sync.RWMutex
+	clients []*Client // By replica; replaced after a failure
+}
+
+func (t *replicaTransport) newReplicaClient(i int) *Client {
+	return NewClientWithPool(t.set.urls[i], t.auth, "", t.set.opts.httpPool)
+}
+
+func (t *replicaTransport) roundTrip(ctx context.Context, req *MCPRequest, resp *MCPResponse, respHeaders *http.Header) error {
+	sticky := isListMethod(req.Method)
+	retry := t.retryable(req)
+	tried := make([]bool, len(t.set.urls))
+	lastErr := errNoReplicas
+	for {
+		i := t.set.pick(tried, sticky)
+		if i < 0 {
+			return lastErr
+		}
+		tried[i] = true
+
+		*resp = MCPResponse{}
+		err := t.send(ctx, i, req, resp, respHeaders)
+		t.set.done(i, err)
+		if err == nil {
+			if req.Method == "tools/list" && resp.Error == nil {
+				t.recordTools(resp)
+			}
+			return nil
+		}
+		lastErr = err
+		if !retry || ctx.Err() != nil {
+			return lastErr
+		}
+	}
+}
+
+// send sends req to replica i, initializing its session first if need be.
+// After a failure the replica gets a new client, so a replica that restarted
+// is not sent a session it no longer knows.
+func (t *replicaTransport) send(ctx context.Context, i int, req *MCPRequest, resp *MCPResponse, respHeaders *http.Header) error {
+	t.mu.RLock()
+	client := t.clients[i]
+	t.mu.RUnlock()
+
+	var err error
+	if req.Method == "initialize" {
+		err = t.initialize(ctx, client, req, resp, respHeaders)
+	} else if err = client.Initialize(ctx); err == nil {
+		err = client.sendRequest(ctx, req, resp, respHeaders)
+	}
+	if err != nil {
+		t.mu.Lock()
+		if t.clients[i] == client {
+			t.clients[i] = t.newReplicaClient(i)
+		}
+		t.mu.Unlock()
+	}
+	return err
+}
+
+// initialize passes the outer client's initialize request to a replica's
+// client, which adopts the session it establishes.
+func (t *replicaTransport) initialize(ctx context.Context, client *Client, req *MCPRequest, resp *MCPResponse, respHeaders *http.Header) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.initialized {
+		// Already in a session; a fresh one would orphan it.
+		resp.JSONRPC = "2.0"
+		resp.ID = req.ID
+		resp.Result = map[string]any{}
+		return nil
+	}
+
+	var headers http.Header
+	if err := client.sendRequest(ctx, req, resp, &headers); err != nil {
+		return err
+	}
+	if respHeaders != nil {
+		*respHeaders = headers
+	}
+	if resp.Error == nil {
+		client.sessionID = initializeSessionID(resp, headers)
+		client.initialized = true
+	}
+	return nil
+}
+
+// retryable reports whether req may be sent again to another replica after a
+// failure.
+func (t *replicaTransport) retryable(req *MCPRequest) bool {
+	if req.Method != "tools/call" {
+		return true
+	}
+	params, _ := req.Params.(map[string]any)
+	name, _ := params["name"].(string)
+	t.set.mu.Lock()
+	defer t.set.mu.Unlock()
+	return t.set.retryTools[name]
+}
+
+// recordTools notes which tools in a tools/list response are idempotent or
+// read-only, so failed calls to them can be retried. They are kept on the
+// replica set, so later clients of the set know them without listing again.
+func (t *replicaTransport) recordTools(resp *MCPResponse) {
+	tools, err := parseToolsResult(resp.Result)
+	if err != nil {
+		return
+	}
+	t.set.setRetryTools(tools)
+}
+
+// Close closes the replicas' clients.
+func (t *replicaTransport) Close() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, client := range t.clients {
+		_ = client.Close()
+	}
+	return nil
+}
+
+// isListMethod reports whether method lists the server's tools, resources or
+// prompts.
+func isListMethod(method string) bool {
+	switch method {
+	case "tools/list", "resources/list", "resources/templates/list", "prompts/list":
+		return true
+	}
+	return false
+}
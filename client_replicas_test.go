@@ -0,0 +1,233 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// replicaServer starts a replica whose tools report its id: "whoami" is
+// idempotent and "create" is not.
+func replicaServer(t *testing.T, id string, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	s := NewServer("replica-"+id, "1.0")
+	handler := func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		calls.Add(1)
+		return NewToolResponseText(id), nil
+	}
+	s.RegisterTool(NewTool("whoami", "Report the replica").Annotations(ToolAnnotations{IdempotentHint: true}), handler)
+	s.RegisterTool(NewTool("create", "Create something"), handler)
+	s.RegisterTool(NewTool("only_"+id, "A tool only this replica has"), handler)
+	ts := httptest.NewServer(http.HandlerFunc(s.HandleRequest))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func replicaServers(t *testing.T, n int) ([]*httptest.Server, []string, []*atomic.Int32) {
+	t.Helper()
+	servers := make([]*httptest.Server, n)
+	urls := make([]string, n)
+	calls := make([]*atomic.Int32, n)
+	for i := range n {
+		calls[i] = &atomic.Int32{}
+		servers[i] = replicaServer(t, string(rune('a'+i)), calls[i])
+		urls[i] = servers[i].URL
+	}
+	return servers, urls, calls
+}
+
+func TestReplicatedClientRoundRobin(t *testing.T) {
+	_, urls, calls := replicaServers(t, 3)
+	client := NewReplicatedClient(urls, nil, "svc")
+	defer client.Close()
+
+	for range 6 {
+		if _, err := client.CallTool(context.Background(), "svc__whoami", nil); err != nil {
+			t.Fatalf("CallTool: %v", err)
+		}
+	}
+	for i, c := range calls {
+		if c.Load() != 2 {
+			t.Errorf("replica %d served %d calls, want 2", i, c.Load())
+		}
+	}
+}
+
+func TestReplicatedClientFailover(t *testing.T) {
+	servers, urls, calls := replicaServers(t, 3)
+	client := NewReplicatedClient(urls, nil, "svc")
+	defer client.Close()
+	ctx := context.Background()
+
+	// Learn the annotations, then take a replica down.
+	if _, err := client.ListTools(ctx); err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	servers[1].Close()
+
+	for range 6 {
+		resp, err := client.CallTool(ctx, "svc__whoami", nil)
+		if err != nil {
+			t.Fatalf("idempotent calls should fail over: %v", err)
+		}
+		if resp.Content[0].Text == "b" {
+			t.Error("the dead replica should not answer")
+		}
+	}
+	if calls[0].Load()+calls[2].Load() != 6 {
+		t.Errorf("live replicas served %d calls, want 6", calls[0].Load()+calls[2].Load())
+	}
+
+	// Once cooling down, the dead replica is passed over for other calls too.
+	for range 4 {
+		if _, err := client.CallTool(ctx, "svc__create", nil); err != nil {
+			t.Errorf("calls should avoid the unhealthy replica: %v", err)
+		}
+	}
+}
+
+func TestReplicatedClientNoRetryForNonIdempotent(t *testing.T) {
+	servers, urls, calls := replicaServers(t, 2)
+	client := NewReplicatedClient(urls, nil, "svc", WithReplicaCooldown(1))
+	defer client.Close()
+	ctx := context.Background()
+	if _, err := client.ListTools(ctx); err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	servers[0].Close()
+
+	failures := 0
+	for range 4 {
+		if _, err := client.CallTool(ctx, "svc__create", nil); err != nil {
+			failures++
+		}
+	}
+	if failures == 0 {
+		t.Error("a non-idempotent call to a dead replica should fail rather than be retried")
+	}
+	if int(calls[1].Load()) != 4-failures {
+		t.Errorf("the live replica served %d calls, want %d", calls[1].Load(), 4-failures)
+	}
+}
+
+func TestReplicatedClientListsAreSticky(t *testing.T) {
+	servers, urls, _ := replicaServers(t, 2)
+	client := NewReplicatedClient(urls, nil, "")
+	defer client.Close()
+	ctx := context.Background()
+
+	for range 3 {
+		if err := client.RefreshToolCache(ctx); err != nil {
+			t.Fatalf("RefreshToolCache: %v", err)
+		}
+		tools, _ := client.ListTools(ctx)
+		if !hasTool(tools, "only_a") {
+			t.Fatalf("lists should come from the first replica, got %+v", tools)
+		}
+	}
+
+	servers[0].Close()
+	if err := client.RefreshToolCache(ctx); err != nil {
+		t.Fatalf("lists should fail over: %v", err)
+	}
+	if tools, _ := client.ListTools(ctx); !hasTool(tools, "only_b") {
+		t.Errorf("lists should come from the next healthy replica, got %+v", tools)
+	}
+}
+
+func TestReplicatedClientLeastInFlight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	slow := NewServer("slow", "1.0")
+	slow.RegisterTool(NewTool("whoami", "Report the replica"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		close(started)
+		<-release
+		return NewToolResponseText("slow"), nil
+	})
+	slowTS := httptest.NewServer(http.HandlerFunc(slow.HandleRequest))
+	defer slowTS.Close()
+	var calls atomic.Int32
+	fastTS := replicaServer(t, "fast", &calls)
+
+	client := NewReplicatedClient([]string{slowTS.URL, fastTS.URL}, nil, "", WithReplicaSelection(ReplicaLeastInFlight))
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = client.CallTool(ctx, "whoami", nil)
+	}()
+	<-started
+	for range 3 {
+		resp, err := client.CallTool(ctx, "whoami", nil)
+		if err != nil || resp.Content[0].Text != "fast" {
+			t.Errorf("calls should go to the idle replica: %+v, %v", resp, err)
+		}
+	}
+	close(release)
+	<-done
+}
+
+func TestRemoteProviderReplicas(t *testing.T) {
+	servers, urls, calls := replicaServers(t, 2)
+	provider := NewRemoteProvider(func(ctx context.Context) ([]RemoteProviderConfig, error) {
+		return []RemoteProviderConfig{{Name: "svc", URL: urls[0], Replicas: urls[1:]}}, nil
+	})
+	server := NewServer("gateway", "1.0")
+	servers[0].Close()
+
+	// Health is kept across requests, so only the first request finds the
+	// dead replica.
+	failures := 0
+	for range 4 {
+		ctx := WithToolProviders(context.Background(), provider)
+		if _, err := server.CallTool(ctx, "svc__create", nil); err != nil {
+			failures++
+		}
+	}
+	if failures > 1 || int(calls[1].Load()) != 4-failures {
+		t.Errorf("got %d failures and %d calls to the live replica", failures, calls[1].Load())
+	}
+}
+
+func TestRemoteProviderReplicaRetryWhenCached(t *testing.T) {
+	servers, urls, calls := replicaServers(t, 2)
+	provider := NewRemoteProvider(func(ctx context.Context) ([]RemoteProviderConfig, error) {
+		return []RemoteProviderConfig{{Name: "svc", URL: urls[0], Replicas: urls[1:]}}, nil
+	})
+	server := NewServer("gateway", "1.0")
+	ctx := WithToolProviders(context.Background(), provider)
+	if !hasTool(server.ListToolsWithContext(ctx), "svc__whoami") {
+		t.Fatal("svc__whoami not listed")
+	}
+	servers[0].Close()
+
+	// Later requests use the cached tool list, yet still know whoami is
+	// idempotent and retry it on the live replica.
+	before := calls[1].Load()
+	for range 4 {
+		ctx := WithToolProviders(context.Background(), provider)
+		resp, err := server.CallTool(ctx, "svc__whoami", nil)
+		if err != nil || resp.Content[0].Text != "b" {
+			t.Fatalf("whoami: %v, %v", resp, err)
+		}
+	}
+	if calls[1].Load()-before != 4 {
+		t.Errorf("live replica served %d calls, want 4", calls[1].Load()-before)
+	}
+}
+
+func hasTool(tools []MCPTool, name string) bool {
+	for _, tool := range tools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
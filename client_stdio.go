@@ -93,6 +93,7 @@ type stdioClientConfig struct {
 	extraEnv []string
 	dir      string
 	onExit   func(error)
+	respawn  bool
 }
 
 // WithClientStderr routes the child server's standard error to w (default:
@@ -139,6 +140,16 @@ func WithClientOnExit(fn func(error)) StdioClientOption {
 	return func(c *stdioClientConfig) { c.onExit = fn }
 }
 
+// WithClientRespawn restarts the server process if it exits unexpectedly, as
+// NewManagedStdioClient does: the next request starts a new process, after a
+// backoff that doubles with each consecutive crash, and initializes it again
+// before it is sent. The client's OnReconnect callback fires after each
+// restart, and the WithClientOnExit callback on every exit rather than once.
+// The process is not stopped when idle.
+func WithClientRespawn() StdioClientOption {
+	return func(c *stdioClientConfig) { c.respawn = true }
+}
+
 // NewStdioClient launches command (with args) as an MCP server speaking
 // newline-delimited JSON-RPC over its stdin/stdout, and returns a client
 // connected to it. Call Close to shut the child process down.
@@ -150,6 +161,9 @@ func NewStdioClient(command string, args []string, namespace string, opts ...Std
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	if cfg.respawn {
+		return newRespawningStdioClient(command, args, namespace, cfg)
+	}
 
 	procOpts := []jsonrpc.ProcessOption{}
 	if cfg.stderr != nil {
@@ -178,6 +192,27 @@ func NewStdioClient(command string, args []string, namespace string, opts ...Std
 	return newPeerClient(peer, namespace), nil
 }
 
+// newRespawningStdioClient returns a managed stdio client for command that
+// never idles out, with its first process already started.
+func newRespawningStdioClient(command string, args []string, namespace string, cfg stdioClientConfig) (*Client, error) {
+	c := NewManagedStdioClient(StdioServerConfig{
+		Command:     command,
+		Args:        args,
+		Env:         cfg.extraEnv,
+		Dir:         cfg.dir,
+		Stderr:      cfg.stderr,
+		IdleTimeout: -1,
+		OnExit:      cfg.onExit,
+		env:         cfg.env,
+	}, namespace)
+	t := c.transport.(*managedStdioTransport)
+	if _, _, err := t.acquire(context.Background(), true); err != nil {
+		return nil, err
+	}
+	t.release()
+	return c, nil
+}
+
 // NewStreamClient returns an MCP client that speaks newline-delimited JSON-RPC
 // over the given streams: it writes requests to out and reads responses from
 // in. Use it to connect to a server exposed via Server.ServeStream (for example
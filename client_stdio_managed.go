@@ -0,0 +1,373 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paularlott/jsonrpc"
+)
+
+// DefaultStdioIdleTimeout is how long a managed stdio server may sit without
+// requests before its process is stopped, when StdioServerConfig does not set
+// IdleTimeout.
+const DefaultStdioIdleTimeout = 5 * time.Minute
+
+const (
+	// stdioRestartBackoff is the wait before restarting a process that
+	// crashed, doubled for each further consecutive crash.
+	stdioRestartBackoff = 250 * time.Millisecond
+
+	// stdioMaxRestartBackoff caps the wait before a restart.
+	stdioMaxRestartBackoff = 30 * time.Second
+
+	// stdioStableRun is how long a process must run before a crash no longer
+	// counts as consecutive with the previous one.
+	stdioStableRun = time.Minute
+)
+
+// errStdioClosed is returned for requests made after a managed stdio client
+// was closed.
+var errStdioClosed = errors.New("mcp: stdio server closed")
+
+// StdioServerConfig describes an MCP server shipped as a local binary that
+// speaks newline-delimited JSON-RPC over stdin/stdout. The library starts the
+// process when it is first needed, stops it when idle and restarts it after a
+// crash.
+type StdioServerConfig struct {
+	// Command is the program to run; Args are its arguments.
+	Command string
+	Args    []string
+
+	// Env adds or overrides KEY=VALUE variables on top of the parent
+	// environment, as WithClientExtraEnv does.
+	Env []string
+
+	// Dir is the working directory of the process. Empty uses the parent's.
+	Dir string
+
+	// Stderr receives the process's standard error. Nil inherits the parent's.
+	Stderr io.Writer
+
+	// IdleTimeout stops the process after this long without requests; the
+	// next request starts it again. Zero uses DefaultStdioIdleTimeout and a
+	// negative value keeps it running until the client is closed.
+	IdleTimeout time.Duration
+
+	// OnExit, if set, is called whenever the process exits, whether it was
+	// stopped or crashed. It must not block.
+	OnExit func(error)
+
+	env []string // Replaces the whole environment, for WithClientEnv
+}
+
+// commandLine returns the command and arguments as one string, for keys and
+// messages.
+func (cfg StdioServerConfig) commandLine() string {
+	return strings.Join(append([]string{cfg.Command}, cfg.Args...), " ")
+}
+
+// key identifies the process cfg describes, so identical configurations can
+// share one.
+func (cfg StdioServerConfig) key() string {
+	return strings.Join([]string{cfg.commandLine(), cfg.Dir, strings.Join(cfg.Env, "\x00")}, "\x01")
+}
+
+// NewManagedStdioClient returns a client for the stdio server cfg describes.
+// Unlike NewStdioClient it does not start the process straight away: the
+// first request starts it, and it is stopped after cfg.IdleTimeout without
+// requests. If the process exits unexpectedly the next request restarts it,
+// waiting first for a backoff that doubles with each consecutive crash, up to
+// 30 seconds. A restarted process is initialized again before the request is
+// sent. Call Close to stop the process for good.
+//
+// The namespace behaves as for NewClient.
+func NewManagedStdioClient(cfg StdioServerConfig, namespace string) *Client {
+	separator := DefaultNamespaceSeparator
+	namespace = strings.TrimSpace(namespace)
+	if namespace != "" && !strings.HasSuffix(namespace, separator) {
+		namespace = namespace + separator
+	}
+	c := &Client{
+		namespace: namespace,
+		separator: separator,
+	}
+	c.transport = newManagedStdioTransport(cfg, c)
+	return c
+}
+
+// newSharedStdioClient returns a client that sends its requests through t,
+// which other clients may share. Notifications from the server are not
+// delivered to it.
+func newSharedStdioClient(t *managedStdioTransport, namespace string) *Client {
+	separator := DefaultNamespaceSeparator
+	namespace = strings.TrimSpace(namespace)
+	if namespace != "" && !strings.HasSuffix(namespace, separator) {
+		namespace = namespace + separator
+	}
+	return &Client{
+		namespace: namespace,
+		separator: separator,
+		transport: t,
+	}
+}
+
+// newManagedStdioTransport returns a transport for cfg's server. Notifications
+// from the server go to client if it is not nil.
+func newManagedStdioTransport(cfg StdioServerConfig, client *Client) *managedStdioTransport {
+	return &managedStdioTransport{cfg: cfg, client: client}
+}
+
+// managedStdioTransport is a clientTransport that owns a child process,
+// starting it on demand and stopping it when idle. Several clients may share
+// one: the process is initialized once and later initialize requests are
+// answered with its response.
+type managedStdioTransport struct {
+	cfg    StdioServerConfig
+	client *Client // For inbound notification handlers; nil for none
+
+	mu        sync.Mutex
+	peer      *jsonrpc.Peer
+	rpc       *stdioTransport
+	gen       int         // Incremented whenever the current process is let go
+	initReq   *MCPRequest // Last initialize request, replayed after a restart
+	initResp  any         // Result of initializing the current process
+	inFlight  int         // Requests using the current process
+	idleTimer *time.Timer // Stops the process when it fires
+	startedAt time.Time   // When the current process started
+	crashes   int         // Consecutive crashes
+	lastCrash time.Time   // When the process last crashed
+	closed    bool
+	onIdle    func() // Called after the process is stopped for idleness; nil for none
+}
+
+func (t *managedStdioTransport) roundTrip(ctx context.Context, req *MCPRequest, resp *MCPResponse, respHeaders *http.Header) error {
+	isInit := req.Method == "initialize"
+	if isInit {
+		t.mu.Lock()
+		initReq := *req
+		t.initReq = &initReq
+		t.mu.Unlock()
+	}
+
+	rpc, initResp, err := t.acquire(ctx, isInit)
+	if err != nil {
+		return err
+	}
+	defer t.release()
+	if isInit && initResp != nil {
+		resp.JSONRPC = "2.0"
+		resp.ID = req.ID
+		resp.Result = initResp
+		if respHeaders != nil {
+			*respHeaders = http.Header{}
+		}
+		return nil
+	}
+	if err := rpc.roundTrip(ctx, req, resp, respHeaders); err != nil {
+		return err
+	}
+	if isInit && resp.Error == nil {
+		t.mu.Lock()
+		if t.rpc == rpc {
+			t.initResp = resp.Result
+		}
+		t.mu.Unlock()
+	}
+	return nil
+}
+
+// batchRoundTrip implements batchTransport on the current process.
+func (t *managedStdioTransport) batchRoundTrip(ctx context.Context, reqs []*MCPRequest) ([]*MCPResponse, error) {
+	rpc, _, err := t.acquire(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer t.release()
+	return rpc.batchRoundTrip(ctx, reqs)
+}
+
+// acquire returns the transport of the running process and the result of
+// initializing it, if it has been, starting it if need be, and holds off idle
+// shutdown until release. A process started for a request other than
+// initialize is initialized first with the last initialize request seen.
+func (t *managedStdioTransport) acquire(ctx context.Context, isInit bool) (*stdioTransport, any, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for t.peer == nil {
+		if t.closed {
+			return nil, nil, errStdioClosed
+		}
+		if wait := t.restartDelay(); wait > 0 {
+			t.mu.Unlock()
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				t.mu.Lock()
+				return nil, nil, ctx.Err()
+			case <-timer.C:
+			}
+			t.mu.Lock()
+			continue // Another request may have started it meanwhile
+		}
+		if err := t.startLocked(ctx, isInit); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	t.inFlight++
+	if t.idleTimer != nil {
+		t.idleTimer.Stop()
+		t.idleTimer = nil
+	}
+	return t.rpc, t.initResp, nil
+}
+
+// restartDelay returns how much longer to wait before restarting a process
+// that crashed.
+func (t *managedStdioTransport) restartDelay() time.Duration {
+	if t.crashes == 0 {
+		return 0
+	}
+	backoff := stdioRestartBackoff << min(t.crashes-1, 16)
+	return min(backoff, stdioMaxRestartBackoff) - time.Since(t.lastCrash)
+}
+
+// startLocked starts the process and, unless the request that needs it is
+// the initialize request itself, re-initializes it.
+func (t *managedStdioTransport) startLocked(ctx context.Context, isInit bool) error {
+	gen := t.gen
+	procOpts := []jsonrpc.ProcessOption{jsonrpc.WithOnExit(func(err error) { t.exited(gen, err) })}
+	if t.cfg.Stderr != nil {
+		procOpts = append(procOpts, jsonrpc.WithStderr(t.cfg.Stderr))
+	}
+	if t.cfg.env != nil {
+		procOpts = append(procOpts, jsonrpc.WithEnv(t.cfg.env))
+	}
+	if len(t.cfg.Env) > 0 {
+		procOpts = append(procOpts, jsonrpc.WithExtraEnv(t.cfg.Env...))
+	}
+	if t.cfg.Dir != "" {
+		procOpts = append(procOpts, jsonrpc.WithDir(t.cfg.Dir))
+	}
+
+	peer, err := jsonrpc.NewProcessPeer(t.cfg.Command, t.cfg.Args, jsonrpc.NewServer(), procOpts...)
+	if err != nil {
+		return fmt.Errorf("start %s: %w", t.cfg.commandLine(), err)
+	}
+	if t.client != nil {
+		registerPeerNotificationHandlers(peer, t.client)
+	}
+	rpc := &stdioTransport{rpc: peer.Client()}
+
+	if !isInit && t.initReq != nil {
+		var resp MCPResponse
+		err := rpc.roundTrip(ctx, t.initReq, &resp, nil)
+		if err == nil && resp.Error != nil {
+			err = errors.New(resp.Error.Message)
+		}
+		if err != nil {
+			t.gen++ // Not a crash
+			_ = peer.Close()
+			return fmt.Errorf("initialize %s: %w", t.cfg.commandLine(), err)
+		}
+		t.initResp = resp.Result
+	}
+
+	// A process started after a crash is a reconnect for the client.
+	if t.client != nil && !t.startedAt.IsZero() && t.lastCrash.After(t.startedAt) {
+		go t.client.reconnected()
+	}
+
+	t.peer = peer
+	t.rpc = rpc
+	t.startedAt = time.Now()
+	return nil
+}
+
+// release marks a request done and arms the idle timer when none remain.
+func (t *managedStdioTransport) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight--
+	if t.inFlight > 0 || t.peer == nil {
+		return
+	}
+	idle := t.cfg.IdleTimeout
+	if idle == 0 {
+		idle = DefaultStdioIdleTimeout
+	}
+	if idle > 0 {
+		gen := t.gen
+		t.idleTimer = time.AfterFunc(idle, func() { t.stopIdle(gen) })
+	}
+}
+
+// stopIdle stops the process started in generation gen if it is still idle.
+func (t *managedStdioTransport) stopIdle(gen int) {
+	t.mu.Lock()
+	if gen != t.gen || t.inFlight > 0 || t.peer == nil {
+		t.mu.Unlock()
+		return
+	}
+	peer := t.detachLocked()
+	t.mu.Unlock()
+	_ = peer.Close()
+	if t.onIdle != nil {
+		t.onIdle()
+	}
+}
+
+// detachLocked lets go of the current process so its exit is not taken for
+// a crash, and returns it for closing.
+func (t *managedStdioTransport) detachLocked() *jsonrpc.Peer {
+	peer := t.peer
+	t.peer = nil
+	t.rpc = nil
+	t.initResp = nil
+	t.gen++
+	if t.idleTimer != nil {
+		t.idleTimer.Stop()
+		t.idleTimer = nil
+	}
+	return peer
+}
+
+// exited is called when the process started in generation gen exits. If it
+// was not stopped on purpose it crashed, and the next start backs off.
+func (t *managedStdioTransport) exited(gen int, err error) {
+	t.mu.Lock()
+	if gen == t.gen && t.peer != nil {
+		peer := t.detachLocked()
+		if time.Since(t.startedAt) > stdioStableRun {
+			t.crashes = 0
+		}
+		t.crashes++
+		t.lastCrash = time.Now()
+		go func() { _ = peer.Close() }()
+	}
+	t.mu.Unlock()
+
+	if t.cfg.OnExit != nil {
+		t.cfg.OnExit(err)
+	}
+}
+
+// Close stops the process for good.
+func (t *managedStdioTransport) Close() error {
+	t.mu.Lock()
+	t.closed = true
+	peer := t.detachLocked()
+	t.mu.Unlock()
+	if peer == nil {
+		return nil
+	}
+	return peer.Close()
+}
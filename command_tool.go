@@ -0,0 +1,363 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCommandTimeout is how long a command tool's process may run when no
+// WithCommandTimeout option is given.
+const DefaultCommandTimeout = 30 * time.Second
+
+// DefaultCommandMaxOutput is the number of bytes kept from each of stdout and
+// stderr when no WithCommandMaxOutput option is given.
+const DefaultCommandMaxOutput = 1 << 20
+
+// CommandOption configures a tool created with NewCommandTool.
+type CommandOption func(*commandConfig)
+
+type commandConfig struct {
+	timeout        time.Duration
+	dir            string
+	envAllow       []string
+	extraEnv       []string
+	maxOutput      int
+	allowFlagValue bool
+}
+
+// WithCommandTimeout limits how long the process may run before it is killed
+// and the call reported as an error result. A value <= 0 disables the timeout;
+// the call's context still applies.
+func WithCommandTimeout(d time.Duration) CommandOption {
+	return func(c *commandConfig) { c.timeout = d }
+}
+
+// WithCommandDir sets the working directory for the process.
+func WithCommandDir(dir string) CommandOption {
+	return func(c *commandConfig) { c.dir = dir }
+}
+
+// WithCommandEnv lists the environment variables passed through from the
+// parent process. Nothing else is inherited: without this option the process
+// starts with an empty environment (plus any WithCommandExtraEnv entries), so
+// include PATH, HOME, etc. if the command needs them.
+func WithCommandEnv(names ...string) CommandOption {
+	return func(c *commandConfig) { c.envAllow = append(c.envAllow, names...) }
+}
+
+// WithCommandExtraEnv sets KEY=VALUE environment variables for the process on
+// top of those allowed by WithCommandEnv. For a repeated key the last value
+// wins.
+func WithCommandExtraEnv(kv ...string) CommandOption {
+	return func(c *commandConfig) { c.extraEnv = append(c.extraEnv, kv...) }
+}
+
+// WithCommandMaxOutput caps how many bytes of stdout and of stderr are kept.
+// Output beyond the cap is discarded and the result notes the truncation. A
+// value <= 0 uses DefaultCommandMaxOutput.
+func WithCommandMaxOutput(n int) CommandOption {
+	return func(c *commandConfig) { c.maxOutput = n }
+}
+
+// WithCommandAllowFlagValues permits argument values starting with "-",
+// including negative numbers. They are rejected by default wherever they are
+// substituted, so a caller cannot smuggle extra flags into the command.
+func WithCommandAllowFlagValues() CommandOption {
+	return func(c *commandConfig) { c.allowFlagValue = true }
+}
+
+// commandPlaceholder matches {name} and {name?text} in argv templates.
+var commandPlaceholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.-]*)(\?[^{}]*)?\}`)
+
+// NewCommandTool adapts a command-line program into a tool. The tool's
+// parameters come from tool, and args is the argv template used for each call;
+// the results can be passed straight to RegisterTool:
+//
+//	server.RegisterTool(mcp.NewCommandTool(
+//		mcp.NewTool("grep", "Search files",
+//			mcp.String("pattern", "Regular expression", mcp.Required()),
+//			mcp.Boolean("ignore_case", "Ignore case"),
+//			mcp.StringArray("paths", "Files to search", mcp.Required()),
+//		),
+//		"grep", []string{"-rn", "{ignore_case?-i}", "--", "{pattern}", "{paths}"},
+//		mcp.WithCommandDir("/srv/data"),
+//		mcp.WithCommandEnv("PATH"),
+//		mcp.WithCommandTimeout(10*time.Second),
+//	))
+//
+// The process is started directly, never through a shell, so argument values
+// are passed verbatim. In each argv element:
+//   - {name} is replaced by the argument's value; an element that is exactly
+//     {name} with an array value expands to one element per item
+//   - {name?text} is replaced by text when the argument is set
+//   - an element referring to an argument that is omitted, null or false is
+//     left out entirely, so optional flags are written as "--limit={limit}"
+//   - braces that do not name one of the tool's parameters are left as-is
+//
+// Stdout and stderr are returned as text content. A non-zero exit status or a
+// timeout is reported as an isError result carrying the output, so the model
+// can see what went wrong; failing to start the process is returned as a
+// ToolError.
+func NewCommandTool(tool *ToolBuilder, command string, args []string, opts ...CommandOption) (*ToolBuilder, ToolHandler) {
+	cfg := commandConfig{timeout: DefaultCommandTimeout, maxOutput: DefaultCommandMaxOutput}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.maxOutput <= 0 {
+		cfg.maxOutput = DefaultCommandMaxOutput
+	}
+
+	params := make(map[string]bool)
+	for _, p := range tool.params {
+		params[p.name] = true
+	}
+	args = append([]string(nil), args...)
+
+	return tool, func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		argv, err := expandCommandArgs(args, params, req, cfg.allowFlagValue)
+		if err != nil {
+			return nil, err
+		}
+		return runCommand(ctx, cfg, command, argv)
+	}
+}
+
+// expandCommandArgs builds the argv for a call from the templates.
+func expandCommandArgs(templates []string, params map[string]bool, req *ToolRequest, allowFlagValue bool) ([]string, error) {
+	var argv []string
+	for _, tmpl := range templates {
+		matches := commandPlaceholder.FindAllStringSubmatchIndex(tmpl, -1)
+
+		// A lone placeholder may expand to several elements.
+		if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(tmpl) && matches[0][4] < 0 {
+			name := tmpl[matches[0][2]:matches[0][3]]
+			if params[name] {
+				values, err := commandArgValues(req, name, allowFlagValue)
+				if err != nil {
+					return nil, err
+				}
+				argv = append(argv, values...)
+				continue
+			}
+		}
+
+		var b strings.Builder
+		last := 0
+		drop := false
+		for _, m := range matches {
+			name := tmpl[m[2]:m[3]]
+			if !params[name] {
+				continue
+			}
+			value, set := req.lookup(name)
+			if flag, ok := value.(bool); !set || value == nil || ok && !flag {
+				drop = true
+				break
+			}
+			b.WriteString(tmpl[last:m[0]])
+			if m[4] >= 0 {
+				b.WriteString(tmpl[m[4]+1 : m[5]])
+			} else {
+				formatted := formatCommandValue(value)
+				if err := checkCommandValue(name, formatted, allowFlagValue); err != nil {
+					return nil, err
+				}
+				b.WriteString(formatted)
+			}
+			last = m[1]
+		}
+		if drop {
+			continue
+		}
+		b.WriteString(tmpl[last:])
+		argv = append(argv, b.String())
+	}
+	return argv, nil
+}
+
+// commandArgValues returns the elements for a lone {name} placeholder.
+func commandArgValues(req *ToolRequest, name string, allowFlagValue bool) ([]string, error) {
+	value, set := req.lookup(name)
+	if !set || value == nil {
+		return nil, nil
+	}
+	if b, ok := value.(bool); ok && !b {
+		return nil, nil
+	}
+
+	items := []any{value}
+	switch v := value.(type) {
+	case []any:
+		items = v
+	case []string:
+		items = make([]any, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		s := formatCommandValue(item)
+		if err := checkCommandValue(name, s, allowFlagValue); err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
+
+// checkCommandValue rejects a formatted value starting with "-", which the
+// command could read as a flag, unless allowFlagValue is set.
+func checkCommandValue(name, value string, allowFlagValue bool) error {
+	if !allowFlagValue && strings.HasPrefix(value, "-") {
+		return NewToolErrorInvalidParams(fmt.Sprintf("argument %q must not start with '-'", name))
+	}
+	return nil
+}
+
+func formatCommandValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatCommandValue(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// runCommand runs the process and converts its outcome into a response.
+func runCommand(ctx context.Context, cfg commandConfig, command string, argv []string) (*ToolResponse, error) {
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command, argv...)
+	cmd.Dir = cfg.dir
+	cmd.Env = commandEnv(cfg)
+	// Do not wait forever for pipes held open by grandchildren after a kill.
+	cmd.WaitDelay = time.Second
+
+	stdout := &cappedBuffer{limit: cfg.maxOutput}
+	stderr := &cappedBuffer{limit: cfg.maxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr), ctx.Err() != nil:
+	default:
+		return nil, NewToolErrorInternal(fmt.Sprintf("failed to run %s: %v", command, err))
+	}
+
+	var content []ToolContent
+	if text := stdout.String(); text != "" {
+		content = append(content, ToolContent{Type: "text", Text: text})
+	}
+	if text := stderr.String(); text != "" {
+		content = append(content, ToolContent{Type: "text", Text: "stderr:\n" + text})
+	}
+
+	resp := &ToolResponse{Content: content}
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		resp.IsError = true
+		msg := "command timed out"
+		if cfg.timeout > 0 {
+			msg = fmt.Sprintf("command timed out after %s", cfg.timeout)
+		}
+		resp.Content = append(resp.Content, ToolContent{Type: "text", Text: msg})
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case exitErr != nil:
+		resp.IsError = true
+		resp.Content = append(resp.Content, ToolContent{Type: "text", Text: fmt.Sprintf("command exited with status %d", exitErr.ExitCode())})
+	case len(resp.Content) == 0:
+		resp.Content = []ToolContent{{Type: "text", Text: "command completed with no output"}}
+	}
+	return resp, nil
+}
+
+// commandEnv builds the process environment from the allowlist and extras.
+// The result is never nil, as a nil Env would inherit the whole parent
+// environment.
+func commandEnv(cfg commandConfig) []string {
+	env := make([]string, 0, len(cfg.envAllow)+len(cfg.extraEnv))
+	index := make(map[string]int)
+	set := func(kv string) {
+		key, _, _ := strings.Cut(kv, "=")
+		if i, ok := index[key]; ok {
+			env[i] = kv
+			return
+		}
+		index[key] = len(env)
+		env = append(env, kv)
+	}
+	for _, name := range cfg.envAllow {
+		if value, ok := os.LookupEnv(name); ok {
+			set(name + "=" + value)
+		}
+	}
+	for _, kv := range cfg.extraEnv {
+		set(kv)
+	}
+	return env
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a chatty process neither blocks nor exhausts memory.
+type cappedBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - len(b.buf); room > 0 {
+		if len(p) > room {
+			b.buf = append(b.buf, p[:room]...)
+			b.truncated = true
+		} else {
+			b.buf = append(b.buf, p...)
+		}
+	} else if len(p) > 0 {
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.truncated {
+		return string(b.buf) + fmt.Sprintf("\n[output truncated at %d bytes]", b.limit)
+	}
+	return string(b.buf)
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandToolExpandsArgs(t *testing.T) {
+	tool := NewTool("t", "",
+		String("pattern", ""),
+		Boolean("ignore_case", ""),
+		Integer("limit", ""),
+		StringArray("paths", ""),
+	)
+	params := map[string]bool{}
+	for _, p := range tool.params {
+		params[p.name] = true
+	}
+	templates := []string{"-rn", "{ignore_case?-i}", "--limit={limit}", "--", "{pattern}", "{paths}", "{}"}
+
+	tests := []struct {
+		args map[string]any
+		want []string
+	}{
+		{
+			args: map[string]any{"pattern": "a b", "ignore_case": true, "limit": float64(5), "paths": []any{"x", "y"}},
+			want: []string{"-rn", "-i", "--limit=5", "--", "a b", "x", "y", "{}"},
+		},
+		{
+			args: map[string]any{"pattern": "$(rm -rf /)", "ignore_case": false},
+			want: []string{"-rn", "--", "$(rm -rf /)", "{}"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := expandCommandArgs(templates, params, NewToolRequest(tt.args), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("args %v: got %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	if _, err := expandCommandArgs(templates, params, NewToolRequest(map[string]any{"pattern": "--exec"}), false); err == nil {
+		t.Error("values starting with '-' should be rejected")
+	}
+	if _, err := expandCommandArgs(templates, params, NewToolRequest(map[string]any{"pattern": "--exec"}), true); err != nil {
+		t.Errorf("WithCommandAllowFlagValues should permit flag-like values: %v", err)
+	}
+
+	// Embedded placeholders and numbers are checked too.
+	embedded := []string{"{pattern}.txt", "--limit={limit}"}
+	for _, args := range []map[string]any{{"pattern": "-rf"}, {"limit": float64(-1)}} {
+		if _, err := expandCommandArgs(embedded, params, NewToolRequest(args), false); err == nil {
+			t.Errorf("args %v: values starting with '-' should be rejected in embedded placeholders", args)
+		}
+		if _, err := expandCommandArgs(embedded, params, NewToolRequest(args), true); err != nil {
+			t.Errorf("args %v: WithCommandAllowFlagValues should permit flag-like values: %v", args, err)
+		}
+	}
+}
+
+func TestCommandToolRun(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewCommandTool(
+		NewTool("echo", "Echo", String("text", "", Required())),
+		"echo", []string{"{text}"},
+	))
+	server.RegisterTool(NewCommandTool(
+		NewTool("script", "Run a script", String("script", "", Required())),
+		"sh", []string{"-c", "{script}"},
+		WithCommandEnv("PATH"),
+		WithCommandExtraEnv("GREETING=hi"),
+		WithCommandTimeout(200*time.Millisecond),
+		WithCommandMaxOutput(8),
+	))
+	ctx := context.Background()
+
+	resp, err := server.CallTool(ctx, "echo", map[string]any{"text": "hello world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsError || resp.Content[0].Text != "hello world\n" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	resp, err = server.CallTool(ctx, "script", map[string]any{"script": "echo \"$GREETING:$HOME\"; echo oops >&2; exit 3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || len(resp.Content) != 3 {
+		t.Fatalf("non-zero exit should be an error result: %+v", resp)
+	}
+	if resp.Content[0].Text != "hi:\n" {
+		t.Errorf("environment should be limited to the allowlist: %q", resp.Content[0].Text)
+	}
+	if resp.Content[1].Text != "stderr:\noops\n" || resp.Content[2].Text != "command exited with status 3" {
+		t.Errorf("unexpected content: %+v", resp.Content)
+	}
+
+	resp, err = server.CallTool(ctx, "script", map[string]any{"script": "echo 0123456789abcdef"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(resp.Content[0].Text, "01234567\n[output truncated at 8 bytes]") {
+		t.Errorf("output should be capped: %q", resp.Content[0].Text)
+	}
+
+	start := time.Now()
+	resp, err = server.CallTool(ctx, "script", map[string]any{"script": "sleep 5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || !strings.Contains(resp.Content[len(resp.Content)-1].Text, "timed out") || time.Since(start) > 3*time.Second {
+		t.Errorf("expected a timeout error result: %+v", resp)
+	}
+}
+
+func TestCommandToolStartFailure(t *testing.T) {
+	_, handler := NewCommandTool(NewTool("missing", ""), "/nonexistent/command", nil)
+	_, err := handler(context.Background(), NewToolRequest(nil))
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInternalError {
+		t.Fatalf("expected an internal ToolError, got %v", err)
+	}
+}
+
+func TestToolResponseIsErrorOverHTTP(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewCommandTool(NewTool("fail", "Fails"), "sh", []string{"-c", "echo bad >&2; exit 1"}))
+	httpServer := httptest.NewServer(http.HandlerFunc(server.HandleRequest))
+	defer httpServer.Close()
+
+	resp, err := NewClient(httpServer.URL, nil, "").CallTool(context.Background(), "fail", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsError || resp.Content[0].Text != "stderr:\nbad\n" {
+		t.Errorf("isError should reach the client: %+v", resp)
+	}
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func newShapeTool() *ToolBuilder {
+	return NewTool("draw", "Draw shapes",
+		Def("point", Object("", "A point",
+			Number("x", "x", Required()),
+			Number("y", "y", Required()),
+		)),
+		OneOf("shape", "Shape to draw",
+			Object("", "Circle",
+				String("kind", "", Enum("circle"), Required()),
+				Ref("center", "Centre", "point", Required()),
+				Number("radius", "Radius", Required()),
+			),
+			Object("", "Polygon",
+				String("kind", "", Enum("polygon"), Required()),
+				ArrayOf("points", "Vertices", Ref("", "", "point"), MinItems(3)),
+			),
+			Required(),
+		),
+		ArrayOf("matrix", "Rows of numbers", ArrayOf("", "Row", Number("", ""))),
+		Map("labels", "Labels by id", String("", "Label", MaxLength(20))),
+		AnyOf("id", "Identifier", String("", ""), Integer("", "")),
+	)
+}
+
+func TestCompositeSchema(t *testing.T) {
+	schema := newShapeTool().ToMCPTool().InputSchema.(map[string]any)
+
+	defs := schema["$defs"].(map[string]any)
+	point := defs["point"].(map[string]any)
+	if point["type"] != "object" || point["description"] != "A point" {
+		t.Errorf("unexpected point definition: %v", point)
+	}
+
+	props := schema["properties"].(map[string]any)
+	shape := props["shape"].(map[string]any)
+	variants := shape["oneOf"].([]any)
+	if len(variants) != 2 || shape["description"] != "Shape to draw" {
+		t.Fatalf("unexpected shape schema: %v", shape)
+	}
+	circle := variants[0].(map[string]any)
+	center := circle["properties"].(map[string]any)["center"].(map[string]any)
+	if center["$ref"] != "#/$defs/point" {
+		t.Errorf("unexpected ref: %v", center)
+	}
+	polygon := variants[1].(map[string]any)
+	points := polygon["properties"].(map[string]any)["points"].(map[string]any)
+	if points["minItems"] != 3 || points["items"].(map[string]any)["$ref"] != "#/$defs/point" {
+		t.Errorf("unexpected points schema: %v", points)
+	}
+
+	matrix := props["matrix"].(map[string]any)
+	row := matrix["items"].(map[string]any)
+	if row["type"] != "array" || row["items"].(map[string]any)["type"] != "number" || row["description"] != "Row" {
+		t.Errorf("unexpected matrix schema: %v", matrix)
+	}
+
+	labels := props["labels"].(map[string]any)
+	value := labels["additionalProperties"].(map[string]any)
+	if labels["type"] != "object" || value["type"] != "string" || value["maxLength"] != 20 {
+		t.Errorf("unexpected labels schema: %v", labels)
+	}
+
+	if len(props["id"].(map[string]any)["anyOf"].([]any)) != 2 {
+		t.Errorf("unexpected id schema: %v", props["id"])
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"shape"}) {
+		t.Errorf("unexpected required: %v", schema["required"])
+	}
+}
+
+func TestCompositeSchemaValidation(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(newShapeTool(), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("ok"), nil
+	})
+	ctx := context.Background()
+
+	valid := map[string]any{
+		"shape":  map[string]any{"kind": "circle", "center": map[string]any{"x": 1, "y": 2}, "radius": 3},
+		"matrix": []any{[]any{1, 2}, []any{3}},
+		"labels": map[string]any{"a": "first"},
+		"id":     7,
+	}
+	if _, err := server.CallTool(ctx, "draw", valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"bad variant": {"shape": map[string]any{"kind": "circle", "radius": 3}},
+		"bad ref":     {"shape": map[string]any{"kind": "polygon", "points": []any{map[string]any{"x": 1}, map[string]any{"x": 1, "y": 1}, map[string]any{"x": 2, "y": 2}}}},
+		"bad matrix":  {"shape": valid["shape"], "matrix": []any{[]any{"x"}}},
+		"bad map":     {"shape": valid["shape"], "labels": map[string]any{"a": 1}},
+		"bad anyOf":   {"shape": valid["shape"], "id": true},
+	} {
+		if _, err := server.CallTool(ctx, "draw", args); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
@@ -3,11 +3,18 @@ module github.com/paularlott/mcp
 go 1.26.1
 
 require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/blevesearch/snowballstem v0.9.0
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/google/uuid v1.6.0
 	github.com/paularlott/cli v0.8.5
 	github.com/paularlott/jsonrpc v0.2.0
 	golang.org/x/net v0.57.0
 	golang.org/x/oauth2 v0.36.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/text v0.40.0 // indirect
+require (
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+)
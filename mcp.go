@@ -66,8 +66,12 @@ type registeredTool struct {
 	Description  string
 	Schema       map[string]any
 	OutputSchema map[string]any
+	Annotations  *ToolAnnotations
+	Category     string
+	Tags         []string
 	Handler      ToolHandler
 	Visibility   ToolVisibility
+	Approval     *ApprovalConfig // Set when calls must be approved first
 }
 
 // Server represents an MCP server instance.
@@ -114,13 +118,25 @@ type Server struct {
 	toolToServer         map[string]*registeredClient // Tool name -> remote client mapping
 	nativeToolCache      []MCPTool                    // Native tools (visible in tools/list)
 	mu                   sync.RWMutex
-	sessionManager       SessionManager                 // Pluggable session management
-	internalRegistry     *internalRegistry              // Registry for discoverable tools (searchable)
-	hasDiscoverableTools bool                           // Track if any discoverable tools exist (local or remote)
-	resources            map[string]*registeredResource // Static resources keyed by URI
-	resourceTemplates    []*registeredResourceTemplate  // Parameterized resource templates
-	prompts              map[string]*registeredPrompt   // Static prompts keyed by name
-	notifications        *notificationHub               // Fan-out for listChanged notifications
+	sessionManager       SessionManager                  // Pluggable session management
+	internalRegistry     *internalRegistry               // Registry for discoverable tools (searchable)
+	hasDiscoverableTools bool                            // Track if any discoverable tools exist (local or remote)
+	resources            map[string]*registeredResource  // Static resources keyed by URI
+	resourceTemplates    []*registeredResourceTemplate   // Parameterized resource templates
+	prompts              map[string]*registeredPrompt    // Static prompts keyed by name
+	notifications        *notificationHub                // Fan-out for listChanged notifications
+	schemaValidation     SchemaValidationMode            // How tool arguments are validated against InputSchema
+	schemas              *schemaCache                    // Normalized input schemas for validation
+	outputValidation     outputValidationConfig          // How structuredContent is checked against OutputSchema
+	semanticSearch       *semanticSearch                 // Embedding-based tool_search ranking; nil when off
+	usage                *usageTracker                   // Usage analytics; nil when off
+	policy               *Policy                         // Access control; nil when off
+	approver             Approver                        // Default approver for tools that require approval
+	approvalRecorder     func(ApprovalRecord)            // Audit hook for approval decisions
+	approvals            approvalCache                   // Approvals cached per session
+	audit                *AuditConfig                    // Audit log; nil when off
+	principalRemotes     map[string]RemoteProviderConfig // Remotes registered WithAuthResolver, by registry key
+	principalProvider    *RemoteProvider                 // Serves principalRemotes per request
 }
 
 func (s *Server) recalcHasDiscoverableToolsLocked() {
@@ -144,12 +160,18 @@ type registeredClient struct {
 	client       *Client
 	namespace    string
 	visibility   ToolVisibility
-	remoteSearch bool // Whether to delegate tool_search to this remote
+	remoteSearch bool           // Whether to delegate tool_search to this remote
+	catalog      *remoteCatalog // Resources and prompts, namespaced
+	stdioKey     string         // Set when started from a RemoteServerEntry's Stdio; the server owns the process
+	tools        []MCPTool      // Last tool list fetched, kept while the remote is unreachable
+
+	discoveredMu sync.Mutex
+	discovered   *lruMap[SearchResult] // Tools found by its tool_search, by namespaced name; nil until the first
 }
 
 // NewServer creates a new MCP server instance.
 func NewServer(name, version string) *Server {
-	return &Server{
+	s := &Server{
 		name:              name,
 		version:           version,
 		instructions:      "",
@@ -162,7 +184,11 @@ func NewServer(name, version string) *Server {
 		resourceTemplates: make([]*registeredResourceTemplate, 0),
 		prompts:           make(map[string]*registeredPrompt),
 		notifications:     newNotificationHub(),
+		principalRemotes:  make(map[string]RemoteProviderConfig),
+		schemas:           newSchemaCache(),
 	}
+	s.principalProvider = NewRemoteProvider(s.resolvePrincipalRemotes)
+	return s
 }
 
 // SetSessionManager sets a custom session manager for the server.
@@ -189,6 +215,11 @@ func (s *Server) getDiscoveryTools() []MCPTool {
 	toolSearch := NewTool(ToolSearchName, "Search for available tools by name, description, or keywords. Returns matching tools with their names, descriptions, input schemas, and relevance scores (0.0 to 1.0, where 1.0 is an exact match and higher scores indicate better relevance). After finding a tool, use execute_tool to call it. Omit query to list all available tools.",
 		String("query", "Search query to find relevant tools (searches name, description, and keywords). Omit to list all tools."),
 		Number("max_results", "Maximum number of results to return (default: 5)"),
+		String("category", "Only return tools in this category"),
+		String("tag", "Only return tools with this tag"),
+		String("namespace", "Only return tools from this namespace (the prefix of namespaced tool names)"),
+		Boolean("read_only", "Only return tools that do not modify anything"),
+		Boolean("facets", "Return an object with the results, the total number of matches, and counts of matching tools per category, tag and namespace. Use with an empty query to browse the catalogue before narrowing down."),
 	)
 
 	executeTool := NewTool(ExecuteToolName, "Execute a tool by name with the given parameters. This is the always-safe way to call tools discovered via tool_search, whether or not they were included in tools/list for the current client.",
@@ -236,26 +267,115 @@ func (s *Server) handleToolSearch(ctx context.Context, req *ToolRequest) (*ToolR
 	s.mu.RUnlock()
 	listedTools = append(listedTools, getNativeToolsFromProviders(ctx)...)
 
+	filter := ToolSearchFilter{
+		Category:  req.StringOr("category", ""),
+		Tag:       req.StringOr("tag", ""),
+		Namespace: req.StringOr("namespace", ""),
+		ReadOnly:  req.BoolOr("read_only", false),
+	}
+	withFacets := req.BoolOr("facets", false)
+	namespaceOf := s.toolNamespacer()
+	allowed := s.toolPolicyFilter(ctx)
+	var toolFilter func(tool *MCPTool) bool
+	if !filter.isZero() || allowed != nil {
+		toolFilter = func(tool *MCPTool) bool {
+			if allowed != nil && !allowed(tool) {
+				return false
+			}
+			return filter.isZero() || filter.matches(tool.Category, tool.Tags, namespaceOf(tool.Name), tool.readOnly())
+		}
+	}
+
+	s.mu.RLock()
+	semantic := s.semanticSearch
+	usage := s.usage
+	s.mu.RUnlock()
+
+	// Facets count every match and usage boosts can promote any match, so
+	// the limit is applied afterwards.
+	limit := maxResults
+	if withFacets || (usage != nil && usage.boost > 0) {
+		limit = 0
+	}
+
+	index := s.internalRegistry.toolIndex()
+	requestTools := make([]MCPTool, 0, len(discoverableFromProviders)+len(listedTools))
+	requestTools = append(requestTools, discoverableFromProviders...)
+	requestTools = append(requestTools, listedTools...)
+
 	// Search with provider tools and listed tools included
-	results := s.internalRegistry.SearchWithAdditionalTools(ctx, query, maxResults, discoverableFromProviders, listedTools)
+	var results []SearchResult
+	ranked := false
+	if semantic != nil && strings.TrimSpace(query) != "" {
+		lexical := make(map[string]float64)
+		lexicalResults, err := index.Search(ctx, ToolQuery{Text: query, Tools: requestTools, Filter: toolFilter})
+		if err != nil {
+			return nil, NewToolErrorInternal("tool search failed: " + err.Error())
+		}
+		for _, r := range lexicalResults {
+			lexical[r.Name] = r.Score
+		}
+		// The index decides which tools are searchable: an empty query lists
+		// every tool it would return, after the filter.
+		visible, err := index.Search(ctx, ToolQuery{Tools: requestTools, Filter: toolFilter})
+		if err != nil {
+			return nil, NewToolErrorInternal("tool search failed: " + err.Error())
+		}
+		candidates := s.internalRegistry.candidates(discoverableFromProviders, listedTools)
+		listed := make(map[string]bool, len(visible))
+		for _, r := range visible {
+			listed[r.Name] = true
+		}
+		filtered := candidates[:0]
+		for _, c := range candidates {
+			if listed[c.tool.Name] {
+				filtered = append(filtered, c)
+			}
+		}
+		candidates = filtered
+		if results, err = semantic.search(ctx, query, candidates, lexical, limit); err != nil {
+			semantic.reportError(fmt.Errorf("semantic tool search failed, using lexical ranking: %w", err))
+		} else {
+			ranked = true
+		}
+	}
+	if !ranked {
+		var err error
+		results, err = index.Search(ctx, ToolQuery{Text: query, MaxResults: limit, Tools: requestTools, Filter: toolFilter})
+		if err != nil {
+			return nil, NewToolErrorInternal("tool search failed: " + err.Error())
+		}
+	}
 
 	// Delegate tool_search to remote servers that have it enabled
-	remoteResults := s.searchRemoteServers(ctx, query, maxResults)
+	remoteResults := s.filterSearchResultsByPolicy(ctx, s.searchRemoteServers(ctx, query, maxResults, filter))
 	if len(remoteResults) > 0 {
 		results = append(results, remoteResults...)
 
 		// Re-sort to merge remote results with local by score
-		sort.Slice(results, func(i, j int) bool {
-			if results[i].Score != results[j].Score {
-				return results[i].Score > results[j].Score
-			}
-			return results[i].Name < results[j].Name
-		})
+		sortSearchResults(results)
+	}
+	if usage != nil {
+		usage.boostResults(ctx, results)
+	}
+
+	total := len(results)
+	var facets ToolSearchFacets
+	if withFacets {
+		facets = countFacets(results, namespaceOf)
+	}
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	if usage != nil {
+		usage.recordSearch(ctx, results)
+	}
 
-		// Truncate to maxResults
-		if len(results) > maxResults {
-			results = results[:maxResults]
+	if withFacets {
+		if results == nil {
+			results = []SearchResult{}
 		}
+		return NewToolResponseJSON(FacetedSearchResults{Results: results, Total: total, Facets: facets}), nil
 	}
 
 	if len(results) == 0 {
@@ -266,13 +386,15 @@ func (s *Server) handleToolSearch(ctx context.Context, req *ToolRequest) (*ToolR
 }
 
 // searchRemoteServers calls tool_search on each remote server that has it enabled,
-// prefixing returned tool names with the server's namespace.
+// prefixing returned tool names with the server's namespace. The filter is
+// passed on and also applied to the results, so remotes that do not support
+// it contribute only tools whose results show they match.
 // Returns nil if no remotes have remoteSearch enabled.
-func (s *Server) searchRemoteServers(ctx context.Context, query string, maxResults int) []SearchResult {
+func (s *Server) searchRemoteServers(ctx context.Context, query string, maxResults int, filter ToolSearchFilter) []SearchResult {
 	s.mu.RLock()
 	clients := make([]*registeredClient, 0, len(s.remoteClients))
 	for _, rc := range s.remoteClients {
-		if rc.remoteSearch {
+		if rc.remoteSearch && (filter.Namespace == "" || strings.EqualFold(filter.Namespace, rc.namespace)) {
 			clients = append(clients, rc)
 		}
 	}
@@ -282,40 +404,136 @@ func (s *Server) searchRemoteServers(ctx context.Context, query string, maxResul
 		return nil
 	}
 
+	// The namespace is ours, not the remote's; it was applied above.
+	remoteFilter := filter
+	remoteFilter.Namespace = ""
+
 	var allResults []SearchResult
 
 	for _, rc := range clients {
-		searchResults, err := rc.client.ToolSearch(ctx, query, maxResults)
+		searchResults, err := rc.client.ToolSearchWithFilter(ctx, query, maxResults, remoteFilter)
 		if err != nil {
 			continue
 		}
 
+		results := make([]SearchResult, 0, len(searchResults))
 		for _, raw := range searchResults {
-			result := SearchResult{
-				Score:       0,
-				InputSchema: firstPresent(raw, "inputSchema", "input_schema"),
-				Keywords:    nil,
-			}
-			if name, ok := raw["name"].(string); ok {
-				if rc.namespace != "" {
-					result.Name = rc.namespace + rc.client.separator + name
-				} else {
-					result.Name = name
-				}
+			result := rc.searchResult(raw)
+			if !remoteFilter.matches(result.Category, result.Tags, "", result.ReadOnly) {
+				continue
 			}
-			if desc, ok := raw["description"].(string); ok {
-				result.Description = desc
-			}
-			if score, ok := raw["score"].(float64); ok {
-				result.Score = score
-			}
-			allResults = append(allResults, result)
+			results = append(results, result)
 		}
+		rc.rememberDiscovered(results)
+		allResults = append(allResults, results...)
 	}
 
 	return allResults
 }
 
+// discoveredRemoteLocked returns the remote whose namespace name carries,
+// for a tool that is in no remote's tool list and so can only have been found
+// through the remote's tool_search. Returns nil if there is none. The caller
+// must hold s.mu.
+func (s *Server) discoveredRemoteLocked(name string) *registeredClient {
+	for _, rc := range s.remoteClients {
+		if rc.namespace != "" && strings.HasPrefix(name, rc.namespace+rc.client.separator) {
+			return rc
+		}
+	}
+	return nil
+}
+
+// maxDiscoveredTools bounds how many tools found by a remote's tool_search
+// are remembered for each remote.
+const maxDiscoveredTools = 1024
+
+// searchResult converts a result from rc's tool_search into ours, with the
+// tool name namespaced.
+func (rc *registeredClient) searchResult(raw map[string]any) SearchResult {
+	result := SearchResult{
+		InputSchema:  firstPresent(raw, "inputSchema", "input_schema"),
+		OutputSchema: firstPresent(raw, "outputSchema", "output_schema"),
+	}
+	if name, ok := raw["name"].(string); ok {
+		if rc.namespace != "" {
+			result.Name = rc.namespace + rc.client.separator + name
+		} else {
+			result.Name = name
+		}
+	}
+	if desc, ok := raw["description"].(string); ok {
+		result.Description = desc
+	}
+	if score, ok := raw["score"].(float64); ok {
+		result.Score = score
+	}
+	if category, ok := raw["category"].(string); ok {
+		result.Category = category
+	}
+	if tags, ok := raw["tags"].([]any); ok {
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				result.Tags = append(result.Tags, tag)
+			}
+		}
+	}
+	result.ReadOnly, _ = raw["readOnly"].(bool)
+	if annotations, ok := raw["annotations"].(map[string]any); ok {
+		if data, err := json.Marshal(annotations); err == nil {
+			_ = json.Unmarshal(data, &result.Annotations)
+		}
+	}
+	if result.Annotations == nil && result.ReadOnly {
+		result.Annotations = &ToolAnnotations{ReadOnlyHint: true}
+	}
+	return result
+}
+
+// rememberDiscovered records tools found by rc's tool_search, so later calls
+// to them can be checked against their schemas and annotations.
+func (rc *registeredClient) rememberDiscovered(results []SearchResult) {
+	if len(results) == 0 {
+		return
+	}
+	rc.discoveredMu.Lock()
+	defer rc.discoveredMu.Unlock()
+	if rc.discovered == nil {
+		rc.discovered = newLRUMap[SearchResult](maxDiscoveredTools)
+	}
+	for _, r := range results {
+		rc.discovered.put(r.Name, r)
+	}
+}
+
+// discoveredTool describes the tool called name, which belongs to rc by
+// namespace but is not in its tool list: as remembered from an earlier
+// tool_search, or else as found by searching rc for the tool's own name.
+// Returns nil if rc does not describe it.
+func (rc *registeredClient) discoveredTool(ctx context.Context, name string) *SearchResult {
+	rc.discoveredMu.Lock()
+	if rc.discovered != nil {
+		if r, ok := rc.discovered.get(name); ok {
+			rc.discoveredMu.Unlock()
+			return &r
+		}
+	}
+	rc.discoveredMu.Unlock()
+
+	remoteName := strings.TrimPrefix(name, rc.namespace+rc.client.separator)
+	raw, err := rc.client.ToolSearch(ctx, remoteName, 0)
+	if err != nil {
+		return nil
+	}
+	for _, item := range raw {
+		if result := rc.searchResult(item); result.Name == name {
+			rc.rememberDiscovered([]SearchResult{result})
+			return &result
+		}
+	}
+	return nil
+}
+
 // handleExecuteTool handles the execute_tool meta-tool execution
 func (s *Server) handleExecuteTool(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
 	name, err := req.String("name")
@@ -333,7 +551,7 @@ func (s *Server) handleExecuteTool(ctx context.Context, req *ToolRequest) (*Tool
 	}
 
 	// Use server's CallTool which handles local, remote, and provider tools
-	response, err := s.CallTool(ctx, name, args)
+	response, err := s.CallTool(context.WithValue(ctx, executeToolKey{}, true), name, args)
 	if err == ErrUnknownTool {
 		return NewToolResponseText("Tool not found: " + name + ". Use tool_search to discover available tools."), nil
 	}
@@ -360,6 +578,22 @@ func (s *Server) CleanupExpiredSessions(maxIdleTime time.Duration) error {
 	return sm.CleanupExpiredSessions(context.Background(), maxIdleTime)
 }
 
+// SetSchemaValidation sets how tool arguments are validated against each
+// tool's InputSchema before dispatch. The mode applies to local, remote and
+// provider tools alike; failures are returned as ErrorCodeInvalidParams with
+// JSON-pointer paths to the offending values. Tools found through a remote
+// server's tool_search are validated against the schema the search reported.
+//
+// The default is SchemaValidationLenient. Earlier versions only checked that
+// required parameters were present, so calls with arguments of the wrong type
+// or outside a declared constraint are now rejected; pass SchemaValidationOff
+// to keep the old behaviour.
+func (s *Server) SetSchemaValidation(mode SchemaValidationMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemaValidation = mode
+}
+
 // SetInstructions sets the server instructions that are returned during protocol initialization.
 // Instructions provide guidance to the LLM about how to use the server's capabilities.
 func (s *Server) SetInstructions(instructions string) {
@@ -376,6 +610,7 @@ func (s *Server) SetInstructions(instructions string) {
 // Optional keywords parameter is merged with keywords set via Discoverable() for search relevance.
 // Keywords are used in show-all mode and for discoverable tool search.
 func (s *Server) RegisterTool(tool *ToolBuilder, handler ToolHandler, keywords ...string) {
+	defer s.internalRegistry.applyIndexUpdates()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -398,8 +633,12 @@ func (s *Server) registerNativeToolLocked(tool *ToolBuilder, handler ToolHandler
 		Description:  tool.Description(),
 		Schema:       tool.buildSchema(),
 		OutputSchema: tool.buildOutputSchema(),
+		Annotations:  tool.annotations,
+		Category:     tool.category,
+		Tags:         tool.tags,
 		Handler:      handler,
 		Visibility:   ToolVisibilityNative,
+		Approval:     tool.approval,
 	}
 	s.tools[tool.name] = regTool
 
@@ -413,6 +652,9 @@ func (s *Server) registerNativeToolLocked(tool *ToolBuilder, handler ToolHandler
 		Name:        tool.name,
 		Description: tool.Description(),
 		InputSchema: regTool.Schema,
+		Annotations: regTool.Annotations,
+		Category:    regTool.Category,
+		Tags:        regTool.Tags,
 		Keywords:    keywords,
 	}
 	if regTool.OutputSchema != nil {
@@ -443,8 +685,12 @@ func (s *Server) registerDiscoverableToolLocked(tool *ToolBuilder, handler ToolH
 		Description:  tool.Description(),
 		Schema:       tool.buildSchema(),
 		OutputSchema: tool.buildOutputSchema(),
+		Annotations:  tool.annotations,
+		Category:     tool.category,
+		Tags:         tool.tags,
 		Handler:      handler,
 		Visibility:   ToolVisibilityDiscoverable,
+		Approval:     tool.approval,
 	}
 	s.tools[tool.name] = regTool
 
@@ -469,6 +715,7 @@ func (s *Server) RegisterTools(tools ...*ToolRegistration) {
 		return
 	}
 
+	defer s.internalRegistry.applyIndexUpdates()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -482,8 +729,12 @@ func (s *Server) RegisterTools(tools ...*ToolRegistration) {
 				Description:  tr.Tool.Description(),
 				Schema:       tr.Tool.buildSchema(),
 				OutputSchema: tr.Tool.buildOutputSchema(),
+				Annotations:  tr.Tool.annotations,
+				Category:     tr.Tool.category,
+				Tags:         tr.Tool.tags,
 				Handler:      tr.Handler,
 				Visibility:   ToolVisibilityDiscoverable,
+				Approval:     tr.Tool.approval,
 			}
 			s.tools[tr.Tool.name] = regTool
 
@@ -496,8 +747,12 @@ func (s *Server) RegisterTools(tools ...*ToolRegistration) {
 				Description:  tr.Tool.Description(),
 				Schema:       tr.Tool.buildSchema(),
 				OutputSchema: tr.Tool.buildOutputSchema(),
+				Annotations:  tr.Tool.annotations,
+				Category:     tr.Tool.category,
+				Tags:         tr.Tool.tags,
 				Handler:      tr.Handler,
 				Visibility:   ToolVisibilityNative,
+				Approval:     tr.Tool.approval,
 			}
 			s.tools[tr.Tool.name] = regTool
 		}
@@ -512,6 +767,7 @@ func (s *Server) RegisterTools(tools ...*ToolRegistration) {
 // Returns true if the tool was found and removed, false otherwise.
 // This is safe to call concurrently.
 func (s *Server) UnregisterTool(name string) bool {
+	defer s.internalRegistry.applyIndexUpdates()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -550,6 +806,9 @@ func (s *Server) rebuildNativeToolCacheLocked() {
 				Name:        tool.Name,
 				Description: tool.Description,
 				InputSchema: tool.Schema,
+				Annotations: tool.Annotations,
+				Category:    tool.Category,
+				Tags:        tool.Tags,
 			}
 			if tool.OutputSchema != nil {
 				toolItem.OutputSchema = tool.OutputSchema
@@ -580,6 +839,9 @@ type RemoteServerOption func(*remoteServerOptions)
 
 type remoteServerOptions struct {
 	remoteSearch bool
+	transforms   ToolTransforms
+	breaker      *CircuitBreakerConfig
+	authResolver AuthResolver
 }
 
 // WithRemoteSearch enables delegating tool_search to this remote server.
@@ -590,6 +852,36 @@ func WithRemoteSearch() RemoteServerOption {
 	}
 }
 
+// WithToolTransforms renames the remote server's tools and rewrites their
+// parameters (see ToolTransform). It sets the transforms on the client, as
+// [Client.WithToolTransforms] does. Registration fails if the transforms do
+// not pass Validate.
+func WithToolTransforms(transforms ToolTransforms) RemoteServerOption {
+	return func(o *remoteServerOptions) {
+		o.transforms = transforms
+	}
+}
+
+// WithCircuitBreaker configures the remote server's circuit breaker (see
+// CircuitBreakerConfig), as [Client.WithCircuitBreaker] does. Without it a
+// remote gets a breaker with the default settings, unless its client already
+// has one.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) RemoteServerOption {
+	return func(o *remoteServerOptions) {
+		o.breaker = &cfg
+	}
+}
+
+// apply sets the options that live on the client.
+func (o *remoteServerOptions) apply(client *Client) {
+	if o.transforms != nil {
+		client.WithToolTransforms(o.transforms)
+	}
+	if o.breaker != nil {
+		client.WithCircuitBreaker(*o.breaker)
+	}
+}
+
 // RegisterRemoteServer registers a remote MCP server with native visibility.
 // Remote server tools appear in tools/list and are directly callable.
 func (s *Server) RegisterRemoteServer(client *Client, opts ...RemoteServerOption) error {
@@ -597,6 +889,13 @@ func (s *Server) RegisterRemoteServer(client *Client, opts ...RemoteServerOption
 	for _, opt := range opts {
 		opt(o)
 	}
+	if err := o.transforms.Validate(); err != nil {
+		return err
+	}
+	o.apply(client)
+	if o.authResolver != nil {
+		return s.registerPrincipalRemote(client, ToolVisibilityNative, o)
+	}
 	return s.registerRemoteServerWithVisibility(client, ToolVisibilityNative, o.remoteSearch)
 }
 
@@ -607,6 +906,13 @@ func (s *Server) RegisterRemoteServerDiscoverable(client *Client, opts ...Remote
 	for _, opt := range opts {
 		opt(o)
 	}
+	if err := o.transforms.Validate(); err != nil {
+		return err
+	}
+	o.apply(client)
+	if o.authResolver != nil {
+		return s.registerPrincipalRemote(client, ToolVisibilityDiscoverable, o)
+	}
 	return s.registerRemoteServerWithVisibility(client, ToolVisibilityDiscoverable, o.remoteSearch)
 }
 
@@ -615,11 +921,17 @@ func (s *Server) UnregisterRemoteServer(client *Client) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	regClient, ok := s.remoteClients[client.baseURL]
+	if _, ok := s.principalRemotes[client.registryKey()]; ok {
+		delete(s.principalRemotes, client.registryKey())
+		s.principalProvider.InvalidateAllCache()
+		return
+	}
+
+	regClient, ok := s.remoteClients[client.registryKey()]
 	if !ok {
 		return
 	}
-	delete(s.remoteClients, client.baseURL)
+	delete(s.remoteClients, client.registryKey())
 
 	// Remove tools belonging to this client from toolToServer and nativeToolCache
 	toRemove := make(map[string]bool)
@@ -647,9 +959,51 @@ func (s *Server) UnregisterRemoteServer(client *Client) {
 // Each entry is a (*Client, ToolVisibility) pair. Use ToolVisibilityNative for tools that should
 // appear in tools/list, or ToolVisibilityDiscoverable for tools only findable via tool_search.
 // All previously registered remote servers and their cached tools are removed first.
+//
+// Entries with Stdio instead of a Client describe local servers whose processes
+// the server manages (see NewManagedStdioClient). An entry with the same
+// namespace, command, arguments, environment and directory as one already
+// registered keeps its process; processes of entries no longer listed are
+// stopped.
 func (s *Server) ReplaceRemoteServers(servers []RemoteServerEntry) error {
+	defer s.internalRegistry.applyIndexUpdates()
+	clients := make([]*Client, len(servers))
+	stdioKeys := make([]string, len(servers))
+	for i, entry := range servers {
+		switch {
+		case entry.Client != nil:
+			clients[i] = entry.Client
+		case entry.Stdio != nil:
+			stdioKeys[i] = entry.Namespace + "\x00" + entry.Stdio.key()
+		default:
+			return fmt.Errorf("remote server entry %d has neither Client nor Stdio", i)
+		}
+		if err := entry.Transforms.Validate(); err != nil {
+			return fmt.Errorf("remote server entry %d: %w", i, err)
+		}
+	}
+
 	s.mu.Lock()
 
+	// Processes we started, to reuse or stop
+	owned := make(map[string]*Client)
+	for _, rc := range s.remoteClients {
+		if rc.stdioKey != "" {
+			owned[rc.stdioKey] = rc.client
+		}
+	}
+	for i, entry := range servers {
+		if stdioKeys[i] == "" {
+			continue
+		}
+		if client, ok := owned[stdioKeys[i]]; ok {
+			clients[i] = client
+			delete(owned, stdioKeys[i])
+		} else {
+			clients[i] = NewManagedStdioClient(*entry.Stdio, entry.Namespace)
+		}
+	}
+
 	newNativeCache := make([]MCPTool, 0, len(s.nativeToolCache))
 	for _, t := range s.nativeToolCache {
 		if _, isRemote := s.toolToServer[t.Name]; !isRemote {
@@ -671,13 +1025,36 @@ func (s *Server) ReplaceRemoteServers(servers []RemoteServerEntry) error {
 
 	s.remoteClients = make(map[string]*registeredClient)
 	s.toolToServer = make(map[string]*registeredClient)
+	s.principalRemotes = make(map[string]RemoteProviderConfig)
 
 	s.mu.Unlock()
 
-	for _, entry := range servers {
-		if err := s.registerRemoteServerWithVisibility(entry.Client, entry.Visibility, entry.RemoteSearch); err != nil {
+	for _, client := range owned {
+		_ = client.Close()
+	}
+
+	for i, entry := range servers {
+		o := remoteServerOptions{
+			remoteSearch: entry.RemoteSearch,
+			transforms:   entry.Transforms,
+			breaker:      entry.CircuitBreaker,
+			authResolver: entry.AuthResolver,
+		}
+		o.apply(clients[i])
+		if o.authResolver != nil {
+			if err := s.registerPrincipalRemote(clients[i], entry.Visibility, &o); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.registerRemoteServerWithVisibility(clients[i], entry.Visibility, entry.RemoteSearch); err != nil {
 			return err
 		}
+		if stdioKeys[i] != "" {
+			s.mu.Lock()
+			s.remoteClients[clients[i].registryKey()].stdioKey = stdioKeys[i]
+			s.mu.Unlock()
+		}
 	}
 
 	s.mu.Lock()
@@ -688,22 +1065,26 @@ func (s *Server) ReplaceRemoteServers(servers []RemoteServerEntry) error {
 }
 
 // RemoteServerEntry pairs a client with the visibility to use when registering.
+// Set either Client, for a server you connect to yourself, or Stdio, for a
+// local server binary whose process the server starts and stops.
 type RemoteServerEntry struct {
 	Client       *Client
 	Visibility   ToolVisibility
 	RemoteSearch bool // Delegate tool_search to this remote server
+
+	Stdio     *StdioServerConfig // Local server to run when Client is nil
+	Namespace string             // Namespace for a Stdio server's tools
+
+	Transforms     ToolTransforms        // Renames and parameter rewrites, as WithToolTransforms
+	CircuitBreaker *CircuitBreakerConfig // Circuit breaker settings, as WithCircuitBreaker
+	AuthResolver   AuthResolver          // Per-request auth and per-principal tool lists, as WithAuthResolver
 }
 
 // registerRemoteServerWithVisibility is the internal implementation for registering remote servers.
 func (s *Server) registerRemoteServerWithVisibility(client *Client, visibility ToolVisibility, remoteSearch bool) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if visibility == ToolVisibilityDiscoverable || remoteSearch {
-		s.hasDiscoverableTools = true
-	}
-
+	defer s.internalRegistry.applyIndexUpdates()
 	namespace := strings.TrimSuffix(client.Namespace(), client.separator)
+	client.defaultCircuitBreaker()
 
 	regClient := &registeredClient{
 		client:       client,
@@ -711,31 +1092,57 @@ func (s *Server) registerRemoteServerWithVisibility(client *Client, visibility T
 		visibility:   visibility,
 		remoteSearch: remoteSearch,
 	}
-	s.remoteClients[client.baseURL] = regClient
 
-	// Propagate upstream tool changes downstream: when this remote's tool set
-	// changes, refresh our merged cache and notify our own subscribers. This hook
-	// fires only when the caller has enabled notifications on the client (via
-	// [Client.EnableNotifications]); otherwise no reader is active and the hook
-	// never runs. Resources/prompts aren't federated, so only tools propagate.
+	// Propagate upstream changes downstream: when this remote's tools,
+	// resources or prompts change, refresh our merged view and notify our own
+	// subscribers. This hook fires only when the caller has enabled
+	// notifications on the client (via [Client.EnableNotifications]); otherwise
+	// no reader is active and the hook never runs.
 	client.setPropagationHook(func(method string, params any) {
-		if method != NotificationToolsChanged {
-			return
+		switch method {
+		case NotificationToolsChanged:
+			go func() {
+				_ = s.RefreshTools(context.Background())
+				s.NotifyToolsChanged()
+			}()
+		case NotificationResourcesChanged:
+			go func() {
+				s.refreshRemoteCatalog(context.Background(), regClient)
+				s.NotifyResourcesChanged()
+			}()
+		case NotificationPromptsChanged:
+			go func() {
+				s.refreshRemoteCatalog(context.Background(), regClient)
+				s.NotifyPromptsChanged()
+			}()
 		}
-		go func() {
-			_ = s.RefreshTools(context.Background())
-			s.NotifyToolsChanged()
-		}()
 	})
 
-	// Fetch tools from the new server
+	// Fetch the server's resources, prompts and tools before taking the lock,
+	// so a slow remote does not hold up the rest of the server.
 	ctx := context.Background()
+	catalog := fetchRemoteCatalog(ctx, regClient)
 	tools, err := client.ListTools(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if visibility == ToolVisibilityDiscoverable || remoteSearch {
+		s.hasDiscoverableTools = true
+	}
+	s.remoteClients[client.registryKey()] = regClient
+
+	// A change notification may already have refreshed the catalog.
+	if regClient.catalog == nil {
+		regClient.catalog = catalog
+	}
+
 	if err != nil {
 		// Server registration succeeded, but we couldn't fetch tools
 		// This is not a fatal error - tools can be fetched later via RefreshTools
 		return nil
 	}
+	regClient.tools = tools
 
 	// Add tools based on visibility
 	for _, tool := range tools {
@@ -782,6 +1189,7 @@ func (s *Server) registerRemoteServerWithVisibility(client *Client, visibility T
 // to avoid blocking other operations, then atomically swaps in the new data.
 // The context can be used to cancel the operation if needed.
 func (s *Server) RefreshTools(ctx context.Context) error {
+	defer s.internalRegistry.applyIndexUpdates()
 	// Check for cancellation early
 	if err := ctx.Err(); err != nil {
 		return err
@@ -796,8 +1204,10 @@ func (s *Server) RefreshTools(ctx context.Context) error {
 		}
 	}
 	remoteClients := make([]*registeredClient, 0, len(s.remoteClients))
+	lastTools := make(map[*registeredClient][]MCPTool, len(s.remoteClients))
 	for _, rc := range s.remoteClients {
 		remoteClients = append(remoteClients, rc)
+		lastTools[rc] = rc.tools
 	}
 	// Capture the names of currently-registered discoverable remote tools so we
 	// can remove stale ones from the internal registry after refreshing.
@@ -814,6 +1224,10 @@ func (s *Server) RefreshTools(ctx context.Context) error {
 	newToolToServer := make(map[string]*registeredClient)
 	// Fresh discoverable remote tools to (re)register in the internal registry.
 	freshDiscoverableRemoteTools := make([]MCPTool, 0)
+	// Fresh resource and prompt catalogs of the remotes.
+	freshCatalogs := make(map[*registeredClient]*remoteCatalog)
+	// Fresh tool lists of the remotes that answered.
+	freshTools := make(map[*registeredClient][]MCPTool)
 
 	// Add local native tools to new cache
 	for _, tool := range localNativeTools {
@@ -821,6 +1235,9 @@ func (s *Server) RefreshTools(ctx context.Context) error {
 			Name:        tool.Name,
 			Description: tool.Description,
 			InputSchema: tool.Schema,
+			Annotations: tool.Annotations,
+			Category:    tool.Category,
+			Tags:        tool.Tags,
 		}
 		if tool.OutputSchema != nil {
 			toolItem.OutputSchema = tool.OutputSchema
@@ -835,13 +1252,21 @@ func (s *Server) RefreshTools(ctx context.Context) error {
 			return err
 		}
 		// Force a fresh fetch from the remote so RefreshTools genuinely picks up
-		// tool changes (the client otherwise serves its cached list).
-		if err := regClient.client.RefreshToolCache(ctx); err != nil {
-			continue // Skip failed remote servers
+		// tool changes (the client otherwise serves its cached list). A remote
+		// that cannot be reached keeps its last known tools and catalog.
+		var tools []MCPTool
+		err := regClient.client.RefreshToolCache(ctx)
+		if err == nil {
+			tools, err = regClient.client.ListTools(ctx)
 		}
-		tools, err := regClient.client.ListTools(ctx)
 		if err != nil {
-			continue // Skip failed remote servers
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			tools = lastTools[regClient]
+		} else {
+			freshTools[regClient] = tools
+			freshCatalogs[regClient] = fetchRemoteCatalog(ctx, regClient)
 		}
 
 		for _, tool := range tools {
@@ -875,6 +1300,12 @@ func (s *Server) RefreshTools(ctx context.Context) error {
 	s.mu.Lock()
 	s.nativeToolCache = newNativeToolCache
 	s.toolToServer = newToolToServer
+	for regClient, catalog := range freshCatalogs {
+		regClient.catalog = catalog
+	}
+	for regClient, tools := range freshTools {
+		regClient.tools = tools
+	}
 	s.mu.Unlock()
 
 	// Phase 4: Refresh discoverable remote tools in the internal registry.
@@ -1018,9 +1449,11 @@ func (s *Server) HandleRequest(w http.ResponseWriter, r *http.Request) {
 
 			// Get show-all flag from session and apply to context
 			showAll, _ := sm.GetShowAll(r.Context(), sessionID)
+			ctx := withSessionID(r.Context(), sessionID)
 			if showAll {
-				r = r.WithContext(WithShowAllTools(r.Context()))
+				ctx = WithShowAllTools(ctx)
 			}
+			r = r.WithContext(ctx)
 		} else {
 			// No session management - check header/query on each request
 			if GetShowAllFromRequest(r) {
@@ -1179,6 +1612,7 @@ func (s *Server) handleToolsList(w http.ResponseWriter, r *http.Request, req *MC
 // Show-all mode: returns ALL tools regardless of visibility
 // The context is used to retrieve request-scoped tool providers.
 func (s *Server) ListToolsWithContext(ctx context.Context) []MCPTool {
+	ctx = s.withPrincipalRemotes(ctx)
 	showAll := GetShowAllTools(ctx)
 	hasDiscoverableProviders := hasDiscoverableToolsFromProviders(ctx)
 
@@ -1209,6 +1643,9 @@ func (s *Server) ListToolsWithContext(ctx context.Context) []MCPTool {
 					Name:        tool.Name,
 					Description: tool.Description,
 					InputSchema: tool.Schema,
+					Annotations: tool.Annotations,
+					Category:    tool.Category,
+					Tags:        tool.Tags,
 					Visibility:  ToolVisibilityDiscoverable,
 				}
 				if tool.OutputSchema != nil {
@@ -1240,6 +1677,19 @@ func (s *Server) ListToolsWithContext(ctx context.Context) []MCPTool {
 		}
 	}
 
+	// Likewise resource_search/read_resource and prompt_search/get_prompt for
+	// discoverable resources and prompts
+	if !showAll {
+		for _, tool := range s.getCatalogDiscoveryTools(ctx) {
+			if !seen[tool.Name] {
+				allTools = append(allTools, tool)
+				seen[tool.Name] = true
+			}
+		}
+	}
+
+	allTools = s.filterToolsByPolicy(ctx, allTools)
+
 	// Sort combined results
 	sort.Slice(allTools, func(i, j int) bool {
 		return allTools[i].Name < allTools[j].Name
@@ -1250,7 +1700,65 @@ func (s *Server) ListToolsWithContext(ctx context.Context) []MCPTool {
 
 // CallTool executes a tool directly with namespace support (direct API)
 // It checks discovery tools first, then local tools, then remote tools, then providers from context.
+// Returns ErrPolicyDenied if the server's policy forbids the call (see SetPolicy).
+// The call is recorded in the audit log, if there is one (see SetAuditLog), and
+// fails with ErrAuditFailed if that log fails closed and cannot record it; the
+// tool is not run if its start cannot be recorded.
 func (s *Server) CallTool(ctx context.Context, name string, args map[string]any) (*ToolResponse, error) {
+	ctx = s.withPrincipalRemotes(ctx)
+	audit := s.auditLog()
+	if audit == nil || s.isMetaTool(name) {
+		return s.callToolChecked(ctx, name, args)
+	}
+	start := time.Now()
+	remote := s.remoteOf(name)
+	if audit.FailClosed {
+		if err := audit.recordToolStart(ctx, name, remote, args, start); err != nil {
+			return nil, err
+		}
+	}
+	response, err := s.callToolChecked(ctx, name, args)
+	if auditErr := audit.recordToolCall(ctx, name, remote, args, start, response, err); auditErr != nil {
+		return nil, auditErr
+	}
+	return response, err
+}
+
+// callToolChecked is CallTool without auditing: it enforces the policy and
+// records usage analytics.
+func (s *Server) callToolChecked(ctx context.Context, name string, args map[string]any) (*ToolResponse, error) {
+	if err := s.checkToolPolicy(ctx, name); err != nil {
+		return nil, err
+	}
+
+	usage := s.usageTracker()
+	if usage == nil || isMetaToolName(name) {
+		return s.callTool(ctx, name, args)
+	}
+
+	// Calls the tool makes itself are not made through execute_tool
+	viaExecuteTool, _ := ctx.Value(executeToolKey{}).(bool)
+	if viaExecuteTool {
+		ctx = context.WithValue(ctx, executeToolKey{}, false)
+	}
+	response, err := s.callTool(ctx, name, args)
+	if err != ErrUnknownTool {
+		usage.recordCall(ctx, name, viaExecuteTool, response, err)
+	}
+	return response, err
+}
+
+// isMetaToolName reports whether name is one of the discovery meta-tools.
+func isMetaToolName(name string) bool {
+	switch name {
+	case ToolSearchName, ExecuteToolName, ResourceSearchName, ReadResourceName, PromptSearchName, GetPromptName:
+		return true
+	}
+	return false
+}
+
+// callTool is CallTool without usage analytics.
+func (s *Server) callTool(ctx context.Context, name string, args map[string]any) (*ToolResponse, error) {
 	// Handle discovery tools (tool_search, execute_tool) dynamically
 	if name == ToolSearchName {
 		return s.handleToolSearch(ctx, NewToolRequest(args))
@@ -1258,53 +1766,118 @@ func (s *Server) CallTool(ctx context.Context, name string, args map[string]any)
 	if name == ExecuteToolName {
 		return s.handleExecuteTool(ctx, NewToolRequest(args))
 	}
+	// The resource and prompt meta-tools give way to local tools of the same
+	// name, as they do in tools/list
+	s.mu.RLock()
+	_, isLocal := s.tools[name]
+	s.mu.RUnlock()
+	switch {
+	case isLocal:
+	case name == ResourceSearchName:
+		return s.handleResourceSearch(ctx, NewToolRequest(args))
+	case name == ReadResourceName:
+		return s.handleReadResource(ctx, NewToolRequest(args))
+	case name == PromptSearchName:
+		return s.handlePromptSearch(ctx, NewToolRequest(args))
+	case name == GetPromptName:
+		return s.handleGetPrompt(ctx, NewToolRequest(args))
+	}
 
 	s.mu.RLock()
+	validation := s.schemaValidation
+	output := s.outputValidation
 
 	// Try local tools first
 	if tool, exists := s.tools[name]; exists {
 		handler := tool.Handler
 		schema := tool.Schema
+		outputSchema := tool.OutputSchema
+		approval := tool.Approval
 		s.mu.RUnlock()
 
-		// Validate required parameters
-		if err := validateRequiredParameters(schema, args); err != nil {
+		if err := validateToolArguments(validation, s.schemas, name, schema, args); err != nil {
 			return nil, err
 		}
+		if approval != nil {
+			if rejected := s.approve(ctx, name, approval, args); rejected != nil {
+				return rejected, nil
+			}
+		}
 
-		toolReq := &ToolRequest{args: args}
-		return handler(ctx, toolReq)
+		toolReq := &ToolRequest{args: args, defaults: schemaDefaults(schema)}
+		response, err := handler(ctx, toolReq)
+		return s.finishToolResponse(output, name, outputSchema, response, err)
 	}
 
 	// Fast lookup for remote tools (registered via RegisterRemoteServer)
 	if regClient, exists := s.toolToServer[name]; exists {
 		client := regClient.client
 		namespace := regClient.namespace
+		var schema, outputSchema any
+		if tool := s.remoteToolLocked(name); tool != nil {
+			schema, outputSchema = tool.InputSchema, tool.OutputSchema
+		}
 		s.mu.RUnlock()
+
+		if err := validateToolArguments(validation, s.schemas, name, schema, args); err != nil {
+			return nil, err
+		}
 		// Extract original tool name (remove namespace if present)
 		toolName := name
 		if namespace != "" {
 			toolName = strings.TrimPrefix(name, namespace+regClient.client.separator)
 		}
-		return client.CallTool(ctx, toolName, args)
+		response, err := client.CallTool(ctx, toolName, args)
+		return s.finishToolResponse(output, name, outputSchema, response, err)
 	}
 
 	// Fallback: match by namespace prefix to find the remote server,
 	// then call via execute_tool on that server (for tools discovered via remote tool_search)
-	for _, rc := range s.remoteClients {
-		if rc.namespace != "" && strings.HasPrefix(name, rc.namespace+rc.client.separator) {
-			client := rc.client
-			separator := rc.client.separator
-			s.mu.RUnlock()
-			toolName := strings.TrimPrefix(name, rc.namespace+separator)
-			return client.ExecuteDiscoveredTool(ctx, toolName, args)
+	if rc := s.discoveredRemoteLocked(name); rc != nil {
+		s.mu.RUnlock()
+
+		// Validate against the schemas the remote's tool_search reported
+		var outputSchema any
+		if tool := rc.discoveredTool(ctx, name); tool != nil {
+			if err := validateToolArguments(validation, s.schemas, name, tool.InputSchema, args); err != nil {
+				return nil, err
+			}
+			outputSchema = tool.OutputSchema
 		}
+		toolName := strings.TrimPrefix(name, rc.namespace+rc.client.separator)
+		response, err := rc.client.ExecuteDiscoveredTool(ctx, toolName, args)
+		return s.finishToolResponse(output, name, outputSchema, response, err)
 	}
 
 	s.mu.RUnlock()
 
+	// Validate against the schemas the provider advertises for this tool, if it
+	// lists one. Tools a provider executes without listing are not validated.
+	var outputSchema any
+	if tool := providerTool(ctx, name); tool != nil {
+		if err := validateToolArguments(validation, s.schemas, name, tool.InputSchema, args); err != nil {
+			return nil, err
+		}
+		outputSchema = tool.OutputSchema
+	}
+
 	// Try native providers from context (per-request dynamic tools)
-	return callToolFromProviders(ctx, name, args)
+	response, err := callToolFromProviders(ctx, name, args)
+	return s.finishToolResponse(output, name, outputSchema, response, err)
+}
+
+// remoteToolLocked returns the cached descriptor for a remote tool registered
+// via RegisterRemoteServer, looking in the native cache and then the discovery
+// registry. Returns nil if the tool is not known. Must be called with s.mu held.
+func (s *Server) remoteToolLocked(name string) *MCPTool {
+	idx := sort.Search(len(s.nativeToolCache), func(i int) bool {
+		return s.nativeToolCache[i].Name >= name
+	})
+	if idx < len(s.nativeToolCache) && s.nativeToolCache[idx].Name == name {
+		tool := s.nativeToolCache[idx]
+		return &tool
+	}
+	return s.internalRegistry.lookupTool(name)
 }
 
 func (s *Server) handleToolsCall(w http.ResponseWriter, r *http.Request, req *MCPRequest) {
@@ -1328,7 +1901,7 @@ func (s *Server) handleToolsCall(w http.ResponseWriter, r *http.Request, req *MC
 	s.sendMCPResponse(w, req.ID, ToolResult{
 		Content:           response.Content,
 		StructuredContent: response.StructuredContent,
-		IsError:           false,
+		IsError:           response.IsError,
 	})
 }
 
@@ -1,6 +1,9 @@
 package mcp
 
-import "sync"
+import (
+	"slices"
+	"sync"
+)
 
 // MCP notification method names for list-change signalling.
 const (
@@ -31,6 +34,8 @@ type notificationHub struct {
 	mu          sync.RWMutex
 	nextID      uint64
 	subscribers map[uint64]*notificationSubscriber
+	batches     int      // BatchChanges calls in progress
+	held        []string // Methods held back until the last batch ends
 }
 
 func newNotificationHub() *notificationHub {
@@ -70,6 +75,20 @@ func (h *notificationHub) count() int {
 	return len(h.subscribers)
 }
 
+// hold records method to be sent when the current batch ends, reporting
+// false if no batch is in progress.
+func (h *notificationHub) hold(method string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.batches == 0 {
+		return false
+	}
+	if !slices.Contains(h.held, method) {
+		h.held = append(h.held, method)
+	}
+	return true
+}
+
 // emitNotification broadcasts a notification to every connected client. It is a
 // no-op when there are no subscribers, so it is cheap to call during server
 // setup (before serving) and on every register/unregister.
@@ -77,9 +96,37 @@ func (s *Server) emitNotification(method string, params any) {
 	if s.notifications.count() == 0 {
 		return
 	}
+	if s.notifications.hold(method) {
+		return
+	}
 	s.notifications.broadcast(method, params)
 }
 
+// BatchChanges runs fn and holds back the list-changed notifications sent
+// meanwhile, by fn's registrations or any other, then sends each kind once.
+// Nothing is sent for a kind that did not change. Use it to apply several
+// registrations as a single change; calls may be nested.
+func (s *Server) BatchChanges(fn func()) {
+	h := s.notifications
+	h.mu.Lock()
+	h.batches++
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		h.batches--
+		var held []string
+		if h.batches == 0 {
+			held, h.held = h.held, nil
+		}
+		h.mu.Unlock()
+		for _, method := range held {
+			h.broadcast(method, nil)
+		}
+	}()
+	fn()
+}
+
 // NotifyToolsChanged emits notifications/tools/listChanged to every connected
 // client, signalling that its cached tool list is stale and should be re-fetched.
 //
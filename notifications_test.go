@@ -373,6 +373,43 @@ func TestEmitNoOpWithoutSubscribers(t *testing.T) {
 	}
 }
 
+// recordingSink is a notificationSink that records the methods sent to it.
+type recordingSink struct {
+	methods []string
+}
+
+func (r *recordingSink) send(method string, params any) {
+	r.methods = append(r.methods, method)
+}
+
+func TestBatchChangesSendsEachKindOnce(t *testing.T) {
+	s := NewServer("ns", "1")
+	sink := &recordingSink{}
+	s.notifications.subscribe(sink)
+
+	s.BatchChanges(func() {
+		s.RegisterTool(NewTool("a", "A"), nil)
+		s.BatchChanges(func() {
+			s.RegisterTool(NewTool("b", "B"), nil)
+			s.UnregisterTool("a")
+		})
+		s.RegisterPrompt(NewPrompt("p", "P"), nil)
+		if len(sink.methods) != 0 {
+			t.Errorf("notifications sent during the batch: %v", sink.methods)
+		}
+	})
+	want := []string{NotificationToolsChanged, NotificationPromptsChanged}
+	if fmt.Sprint(sink.methods) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", sink.methods, want)
+	}
+
+	sink.methods = nil
+	s.BatchChanges(func() {})
+	if len(sink.methods) != 0 {
+		t.Errorf("an empty batch sent %v", sink.methods)
+	}
+}
+
 // TestRegisterToolDoesNotBlockOnStuckStdioClient is the destruction test for
 // the stdio sink: a dead client (no one reading the server->client pipe) must
 // not block RegisterTool, because emission runs under s.mu and must never stall
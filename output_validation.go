@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"encoding/json"
+)
+
+// OutputValidationMode controls what the server does when a tool's
+// structuredContent does not conform to the tool's OutputSchema.
+type OutputValidationMode int
+
+const (
+	// OutputValidationOff skips output validation. This is the default.
+	OutputValidationOff OutputValidationMode = iota
+
+	// OutputValidationWarn validates structuredContent and reports violations
+	// to the warning handler (see OnOutputValidationWarning), but still returns
+	// the response to the client unchanged.
+	OutputValidationWarn
+
+	// OutputValidationError validates structuredContent and fails the call
+	// with ErrorCodeInternalError when it does not conform.
+	OutputValidationError
+)
+
+// String returns a human-readable name for the validation mode.
+func (m OutputValidationMode) String() string {
+	switch m {
+	case OutputValidationOff:
+		return "off"
+	case OutputValidationWarn:
+		return "warn"
+	case OutputValidationError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// OutputValidationWarningFunc receives the violations found in a tool's
+// structuredContent when the server runs in OutputValidationWarn mode.
+type OutputValidationWarningFunc func(toolName string, errs []SchemaError)
+
+type outputValidationConfig struct {
+	mode      OutputValidationMode
+	onWarning OutputValidationWarningFunc
+}
+
+// SetOutputValidation sets how the structuredContent returned by tools is
+// checked against each tool's OutputSchema (declared with Output(...)).
+// Tools without an OutputSchema, and responses without structuredContent, are
+// never checked. Applies to local, remote and provider tools.
+func (s *Server) SetOutputValidation(mode OutputValidationMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputValidation.mode = mode
+}
+
+// OnOutputValidationWarning registers the handler that receives violations in
+// OutputValidationWarn mode. When no handler is set, violations are discarded.
+func (s *Server) OnOutputValidationWarning(fn OutputValidationWarningFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputValidation.onWarning = fn
+}
+
+// finishToolResponse post-processes a tool's response before it is returned:
+// the structuredContent is validated against outputSchema according to cfg,
+// and a text block carrying the serialized JSON is added when the handler
+// returned structured content without one, as the MCP specification
+// recommends for clients that do not read structuredContent. Error results
+// (IsError) are not validated.
+func (s *Server) finishToolResponse(cfg outputValidationConfig, name string, outputSchema any, response *ToolResponse, err error) (*ToolResponse, error) {
+	if err != nil || response == nil || response.StructuredContent == nil {
+		return response, err
+	}
+
+	if cfg.mode != OutputValidationOff && outputSchema != nil && !response.IsError {
+		if errs := ValidateSchema(outputSchema, response.StructuredContent); len(errs) > 0 {
+			if cfg.mode == OutputValidationError {
+				return nil, NewToolError(ErrorCodeInternalError, "structured content does not match output schema: "+errs[0].Error(), map[string]any{
+					"errors": errs,
+				})
+			}
+			if cfg.onWarning != nil {
+				cfg.onWarning(name, errs)
+			}
+		}
+	}
+
+	if !hasTextContent(response.Content) {
+		if data, err := json.Marshal(response.StructuredContent); err == nil {
+			filled := *response
+			filled.Content = append(append([]ToolContent{}, response.Content...), ToolContent{Type: "text", Text: string(data)})
+			return &filled, nil
+		}
+	}
+	return response, nil
+}
+
+func hasTextContent(content []ToolContent) bool {
+	for _, c := range content {
+		if c.Type == "text" {
+			return true
+		}
+	}
+	return false
+}
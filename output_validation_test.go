@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"testing"
+)
+
+func newOutputTestServer(result any) *Server {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("report", "Report",
+		Output(
+			String("status", "Status", Required()),
+			Integer("count", "Count"),
+		),
+	), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseStructured(result), nil
+	})
+	return server
+}
+
+func TestStructuredContentFillsTextBlock(t *testing.T) {
+	server := newOutputTestServer(map[string]any{"status": "ok", "count": 2})
+
+	resp, err := server.CallTool(context.Background(), "report", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Content) != 1 || resp.Content[0].Type != "text" {
+		t.Fatalf("expected a single text block, got %+v", resp.Content)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &decoded); err != nil {
+		t.Fatalf("text block is not JSON: %v", err)
+	}
+	if decoded["status"] != "ok" {
+		t.Errorf("unexpected text content: %s", resp.Content[0].Text)
+	}
+}
+
+func TestStructuredContentKeepsExistingText(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("t", "t"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseMulti(NewToolResponseText("summary"), NewToolResponseStructured(map[string]any{"a": 1})), nil
+	})
+
+	resp, err := server.CallTool(context.Background(), "t", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Content) != 1 || resp.Content[0].Text != "summary" {
+		t.Fatalf("existing text block should be preserved unchanged, got %+v", resp.Content)
+	}
+}
+
+func TestOutputValidationModes(t *testing.T) {
+	bad := map[string]any{"count": "many"}
+	ctx := context.Background()
+
+	server := newOutputTestServer(bad)
+	if _, err := server.CallTool(ctx, "report", nil); err != nil {
+		t.Fatalf("validation is off by default, got %v", err)
+	}
+
+	server.SetOutputValidation(OutputValidationError)
+	_, err := server.CallTool(ctx, "report", nil)
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInternalError {
+		t.Fatalf("expected internal error, got %v", err)
+	}
+
+	// Without a handler, warn mode reports nothing and writes nothing to the log.
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	server.SetOutputValidation(OutputValidationWarn)
+	if _, err := server.CallTool(ctx, "report", nil); err != nil || logged.Len() > 0 {
+		t.Fatalf("warn mode without a handler: %v, logged %q", err, logged.String())
+	}
+
+	var warned []SchemaError
+	server.OnOutputValidationWarning(func(name string, errs []SchemaError) {
+		if name != "report" {
+			t.Errorf("unexpected tool name %q", name)
+		}
+		warned = errs
+	})
+	resp, err := server.CallTool(ctx, "report", nil)
+	if err != nil || resp == nil {
+		t.Fatalf("warn mode should return the response, got %v", err)
+	}
+	paths := map[string]bool{}
+	for _, e := range warned {
+		paths[e.Path] = true
+	}
+	if !paths["/status"] || !paths["/count"] {
+		t.Errorf("expected warnings for /status and /count, got %v", warned)
+	}
+}
+
+func TestOutputValidationPassesConformingContent(t *testing.T) {
+	server := newOutputTestServer(map[string]any{"status": "ok", "count": 3})
+	server.SetOutputValidation(OutputValidationError)
+	if _, err := server.CallTool(context.Background(), "report", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package mcp
+
+// Common values for the Format option. Formats are annotations: clients and
+// LLMs use them as hints, and the server only asserts them in
+// SchemaValidationStrict mode.
+const (
+	FormatDateTime = "date-time"
+	FormatDate     = "date"
+	FormatTime     = "time"
+	FormatEmail    = "email"
+	FormatURI      = "uri"
+	FormatUUID     = "uuid"
+	FormatHostname = "hostname"
+	FormatIPv4     = "ipv4"
+	FormatIPv6     = "ipv6"
+)
+
+// paramConstraints holds the JSON Schema keywords set through parameter
+// options. Zero values mean "not set".
+type paramConstraints struct {
+	enum             []any
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+	minLength        *int
+	maxLength        *int
+	pattern          string
+	format           string
+	minItems         *int
+	maxItems         *int
+	uniqueItems      bool
+	defaultValue     any
+	hasDefault       bool
+}
+
+// constraintOption is an Option that sets one or more schema constraints.
+type constraintOption func(c *paramConstraints)
+
+func (o constraintOption) applyToParam(param *parameterBase) {
+	o(&param.constraints)
+}
+
+// Enum restricts the parameter to the given values. On array parameters the
+// restriction applies to each element.
+func Enum(values ...any) Option {
+	return constraintOption(func(c *paramConstraints) {
+		c.enum = make([]any, len(values))
+		for i, v := range values {
+			c.enum[i] = toJSONValue(v)
+		}
+	})
+}
+
+// Minimum sets the inclusive lower bound of a number or integer parameter.
+// On array parameters the bound applies to each element.
+func Minimum(v float64) Option {
+	return constraintOption(func(c *paramConstraints) { c.minimum = &v })
+}
+
+// Maximum sets the inclusive upper bound of a number or integer parameter.
+// On array parameters the bound applies to each element.
+func Maximum(v float64) Option {
+	return constraintOption(func(c *paramConstraints) { c.maximum = &v })
+}
+
+// ExclusiveMinimum sets the exclusive lower bound of a number or integer
+// parameter. On array parameters the bound applies to each element.
+func ExclusiveMinimum(v float64) Option {
+	return constraintOption(func(c *paramConstraints) { c.exclusiveMinimum = &v })
+}
+
+// ExclusiveMaximum sets the exclusive upper bound of a number or integer
+// parameter. On array parameters the bound applies to each element.
+func ExclusiveMaximum(v float64) Option {
+	return constraintOption(func(c *paramConstraints) { c.exclusiveMaximum = &v })
+}
+
+// MultipleOf requires a number or integer parameter to be a multiple of v.
+// On array parameters the restriction applies to each element.
+func MultipleOf(v float64) Option {
+	return constraintOption(func(c *paramConstraints) { c.multipleOf = &v })
+}
+
+// MinLength sets the minimum length of a string parameter, in characters.
+// On array parameters the restriction applies to each element.
+func MinLength(n int) Option {
+	return constraintOption(func(c *paramConstraints) { c.minLength = &n })
+}
+
+// MaxLength sets the maximum length of a string parameter, in characters.
+// On array parameters the restriction applies to each element.
+func MaxLength(n int) Option {
+	return constraintOption(func(c *paramConstraints) { c.maxLength = &n })
+}
+
+// Pattern requires a string parameter to match the regular expression re.
+// On array parameters the restriction applies to each element.
+func Pattern(re string) Option {
+	return constraintOption(func(c *paramConstraints) { c.pattern = re })
+}
+
+// Format annotates a string parameter with a JSON Schema format such as
+// FormatDateTime or FormatEmail. On array parameters the format applies to
+// each element.
+func Format(format string) Option {
+	return constraintOption(func(c *paramConstraints) { c.format = format })
+}
+
+// MinItems sets the minimum number of elements of an array parameter.
+func MinItems(n int) Option {
+	return constraintOption(func(c *paramConstraints) { c.minItems = &n })
+}
+
+// MaxItems sets the maximum number of elements of an array parameter.
+func MaxItems(n int) Option {
+	return constraintOption(func(c *paramConstraints) { c.maxItems = &n })
+}
+
+// UniqueItems requires the elements of an array parameter to be distinct.
+func UniqueItems() Option {
+	return constraintOption(func(c *paramConstraints) { c.uniqueItems = true })
+}
+
+// Default sets the value used when the caller omits the parameter. It is
+// advertised in the schema and returned by the ToolRequest accessors for
+// missing arguments.
+func Default(v any) Option {
+	return constraintOption(func(c *paramConstraints) {
+		c.defaultValue = toJSONValue(v)
+		c.hasDefault = true
+	})
+}
+
+// applyValueConstraints adds the keywords that constrain a single value
+// (strings, numbers, enums) to schema.
+func (c *paramConstraints) applyValueConstraints(schema map[string]any) {
+	if c.enum != nil {
+		schema["enum"] = c.enum
+	}
+	if c.minimum != nil {
+		schema["minimum"] = *c.minimum
+	}
+	if c.maximum != nil {
+		schema["maximum"] = *c.maximum
+	}
+	if c.exclusiveMinimum != nil {
+		schema["exclusiveMinimum"] = *c.exclusiveMinimum
+	}
+	if c.exclusiveMaximum != nil {
+		schema["exclusiveMaximum"] = *c.exclusiveMaximum
+	}
+	if c.multipleOf != nil {
+		schema["multipleOf"] = *c.multipleOf
+	}
+	if c.minLength != nil {
+		schema["minLength"] = *c.minLength
+	}
+	if c.maxLength != nil {
+		schema["maxLength"] = *c.maxLength
+	}
+	if c.pattern != "" {
+		schema["pattern"] = c.pattern
+	}
+	if c.format != "" {
+		schema["format"] = c.format
+	}
+}
+
+// applyArrayConstraints adds the keywords that constrain an array as a whole
+// to schema.
+func (c *paramConstraints) applyArrayConstraints(schema map[string]any) {
+	if c.minItems != nil {
+		schema["minItems"] = *c.minItems
+	}
+	if c.maxItems != nil {
+		schema["maxItems"] = *c.maxItems
+	}
+	if c.uniqueItems {
+		schema["uniqueItems"] = true
+	}
+}
+
+// schemaDefaults returns the "default" values declared on the top-level
+// properties of an input schema, keyed by property name.
+func schemaDefaults(schema map[string]any) map[string]any {
+	props, _ := schema["properties"].(map[string]any)
+	var defaults map[string]any
+	for name, prop := range props {
+		propSchema, ok := prop.(map[string]any)
+		if !ok {
+			continue
+		}
+		if def, ok := propSchema["default"]; ok {
+			if defaults == nil {
+				defaults = make(map[string]any)
+			}
+			defaults[name] = def
+		}
+	}
+	return defaults
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParamConstraintsInSchema(t *testing.T) {
+	tool := NewTool("t", "test",
+		String("mode", "mode", Enum("fast", "slow"), Default("fast")),
+		String("code", "code", Pattern("^[A-Z]+$"), MinLength(2), MaxLength(5)),
+		String("when", "when", Format(FormatDateTime)),
+		Integer("level", "level", Minimum(1), Maximum(10), Default(3)),
+		Number("ratio", "ratio", ExclusiveMinimum(0), ExclusiveMaximum(1), MultipleOf(0.25)),
+		StringArray("tags", "tags", Enum("a", "b"), MinItems(1), MaxItems(3), UniqueItems()),
+		Object("opts", "opts", String("k", "k"), Required(), Default(map[string]any{"k": "v"})),
+	)
+	props := tool.BuildSchema()["properties"].(map[string]any)
+
+	mode := props["mode"].(map[string]any)
+	if !reflect.DeepEqual(mode["enum"], []any{"fast", "slow"}) || mode["default"] != "fast" {
+		t.Errorf("unexpected mode schema: %v", mode)
+	}
+	code := props["code"].(map[string]any)
+	if code["pattern"] != "^[A-Z]+$" || code["minLength"] != 2 || code["maxLength"] != 5 {
+		t.Errorf("unexpected code schema: %v", code)
+	}
+	if props["when"].(map[string]any)["format"] != "date-time" {
+		t.Errorf("missing format: %v", props["when"])
+	}
+	level := props["level"].(map[string]any)
+	if level["minimum"] != 1.0 || level["maximum"] != 10.0 || level["default"] != 3.0 {
+		t.Errorf("unexpected level schema: %v", level)
+	}
+	ratio := props["ratio"].(map[string]any)
+	if ratio["exclusiveMinimum"] != 0.0 || ratio["exclusiveMaximum"] != 1.0 || ratio["multipleOf"] != 0.25 {
+		t.Errorf("unexpected ratio schema: %v", ratio)
+	}
+
+	tags := props["tags"].(map[string]any)
+	if tags["minItems"] != 1 || tags["maxItems"] != 3 || tags["uniqueItems"] != true {
+		t.Errorf("unexpected tags schema: %v", tags)
+	}
+	if _, ok := tags["enum"]; ok {
+		t.Errorf("enum should apply to the items, not the array: %v", tags)
+	}
+	if items := tags["items"].(map[string]any); !reflect.DeepEqual(items["enum"], []any{"a", "b"}) {
+		t.Errorf("expected item enum, got %v", items)
+	}
+
+	opts := props["opts"].(map[string]any)
+	if !reflect.DeepEqual(opts["default"], map[string]any{"k": "v"}) {
+		t.Errorf("unexpected opts default: %v", opts)
+	}
+	if required := tool.BuildSchema()["required"].([]string); !reflect.DeepEqual(required, []string{"opts"}) {
+		t.Errorf("options mixed with properties should still apply, got required %v", required)
+	}
+}
+
+func TestParamConstraintsEnforced(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("t", "test",
+		String("mode", "mode", Enum("fast", "slow")),
+		Integer("level", "level", Minimum(1), Maximum(10)),
+		StringArray("tags", "tags", MaxItems(2), UniqueItems()),
+	), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("ok"), nil
+	})
+	ctx := context.Background()
+
+	for _, args := range []map[string]any{
+		{"mode": "medium"},
+		{"level": float64(11)},
+		{"tags": []any{"a", "a"}},
+		{"tags": []any{"a", "b", "c"}},
+	} {
+		_, err := server.CallTool(ctx, "t", args)
+		var toolErr *ToolError
+		if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInvalidParams {
+			t.Errorf("expected invalid params for %v, got %v", args, err)
+		}
+	}
+	if _, err := server.CallTool(ctx, "t", map[string]any{"mode": "slow", "level": float64(10), "tags": []any{"a"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestToolRequestDefaults(t *testing.T) {
+	server := NewServer("test", "1.0")
+	var mode string
+	var level int
+	var ratio float64
+	var tags []string
+	var argCount int
+	server.RegisterTool(NewTool("t", "test",
+		String("mode", "mode", Default("fast")),
+		Integer("level", "level", Default(3)),
+		Number("ratio", "ratio", Default(0.5)),
+		StringArray("tags", "tags", Default([]string{"x"})),
+	), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		mode, _ = req.String("mode")
+		level, _ = req.Int("level")
+		ratio, _ = req.Float("ratio")
+		tags, _ = req.StringSlice("tags")
+		argCount = len(req.Args())
+		return NewToolResponseText("ok"), nil
+	})
+	ctx := context.Background()
+
+	if _, err := server.CallTool(ctx, "t", map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode != "fast" || level != 3 || ratio != 0.5 || !reflect.DeepEqual(tags, []string{"x"}) {
+		t.Errorf("defaults not applied: mode=%q level=%d ratio=%v tags=%v", mode, level, ratio, tags)
+	}
+	if argCount != 0 {
+		t.Errorf("defaults should not be merged into Args, got %d args", argCount)
+	}
+
+	if _, err := server.CallTool(ctx, "t", map[string]any{"mode": "slow"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode != "slow" {
+		t.Errorf("explicit argument should win over default, got %q", mode)
+	}
+}
@@ -0,0 +1,485 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrorCodePolicyDenied is the JSON-RPC error code sent when a policy denies a
+// request.
+const ErrorCodePolicyDenied = -32003
+
+// ErrPolicyDenied is returned for calls to tools, reads of resources and gets
+// of prompts that the server's policy denies. Use the server's Explain
+// methods to find out why.
+var ErrPolicyDenied = &ToolError{Code: ErrorCodePolicyDenied, Message: "access denied by policy"}
+
+// Principal identifies who a request is made for, for policy rules. Attach it
+// to the request context with WithPrincipal, typically in the middleware that
+// authenticates the request.
+type Principal struct {
+	ID    string
+	Roles []string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context whose requests are made for p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal attached to ctx by WithPrincipal.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	if ctx == nil {
+		return Principal{}, false
+	}
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// PolicyEffect is what a matching policy rule does.
+type PolicyEffect int
+
+const (
+	// PolicyAllow permits matching items unless a deny rule also matches.
+	PolicyAllow PolicyEffect = iota
+
+	// PolicyDeny forbids matching items, whatever allow rules say.
+	PolicyDeny
+)
+
+func (e PolicyEffect) String() string {
+	if e == PolicyDeny {
+		return "deny"
+	}
+	return "allow"
+}
+
+// PolicyAnnotations matches tools by their annotation hints. Each hint that is
+// set must equal the tool's; hints a tool does not give take their MCP
+// defaults (not read-only, destructive, not idempotent, open world).
+type PolicyAnnotations struct {
+	ReadOnly    *bool
+	Destructive *bool
+	Idempotent  *bool
+	OpenWorld   *bool
+}
+
+// PolicyRule allows or denies the tools, resources and prompts it matches. A
+// rule matches when every condition it sets holds; conditions left empty
+// match anything. Patterns are globs in which * matches any run of characters
+// and ? any single character.
+type PolicyRule struct {
+	// Name identifies the rule in explanations.
+	Name   string
+	Effect PolicyEffect
+
+	// Principals and Roles match the request's principal by ID or by any of
+	// its roles. A request without a principal has an empty ID and no roles.
+	Principals []string
+	Roles      []string
+
+	// Namespaces matches the namespace of the item: that of the remote server
+	// it comes from, or the prefix of a namespaced provider tool. Local items
+	// have an empty namespace.
+	Namespaces []string
+
+	// Tools, Resources and Prompts match tool names, resource URIs (or URI
+	// templates) and prompt names. A rule that sets none of them applies to
+	// all three kinds; otherwise it applies only to the kinds it sets.
+	Tools     []string
+	Resources []string
+	Prompts   []string
+
+	// Annotations, if set, restricts the rule to tools with these hints.
+	Annotations *PolicyAnnotations
+}
+
+// Policy decides which tools, resources and prompts each principal may see
+// and use. An item denied by a rule is denied; otherwise one allowed by a rule
+// is allowed; otherwise DefaultDeny decides. The discovery meta-tools are not
+// subject to the policy, but the items they find and run are.
+type Policy struct {
+	Rules       []PolicyRule
+	DefaultDeny bool
+}
+
+// PolicyDecision explains whether a policy permits an item.
+type PolicyDecision struct {
+	Allowed   bool   `json:"allowed"`
+	Rule      string `json:"rule,omitempty"`      // Rule that decided; empty for the default
+	Namespace string `json:"namespace,omitempty"` // Namespace the item was matched under
+	Reason    string `json:"reason"`
+}
+
+// policyKind is the kind of item a policy is applied to.
+type policyKind int
+
+const (
+	policyTool policyKind = iota
+	policyResource
+	policyPrompt
+)
+
+func (k policyKind) String() string {
+	switch k {
+	case policyResource:
+		return "resource"
+	case policyPrompt:
+		return "prompt"
+	}
+	return "tool"
+}
+
+// policyItem is a tool, resource or prompt a policy is applied to.
+type policyItem struct {
+	kind        policyKind
+	name        string // Tool or prompt name, or resource URI
+	namespace   string
+	annotations *ToolAnnotations // Tools only
+}
+
+// decide applies the policy to item for principal.
+func (p *Policy) decide(principal Principal, item policyItem) PolicyDecision {
+	allowedBy := -1
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if !rule.matches(principal, item) {
+			continue
+		}
+		if rule.Effect == PolicyDeny {
+			return PolicyDecision{
+				Rule:      rule.label(i),
+				Namespace: item.namespace,
+				Reason:    fmt.Sprintf("%s %q denied by rule %s", item.kind, item.name, rule.label(i)),
+			}
+		}
+		if allowedBy < 0 {
+			allowedBy = i
+		}
+	}
+	if allowedBy >= 0 {
+		rule := &p.Rules[allowedBy]
+		return PolicyDecision{
+			Allowed:   true,
+			Rule:      rule.label(allowedBy),
+			Namespace: item.namespace,
+			Reason:    fmt.Sprintf("%s %q allowed by rule %s", item.kind, item.name, rule.label(allowedBy)),
+		}
+	}
+	if p.DefaultDeny {
+		return PolicyDecision{
+			Namespace: item.namespace,
+			Reason:    fmt.Sprintf("no rule allows %s %q and the policy denies by default", item.kind, item.name),
+		}
+	}
+	return PolicyDecision{
+		Allowed:   true,
+		Namespace: item.namespace,
+		Reason:    fmt.Sprintf("no rule matches %s %q and the policy allows by default", item.kind, item.name),
+	}
+}
+
+// label names rule i of a policy for explanations.
+func (r *PolicyRule) label(i int) string {
+	if r.Name != "" {
+		return fmt.Sprintf("%q", r.Name)
+	}
+	return fmt.Sprintf("#%d", i)
+}
+
+// matches reports whether the rule applies to item for principal.
+func (r *PolicyRule) matches(principal Principal, item policyItem) bool {
+	if len(r.Principals) > 0 && !matchAnyGlob(r.Principals, principal.ID) {
+		return false
+	}
+	if len(r.Roles) > 0 && !slices.ContainsFunc(principal.Roles, func(role string) bool {
+		return matchAnyGlob(r.Roles, role)
+	}) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !matchAnyGlob(r.Namespaces, item.namespace) {
+		return false
+	}
+
+	if len(r.Tools) > 0 || len(r.Resources) > 0 || len(r.Prompts) > 0 {
+		var patterns []string
+		switch item.kind {
+		case policyTool:
+			patterns = r.Tools
+		case policyResource:
+			patterns = r.Resources
+		case policyPrompt:
+			patterns = r.Prompts
+		}
+		if !matchAnyGlob(patterns, item.name) {
+			return false
+		}
+	}
+
+	if r.Annotations != nil {
+		return item.kind == policyTool && r.Annotations.matches(item.annotations)
+	}
+	return true
+}
+
+// matches reports whether a tool with annotations a has the hints required.
+func (pa *PolicyAnnotations) matches(a *ToolAnnotations) bool {
+	if a == nil {
+		a = &ToolAnnotations{}
+	}
+	readOnly := a.ReadOnlyHint
+	destructive := !readOnly && (a.DestructiveHint == nil || *a.DestructiveHint)
+	openWorld := a.OpenWorldHint == nil || *a.OpenWorldHint
+	return hintMatches(pa.ReadOnly, readOnly) &&
+		hintMatches(pa.Destructive, destructive) &&
+		hintMatches(pa.Idempotent, a.IdempotentHint) &&
+		hintMatches(pa.OpenWorld, openWorld)
+}
+
+func hintMatches(want *bool, got bool) bool {
+	return want == nil || *want == got
+}
+
+// matchAnyGlob reports whether s matches any of patterns.
+func matchAnyGlob(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether s matches pattern, in which * matches any run of
+// characters, including none, and ? matches any single character.
+func matchGlob(pattern, s string) bool {
+	p, n := []rune(pattern), []rune(s)
+	pi, ni := 0, 0
+	star, mark := -1, 0
+	for ni < len(n) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == n[ni]):
+			pi++
+			ni++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ni
+			pi++
+		case star >= 0:
+			// Let the last * absorb one more character and try again.
+			mark++
+			pi, ni = star+1, mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// SetPolicy sets the policy that decides which tools, resources and prompts
+// each principal sees and may use, or removes it if p is nil. The policy
+// applies to tools/list, tool_search results, tools/call and execute_tool,
+// and to the listing, search, reading and getting of resources and prompts.
+// The principal is taken from the request context; see WithPrincipal.
+//
+// p must not be modified after it is set.
+func (s *Server) SetPolicy(p *Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = p
+}
+
+// getPolicy returns the policy under read lock.
+func (s *Server) getPolicy() *Policy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// ExplainTool reports whether the principal on ctx may see and call the tool
+// called name, and which rule decided. It is meant for finding out why a tool
+// is hidden.
+func (s *Server) ExplainTool(ctx context.Context, name string) PolicyDecision {
+	policy := s.getPolicy()
+	if policy == nil {
+		return PolicyDecision{Allowed: true, Reason: "no policy is set"}
+	}
+	if s.isMetaTool(name) {
+		return PolicyDecision{Allowed: true, Reason: fmt.Sprintf("tool %q is a discovery tool, which policies do not apply to", name)}
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	return policy.decide(principal, s.toolPolicyItem(ctx, name, s.toolNamespacer()))
+}
+
+// ExplainResource reports whether the principal on ctx may see and read the
+// resource at uri, or the template with that URI template, and which rule
+// decided.
+func (s *Server) ExplainResource(ctx context.Context, uri string) PolicyDecision {
+	policy := s.getPolicy()
+	if policy == nil {
+		return PolicyDecision{Allowed: true, Reason: "no policy is set"}
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	return policy.decide(principal, policyItem{kind: policyResource, name: uri, namespace: s.resourceNamespace(uri)})
+}
+
+// ExplainPrompt reports whether the principal on ctx may see and get the
+// prompt called name, and which rule decided.
+func (s *Server) ExplainPrompt(ctx context.Context, name string) PolicyDecision {
+	policy := s.getPolicy()
+	if policy == nil {
+		return PolicyDecision{Allowed: true, Reason: "no policy is set"}
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	return policy.decide(principal, policyItem{kind: policyPrompt, name: name, namespace: s.toolNamespacer()(name)})
+}
+
+// isMetaTool reports whether name is a discovery meta-tool rather than a local
+// tool of the same name.
+func (s *Server) isMetaTool(name string) bool {
+	switch name {
+	case ToolSearchName, ExecuteToolName:
+		return true
+	}
+	if !isMetaToolName(name) {
+		return false
+	}
+	s.mu.RLock()
+	_, isLocal := s.tools[name]
+	s.mu.RUnlock()
+	return !isLocal
+}
+
+// toolPolicyItem describes the tool called name for the policy, with the
+// annotations of whichever local, remote or provider tool has that name,
+// including remote tools found through the remote's tool_search.
+func (s *Server) toolPolicyItem(ctx context.Context, name string, namespaceOf func(string) string) policyItem {
+	item := policyItem{kind: policyTool, name: name, namespace: namespaceOf(name)}
+
+	s.mu.RLock()
+	if tool, ok := s.tools[name]; ok {
+		item.annotations = tool.Annotations
+		s.mu.RUnlock()
+		return item
+	}
+	_, isRemote := s.toolToServer[name]
+	var tool *MCPTool
+	var discoveredOn *registeredClient
+	if isRemote {
+		tool = s.remoteToolLocked(name)
+	} else {
+		discoveredOn = s.discoveredRemoteLocked(name)
+	}
+	s.mu.RUnlock()
+
+	if discoveredOn != nil && ctx != nil {
+		if found := discoveredOn.discoveredTool(ctx, name); found != nil {
+			item.annotations = found.Annotations
+		}
+		return item
+	}
+	if tool == nil && !isRemote && ctx != nil {
+		tool = providerTool(ctx, name)
+	}
+	if tool != nil {
+		item.annotations = tool.Annotations
+	}
+	return item
+}
+
+// checkToolPolicy returns ErrPolicyDenied if the policy forbids the principal
+// on ctx to call the tool called name.
+func (s *Server) checkToolPolicy(ctx context.Context, name string) error {
+	policy := s.getPolicy()
+	if policy == nil || s.isMetaTool(name) {
+		return nil
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	if !policy.decide(principal, s.toolPolicyItem(ctx, name, s.toolNamespacer())).Allowed {
+		return ErrPolicyDenied
+	}
+	return nil
+}
+
+// toolPolicyFilter returns a function reporting whether the policy lets the
+// principal on ctx see a tool, or nil if no policy is set.
+func (s *Server) toolPolicyFilter(ctx context.Context) func(tool *MCPTool) bool {
+	policy := s.getPolicy()
+	if policy == nil {
+		return nil
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	namespaceOf := s.toolNamespacer()
+	return func(tool *MCPTool) bool {
+		item := policyItem{kind: policyTool, name: tool.Name, namespace: namespaceOf(tool.Name), annotations: tool.Annotations}
+		return policy.decide(principal, item).Allowed
+	}
+}
+
+// filterToolsByPolicy drops the tools the policy hides from the principal on
+// ctx. The discovery meta-tools are kept.
+func (s *Server) filterToolsByPolicy(ctx context.Context, tools []MCPTool) []MCPTool {
+	allowed := s.toolPolicyFilter(ctx)
+	if allowed == nil {
+		return tools
+	}
+	return slices.DeleteFunc(tools, func(tool MCPTool) bool {
+		return !s.isMetaTool(tool.Name) && !allowed(&tool)
+	})
+}
+
+// filterSearchResultsByPolicy drops the tool_search results the policy hides
+// from the principal on ctx.
+func (s *Server) filterSearchResultsByPolicy(ctx context.Context, results []SearchResult) []SearchResult {
+	allowed := s.toolPolicyFilter(ctx)
+	if allowed == nil {
+		return results
+	}
+	return slices.DeleteFunc(results, func(r SearchResult) bool {
+		tool := MCPTool{Name: r.Name, Annotations: r.Annotations}
+		if tool.Annotations == nil && r.ReadOnly {
+			tool.Annotations = &ToolAnnotations{ReadOnlyHint: true}
+		}
+		return !allowed(&tool)
+	})
+}
+
+// resourceNamespace returns the namespace of a resource URI or URI template:
+// that of the remote server it belongs to, or the part before
+// ResourceNamespaceSeparator when that precedes the URI's scheme, as used by
+// providers.
+func (s *Server) resourceNamespace(uri string) string {
+	s.mu.RLock()
+	for _, rc := range s.remoteClients {
+		if rc.namespace != "" && strings.HasPrefix(uri, rc.namespace+ResourceNamespaceSeparator) {
+			s.mu.RUnlock()
+			return rc.namespace
+		}
+	}
+	s.mu.RUnlock()
+	if namespace, rest, ok := strings.Cut(uri, ResourceNamespaceSeparator); ok &&
+		!strings.ContainsAny(namespace, ":/") && strings.Contains(rest, ":") {
+		return namespace
+	}
+	return ""
+}
+
+// allowResource reports whether the policy lets the principal on ctx see and
+// read the resource at uri (or template).
+func (s *Server) allowResource(ctx context.Context, policy *Policy, uri string) bool {
+	principal, _ := PrincipalFromContext(ctx)
+	return policy.decide(principal, policyItem{kind: policyResource, name: uri, namespace: s.resourceNamespace(uri)}).Allowed
+}
+
+// allowPrompt reports whether the policy lets the principal on ctx see and
+// get the prompt called name.
+func (s *Server) allowPrompt(ctx context.Context, policy *Policy, name string, namespaceOf func(string) string) bool {
+	principal, _ := PrincipalFromContext(ctx)
+	return policy.decide(principal, policyItem{kind: policyPrompt, name: name, namespace: namespaceOf(name)}).Allowed
+}
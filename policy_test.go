@@ -0,0 +1,245 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func policyTestServer() *Server {
+	server := NewServer("test", "1.0")
+	handler := func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("ok"), nil
+	}
+	server.RegisterTool(NewTool("list_files", "List files").ReadOnly(), handler)
+	server.RegisterTool(NewTool("delete_file", "Delete a file"), handler)
+	server.RegisterTool(NewTool("admin_reset", "Reset everything").Discoverable("reset"), handler)
+	server.RegisterResource(NewResource("config://app", "App Config", "", "application/json"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), "{}", "application/json"), nil
+		})
+	server.RegisterResource(NewResource("secret://keys", "Keys", "", "text/plain"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), "k", "text/plain"), nil
+		})
+	server.RegisterPrompt(NewPrompt("summarize", "Summarize"),
+		func(ctx context.Context, req *PromptRequest) (*PromptResponse, error) {
+			return NewPromptResponseText("summarize"), nil
+		})
+	server.RegisterPrompt(NewPrompt("admin_report", "Admin report"),
+		func(ctx context.Context, req *PromptRequest) (*PromptResponse, error) {
+			return NewPromptResponseText("report"), nil
+		})
+
+	readOnly := true
+	server.SetPolicy(&Policy{
+		DefaultDeny: true,
+		Rules: []PolicyRule{
+			{Name: "read-only tools", Effect: PolicyAllow, Annotations: &PolicyAnnotations{ReadOnly: &readOnly}},
+			{Name: "viewer catalog", Effect: PolicyAllow, Resources: []string{"config://*"}, Prompts: []string{"*"}},
+			{Name: "admins", Effect: PolicyAllow, Roles: []string{"admin"}},
+			{Name: "no admin items", Effect: PolicyDeny, Principals: []string{"guest-*"}, Tools: []string{"admin_*"}, Prompts: []string{"admin_*"}},
+		},
+	})
+	return server
+}
+
+func TestPolicyListTools(t *testing.T) {
+	server := policyTestServer()
+
+	viewer := WithPrincipal(context.Background(), Principal{ID: "alice", Roles: []string{"viewer"}})
+	got := strings.Join(toolNames(server.ListToolsWithContext(viewer)), ",")
+	if got != "execute_tool,list_files,tool_search" {
+		t.Errorf("viewer sees %s", got)
+	}
+
+	admin := WithPrincipal(context.Background(), Principal{ID: "root", Roles: []string{"admin"}})
+	got = strings.Join(toolNames(server.ListToolsWithContext(WithShowAllTools(admin))), ",")
+	if got != "admin_reset,delete_file,list_files" {
+		t.Errorf("admin sees %s", got)
+	}
+
+	// A deny rule overrides any allow.
+	guest := WithPrincipal(context.Background(), Principal{ID: "guest-1", Roles: []string{"admin"}})
+	got = strings.Join(toolNames(server.ListToolsWithContext(WithShowAllTools(guest))), ",")
+	if got != "delete_file,list_files" {
+		t.Errorf("guest admin sees %s", got)
+	}
+}
+
+func TestPolicyToolSearchAndCall(t *testing.T) {
+	server := policyTestServer()
+	viewer := WithPrincipal(context.Background(), Principal{ID: "alice"})
+
+	resp, err := server.CallTool(viewer, ToolSearchName, map[string]any{"query": "reset"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := resp.Content[0].Text; strings.Contains(text, "admin_reset") {
+		t.Errorf("tool_search returned a denied tool: %s", text)
+	}
+
+	if _, err := server.CallTool(viewer, ExecuteToolName, map[string]any{"name": "admin_reset"}); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("execute_tool of a denied tool: got %v, want ErrPolicyDenied", err)
+	}
+	if _, err := server.CallTool(viewer, "delete_file", nil); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("call of a denied tool: got %v, want ErrPolicyDenied", err)
+	}
+	if _, err := server.CallTool(viewer, "list_files", nil); err != nil {
+		t.Errorf("call of an allowed tool: %v", err)
+	}
+
+	admin := WithPrincipal(context.Background(), Principal{ID: "root", Roles: []string{"admin"}})
+	resp, err = server.CallTool(admin, ToolSearchName, map[string]any{"query": "reset"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := resp.Content[0].Text; !strings.Contains(text, "admin_reset") {
+		t.Errorf("tool_search hid an allowed tool: %s", text)
+	}
+}
+
+func TestPolicyDeniesDestructiveRemoteTools(t *testing.T) {
+	handler := func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("ok"), nil
+	}
+	destructive := true
+	remote := NewServer("remote", "1.0")
+	remote.RegisterTool(NewTool("drop_repo", "Drop a repo").Discoverable("repo").
+		Annotations(ToolAnnotations{DestructiveHint: &destructive}), handler)
+	remote.RegisterTool(NewTool("list_repos", "List repos").Discoverable("repo").ReadOnly(), handler)
+	remoteTS := httptest.NewServer(http.HandlerFunc(remote.HandleRequest))
+	defer remoteTS.Close()
+
+	newServer := func() *Server {
+		server := NewServer("main", "1.0")
+		if err := server.ReplaceRemoteServers([]RemoteServerEntry{
+			{Client: NewClient(remoteTS.URL, nil, "gh"), Visibility: ToolVisibilityDiscoverable, RemoteSearch: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		server.SetPolicy(&Policy{Rules: []PolicyRule{
+			{Name: "no destructive tools", Effect: PolicyDeny, Annotations: &PolicyAnnotations{Destructive: &destructive}},
+		}})
+		return server
+	}
+	ctx := context.Background()
+
+	server := newServer()
+	resp, err := server.CallTool(ctx, ToolSearchName, map[string]any{"query": "repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := resp.Content[0].Text; strings.Contains(text, "drop_repo") || !strings.Contains(text, "gh__list_repos") {
+		t.Errorf("tool_search should drop only the destructive remote tool: %s", text)
+	}
+
+	// The second server has not searched, so the call looks the tool up itself.
+	for _, s := range []*Server{server, newServer()} {
+		if _, err := s.CallTool(ctx, "gh__drop_repo", nil); !errors.Is(err, ErrPolicyDenied) {
+			t.Errorf("call of a destructive remote tool: got %v, want ErrPolicyDenied", err)
+		}
+		if _, err := s.CallTool(ctx, "gh__list_repos", nil); err != nil {
+			t.Errorf("call of a read-only remote tool: %v", err)
+		}
+	}
+}
+
+func TestPolicyResourcesAndPrompts(t *testing.T) {
+	server := policyTestServer()
+	viewer := WithPrincipal(context.Background(), Principal{ID: "guest-7"})
+
+	resources := server.ListResources(viewer)
+	if len(resources) != 1 || resources[0].URI != "config://app" {
+		t.Errorf("unexpected resources %+v", resources)
+	}
+	if _, err := server.ReadResource(viewer, "secret://keys"); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("read of a denied resource: got %v, want ErrPolicyDenied", err)
+	}
+	if _, err := server.ReadResource(viewer, "config://app"); err != nil {
+		t.Errorf("read of an allowed resource: %v", err)
+	}
+
+	prompts := server.ListPrompts(viewer)
+	if len(prompts) != 1 || prompts[0].Name != "summarize" {
+		t.Errorf("unexpected prompts %+v", prompts)
+	}
+	if _, err := server.GetPrompt(viewer, "admin_report", nil); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("get of a denied prompt: got %v, want ErrPolicyDenied", err)
+	}
+}
+
+func TestPolicyExplain(t *testing.T) {
+	server := policyTestServer()
+	guest := WithPrincipal(context.Background(), Principal{ID: "guest-1", Roles: []string{"admin"}})
+
+	d := server.ExplainTool(guest, "admin_reset")
+	if d.Allowed || d.Rule != `"no admin items"` {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	d = server.ExplainTool(guest, "list_files")
+	if !d.Allowed || d.Rule != `"read-only tools"` {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	d = server.ExplainTool(context.Background(), "delete_file")
+	if d.Allowed || d.Rule != "" || !strings.Contains(d.Reason, "denies by default") {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	if d := server.ExplainTool(context.Background(), ToolSearchName); !d.Allowed {
+		t.Errorf("discovery tools should not be subject to the policy: %+v", d)
+	}
+	if d := server.ExplainResource(guest, "secret://keys"); !d.Allowed || d.Rule != `"admins"` {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	if d := server.ExplainPrompt(guest, "admin_report"); d.Allowed {
+		t.Errorf("unexpected decision %+v", d)
+	}
+
+	server.SetPolicy(nil)
+	if d := server.ExplainTool(context.Background(), "delete_file"); !d.Allowed {
+		t.Errorf("unexpected decision without a policy %+v", d)
+	}
+}
+
+func TestPolicyNamespaces(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.SetPolicy(&Policy{Rules: []PolicyRule{
+		{Effect: PolicyDeny, Namespaces: []string{"github"}},
+	}})
+
+	if d := server.ExplainTool(context.Background(), "github__create_issue"); d.Allowed || d.Namespace != "github" {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	if d := server.ExplainResource(context.Background(), "github+repo://x/y"); d.Allowed || d.Namespace != "github" {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	if d := server.ExplainResource(context.Background(), "file:///a+b"); !d.Allowed || d.Namespace != "" {
+		t.Errorf("unexpected decision %+v", d)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"admin_*", "admin_reset", true},
+		{"admin_*", "user_reset", false},
+		{"*_file", "delete_file", true},
+		{"d?lete_*", "delete_file", true},
+		{"docs://*", "docs://a/b", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
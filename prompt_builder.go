@@ -11,9 +11,11 @@ type PromptHandler func(ctx context.Context, req *PromptRequest) (*PromptRespons
 // server renders it into one or more messages via prompts/get. Register it with
 // [Server.RegisterPrompt].
 type PromptBuilder struct {
-	name        string
-	description string
-	arguments   []MCPPromptArgument
+	name         string
+	description  string
+	arguments    []MCPPromptArgument
+	discoverable bool     // If true, found via prompt_search but not in prompts/list
+	keywords     []string // Keywords for prompt_search
 }
 
 // NewPrompt creates a prompt descriptor. Chain Argument calls to declare the
@@ -44,12 +46,29 @@ func (p *PromptBuilder) Description() string { return p.description }
 // Arguments returns the prompt's declared arguments.
 func (p *PromptBuilder) Arguments() []MCPPromptArgument { return p.arguments }
 
+// Discoverable hides the prompt from prompts/list; it is found with the
+// prompt_search meta-tool instead and can still be rendered by name. Keywords
+// improve search relevance.
+func (p *PromptBuilder) Discoverable(keywords ...string) *PromptBuilder {
+	p.discoverable = true
+	p.keywords = keywords
+	return p
+}
+
+// IsDiscoverable returns true if the prompt is marked as discoverable.
+func (p *PromptBuilder) IsDiscoverable() bool { return p.discoverable }
+
+// Keywords returns the keywords set for this prompt.
+func (p *PromptBuilder) Keywords() []string { return p.keywords }
+
 // ToMCPPrompt converts the builder to an MCPPrompt descriptor.
 func (p *PromptBuilder) ToMCPPrompt() MCPPrompt {
 	return MCPPrompt{
 		Name:        p.name,
 		Description: p.description,
 		Arguments:   p.arguments,
+		Keywords:    p.keywords,
+		Visibility:  visibilityFor(p.discoverable),
 	}
 }
 
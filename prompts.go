@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"sort"
 )
 
@@ -42,9 +43,17 @@ func (s *Server) UnregisterPrompt(name string) bool {
 }
 
 // ListPrompts returns all registered prompts plus any contributed by
-// [PromptProvider]s on ctx, sorted by name. Duplicates (by name) are removed,
-// with static registrations taking precedence.
+// [PromptProvider]s on ctx, sorted by name, followed by those of registered
+// remote servers under namespaced names. Duplicates (by name) are removed,
+// with static registrations taking precedence. Discoverable prompts are left
+// out unless ctx is in show-all mode (see [WithShowAllTools]).
 func (s *Server) ListPrompts(ctx context.Context) []MCPPrompt {
+	return s.listPrompts(ctx, GetShowAllTools(ctx))
+}
+
+// listPrompts lists prompts, including discoverable ones if all is set.
+func (s *Server) listPrompts(ctx context.Context, all bool) []MCPPrompt {
+	ctx = s.withPrincipalRemotes(ctx)
 	s.mu.RLock()
 	result := make([]MCPPrompt, 0, len(s.prompts))
 	seen := make(map[string]bool, len(s.prompts))
@@ -59,16 +68,39 @@ func (s *Server) ListPrompts(ctx context.Context) []MCPPrompt {
 	if ctx != nil {
 		result = append(result, listPromptsFromProviders(ctx, seen)...)
 	}
+	for _, catalog := range s.remoteCatalogs() {
+		for _, prompt := range catalog.prompts {
+			if !seen[prompt.Name] {
+				result = append(result, prompt)
+				seen[prompt.Name] = true
+			}
+		}
+	}
+	if policy := s.getPolicy(); policy != nil {
+		namespaceOf := s.toolNamespacer()
+		result = slices.DeleteFunc(result, func(p MCPPrompt) bool { return !s.allowPrompt(ctx, policy, p.Name, namespaceOf) })
+	}
+	if !all {
+		return nativeOnly(result, func(p MCPPrompt) ToolVisibility { return p.Visibility })
+	}
 	return result
 }
 
-// GetPrompt renders a prompt by name with the given arguments. Resolution order:
+// GetPrompt renders a prompt by name with the given arguments. Discoverable
+// prompts are rendered like any other. Resolution order:
 //  1. Statically-registered prompts (exact name match).
 //  2. [PromptProvider]s on ctx, in attachment order (first hit wins).
+//  3. Registered remote servers, by namespaced name.
 //
 // Required arguments are validated before the handler runs. Returns
-// ErrUnknownPrompt if nothing handles the name.
+// ErrUnknownPrompt if nothing handles the name, and ErrPolicyDenied if the
+// server's policy forbids getting it (see SetPolicy).
 func (s *Server) GetPrompt(ctx context.Context, name string, args map[string]string) (*PromptResponse, error) {
+	ctx = s.withPrincipalRemotes(ctx)
+	if policy := s.getPolicy(); policy != nil && !s.allowPrompt(ctx, policy, name, s.toolNamespacer()) {
+		return nil, ErrPolicyDenied
+	}
+
 	if args == nil {
 		args = map[string]string{}
 	}
@@ -87,9 +119,12 @@ func (s *Server) GetPrompt(ctx context.Context, name string, args map[string]str
 	if ctx != nil {
 		// Providers validate their own arguments, but for a consistent client
 		// experience we validate against the static descriptor if present.
-		return getPromptFromProviders(ctx, name, args)
+		resp, err := getPromptFromProviders(ctx, name, args)
+		if err != ErrUnknownPrompt {
+			return resp, err
+		}
 	}
-	return nil, ErrUnknownPrompt
+	return s.getRemotePrompt(ctx, name, args)
 }
 
 // handlePromptsList handles prompts/list over HTTP.
@@ -30,8 +30,11 @@ type MultiProvider struct {
 	providers []ToolProvider
 }
 
-// Ensure MultiProvider implements ToolProvider.
-var _ ToolProvider = (*MultiProvider)(nil)
+// Ensure MultiProvider implements ToolProvider and ToolLookup.
+var (
+	_ ToolProvider = (*MultiProvider)(nil)
+	_ ToolLookup   = (*MultiProvider)(nil)
+)
 
 // NewMultiProvider combines the given providers into a single ToolProvider.
 // Nil providers are skipped. If no non-nil providers are supplied, nil is
@@ -67,6 +70,17 @@ func (p *MultiProvider) GetTools(ctx context.Context) ([]MCPTool, error) {
 	return tools, nil
 }
 
+// LookupTool returns the tool called name from the first provider that lists
+// it, or nil if none does. Providers that fail are skipped, as in GetTools.
+func (p *MultiProvider) LookupTool(ctx context.Context, name string) (*MCPTool, error) {
+	for _, provider := range p.providers {
+		if tool := lookupProviderTool(ctx, provider, name); tool != nil {
+			return tool, nil
+		}
+	}
+	return nil, nil
+}
+
 // ExecuteTool dispatches the call to the first provider that handles the tool.
 // See the MultiProvider type docs for the full skip/abort/first-success contract.
 func (p *MultiProvider) ExecuteTool(ctx context.Context, name string, params map[string]any) (*ToolResponse, error) {
@@ -0,0 +1,952 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/paularlott/mcp/pool"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultOpenAPIMaxResponseBytes is the largest response body an
+// OpenAPIProvider reads when OpenAPIProviderConfig.MaxResponseBytes is not set.
+const DefaultOpenAPIMaxResponseBytes = 10 << 20
+
+// OpenAPIOperation identifies an operation in an OpenAPI document. It is
+// passed to OpenAPIProviderConfig.OperationFilter.
+type OpenAPIOperation struct {
+	Method      string // upper-case HTTP method, e.g. "GET"
+	Path        string // path template, e.g. "/users/{id}"
+	OperationID string
+	Tags        []string
+}
+
+// OpenAPIProviderConfig configures an OpenAPIProvider.
+type OpenAPIProviderConfig struct {
+	// BaseURL is the API root that operation paths are appended to. Empty uses
+	// the first entry of the document's servers list, with server variables
+	// set to their defaults.
+	BaseURL string
+
+	// Namespace optionally prefixes every tool name (e.g. a Namespace of "pets"
+	// exposes the operation "listPets" as "pets__listPets").
+	Namespace string
+
+	// Auth supplies the credentials sent with each call. May be nil for
+	// unauthenticated APIs. When a call is answered with 401 Unauthorized the
+	// provider calls Refresh and retries once.
+	Auth AuthProvider
+
+	// AuthHeader is the header that receives Auth's value. Defaults to
+	// "Authorization"; set it for APIs that expect e.g. "X-API-Key".
+	AuthHeader string
+
+	// Headers are extra headers sent with every call.
+	Headers map[string]string
+
+	// HTTPPool optionally provides a custom HTTP pool (e.g. for self-signed
+	// internal services). Nil uses the default secure pool.
+	HTTPPool pool.HTTPPool
+
+	// Visibility controls whether the generated tools appear in tools/list or
+	// are only reachable via tool_search.
+	Visibility ToolVisibility
+
+	// OperationFilter optionally restricts which operations become tools.
+	// Nil exposes every operation.
+	OperationFilter func(op OpenAPIOperation) bool
+
+	// Keywords are extra search keywords attached to every tool. Each tool's
+	// OpenAPI tags and "openapi" are always included.
+	Keywords []string
+
+	// MaxResponseBytes caps how much of a response body is read. A call whose
+	// response is larger fails rather than returning a truncated body. Zero
+	// or less uses DefaultOpenAPIMaxResponseBytes.
+	MaxResponseBytes int64
+}
+
+// OpenAPIProvider is a ToolProvider that exposes the operations of an OpenAPI
+// 3.0 or 3.1 document as tools, one tool per operation. Path, query, header and
+// cookie parameters become top-level properties of the tool's input schema and
+// the request body, when there is one, becomes a "body" property. Calls are
+// executed over HTTP; JSON object responses are returned as structured
+// content, other responses as text.
+//
+// The tool set is fixed when the provider is created, so create it once and
+// reuse it:
+//
+//	provider, err := mcp.NewOpenAPIProvider(spec, mcp.OpenAPIProviderConfig{
+//	    Namespace: "petstore",
+//	    Auth:      mcp.NewBearerTokenAuth(token),
+//	})
+//	// per request:
+//	ctx := mcp.WithToolProviders(r.Context(), provider)
+type OpenAPIProvider struct {
+	cfg     OpenAPIProviderConfig
+	baseURL string
+	tools   []MCPTool
+	ops     map[string]*openAPIOperation
+}
+
+// Ensure OpenAPIProvider implements ToolProvider.
+var _ ToolProvider = (*OpenAPIProvider)(nil)
+
+// openAPIOperation is the information needed to turn a tool call back into an
+// HTTP request.
+type openAPIOperation struct {
+	method string
+	path   string
+	params []openAPIParam
+	body   *openAPIBody
+}
+
+type openAPIParam struct {
+	property string // input schema property holding the value
+	name     string // parameter name on the wire
+	in       string // path, query, header or cookie
+	explode  bool
+	asJSON   bool // parameter declared with content rather than schema
+}
+
+type openAPIBody struct {
+	property    string
+	contentType string
+}
+
+// NewOpenAPIProvider parses an OpenAPI 3.x document (JSON or YAML) and builds
+// a tool for each operation. Only local references ("#/components/...") are
+// supported. Operations whose request body has no JSON, form or text media
+// type are skipped.
+func NewOpenAPIProvider(spec []byte, cfg OpenAPIProviderConfig) (*OpenAPIProvider, error) {
+	doc, err := parseOpenAPIDocument(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &OpenAPIProvider{
+		cfg:     cfg,
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		ops:     make(map[string]*openAPIOperation),
+	}
+	if p.baseURL == "" {
+		p.baseURL = doc.serverURL()
+	}
+
+	paths, _ := doc.root["paths"].(map[string]any)
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+
+	for _, path := range pathNames {
+		item, err := doc.resolve(paths[path])
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", path, err)
+		}
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			op, _ := raw.(map[string]any)
+			if err := p.addOperation(doc, strings.ToUpper(method), path, item, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// addOperation converts one operation to a tool and records how to call it.
+func (p *OpenAPIProvider) addOperation(doc *openAPIDocument, method, path string, item, op map[string]any) error {
+	operationID, _ := op["operationId"].(string)
+	var tags []string
+	if rawTags, ok := op["tags"].([]any); ok {
+		for _, tag := range rawTags {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+	if p.cfg.OperationFilter != nil && !p.cfg.OperationFilter(OpenAPIOperation{Method: method, Path: path, OperationID: operationID, Tags: tags}) {
+		return nil
+	}
+
+	properties := make(map[string]any)
+	var required []string
+	call := &openAPIOperation{method: method, path: path}
+
+	// Path-level parameters apply to every operation unless the operation
+	// redefines a parameter with the same name and location.
+	params, err := doc.parameters(item["parameters"], op["parameters"])
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		if name == "" || in == "" {
+			continue
+		}
+		if in == "header" && isReservedOpenAPIHeader(name) {
+			continue
+		}
+
+		schema, asJSON, err := doc.parameterSchema(param)
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", name, err)
+		}
+		if desc, ok := param["description"].(string); ok && desc != "" {
+			schema["description"] = desc
+		}
+
+		property := name
+		if _, taken := properties[property]; taken {
+			property = in + "_" + name
+		}
+		properties[property] = schema
+		if req, _ := param["required"].(bool); req || in == "path" {
+			required = append(required, property)
+		}
+
+		explode := true
+		if style, _ := param["style"].(string); style != "" && style != "form" {
+			explode = false
+		}
+		if e, ok := param["explode"].(bool); ok {
+			explode = e
+		}
+		if in != "query" && in != "cookie" {
+			explode = false
+		}
+		call.params = append(call.params, openAPIParam{property: property, name: name, in: in, explode: explode, asJSON: asJSON})
+	}
+
+	if rawBody, ok := op["requestBody"]; ok {
+		body, err := doc.resolve(rawBody)
+		if err != nil {
+			return fmt.Errorf("request body: %w", err)
+		}
+		content, _ := body["content"].(map[string]any)
+		contentType, media := selectOpenAPIMediaType(content, true)
+		if contentType == "" {
+			return nil
+		}
+
+		schema := map[string]any{"type": "string"}
+		if isJSONMediaType(contentType) || contentType == "application/x-www-form-urlencoded" {
+			if schema, err = doc.mediaSchema(media); err != nil {
+				return fmt.Errorf("request body: %w", err)
+			}
+		}
+		if desc, ok := body["description"].(string); ok && desc != "" {
+			schema["description"] = desc
+		}
+
+		property := "body"
+		if _, taken := properties[property]; taken {
+			property = "request_body"
+		}
+		properties[property] = schema
+		if req, _ := body["required"].(bool); req {
+			required = append(required, property)
+		}
+		call.body = &openAPIBody{property: property, contentType: contentType}
+	}
+
+	inputSchema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		inputSchema["required"] = required
+	}
+
+	outputSchema, err := doc.outputSchema(op["responses"])
+	if err != nil {
+		return err
+	}
+
+	name := p.toolName(operationID, method, path)
+	keywords := append([]string{"openapi"}, tags...)
+	keywords = append(keywords, p.cfg.Keywords...)
+	if p.cfg.Namespace != "" {
+		keywords = append(keywords, p.cfg.Namespace)
+	}
+
+	tool := MCPTool{
+		Name:        name,
+		Description: openAPIDescription(op, method, path),
+		InputSchema: inputSchema,
+		Visibility:  p.cfg.Visibility,
+		Keywords:    keywords,
+	}
+	if outputSchema != nil {
+		tool.OutputSchema = outputSchema
+	}
+
+	p.tools = append(p.tools, tool)
+	p.ops[name] = call
+	return nil
+}
+
+// toolName derives a unique tool name from the operationId, falling back to
+// the method and path for operations without one.
+func (p *OpenAPIProvider) toolName(operationID, method, path string) string {
+	base := sanitizeOpenAPIName(operationID)
+	if base == "" {
+		base = sanitizeOpenAPIName(strings.ToLower(method) + "/" + path)
+	}
+	if p.cfg.Namespace != "" {
+		base = p.cfg.Namespace + DefaultNamespaceSeparator + base
+	}
+
+	name := base
+	for i := 2; p.ops[name] != nil; i++ {
+		name = base + "_" + strconv.Itoa(i)
+	}
+	return name
+}
+
+// GetTools returns a copy of the tools generated from the document.
+func (p *OpenAPIProvider) GetTools(ctx context.Context) ([]MCPTool, error) {
+	return slices.Clone(p.tools), nil
+}
+
+// ExecuteTool performs the HTTP request for the named operation. Returns
+// (nil, nil) for names this provider does not own. Responses with a non-2xx
+// status are returned as an isError result holding the status and body, so
+// the model can see what the API rejected.
+func (p *OpenAPIProvider) ExecuteTool(ctx context.Context, name string, params map[string]any) (*ToolResponse, error) {
+	op, ok := p.ops[name]
+	if !ok {
+		return nil, nil
+	}
+	if p.baseURL == "" {
+		return nil, NewToolErrorInternal("OpenAPI document has no server URL; set BaseURL")
+	}
+
+	path := op.path
+	query := url.Values{}
+	header := http.Header{}
+	var cookies []string
+
+	for _, param := range op.params {
+		value, ok := params[param.property]
+		if !ok || value == nil {
+			if param.in == "path" {
+				return nil, NewToolErrorInvalidParams(fmt.Sprintf("missing required path parameter %q", param.name))
+			}
+			continue
+		}
+		if param.asJSON {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, NewToolErrorInvalidParams(fmt.Sprintf("parameter %q: %v", param.name, err))
+			}
+			value = string(data)
+		}
+
+		switch param.in {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.name+"}", url.PathEscape(joinOpenAPIValue(value)))
+		case "query":
+			addOpenAPIQuery(query, param, value)
+		case "header":
+			header.Set(param.name, joinOpenAPIValue(value))
+		case "cookie":
+			cookies = append(cookies, param.name+"="+url.QueryEscape(joinOpenAPIValue(value)))
+		}
+	}
+	if len(cookies) > 0 {
+		header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+
+	target := p.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body []byte
+	if op.body != nil {
+		if value, ok := params[op.body.property]; ok && value != nil {
+			encoded, err := encodeOpenAPIBody(op.body.contentType, value)
+			if err != nil {
+				return nil, NewToolErrorInvalidParams(fmt.Sprintf("request body: %v", err))
+			}
+			body = encoded
+			header.Set("Content-Type", op.body.contentType)
+		}
+	}
+	header.Set("Accept", "application/json, */*;q=0.8")
+	for k, v := range p.cfg.Headers {
+		header.Set(k, v)
+	}
+
+	resp, err := p.do(ctx, op.method, target, header, body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", op.method, op.path, err)
+	}
+	defer resp.Body.Close()
+
+	limit := p.cfg.MaxResponseBytes
+	if limit <= 0 {
+		limit = DefaultOpenAPIMaxResponseBytes
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: read response: %w", op.method, op.path, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s %s: response larger than %d bytes", op.method, op.path, limit)
+	}
+	return openAPIResponse(op, resp, data)
+}
+
+// do sends the request, refreshing the credentials and retrying once when the
+// server answers 401 Unauthorized.
+func (p *OpenAPIProvider) do(ctx context.Context, method, target string, header http.Header, body []byte) (*http.Response, error) {
+	var client *http.Client
+	if p.cfg.HTTPPool != nil {
+		client = p.cfg.HTTPPool.GetHTTPClient()
+	} else {
+		client = pool.GetPool().GetHTTPClient()
+	}
+
+	authHeader := p.cfg.AuthHeader
+	if authHeader == "" {
+		authHeader = "Authorization"
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header = header.Clone()
+		if p.cfg.Auth != nil {
+			value, err := p.cfg.Auth.GetAuthHeader()
+			if err != nil {
+				return nil, fmt.Errorf("get auth header: %w", err)
+			}
+			if value != "" {
+				req.Header.Set(authHeader, value)
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized || p.cfg.Auth == nil || attempt > 0 {
+			return resp, nil
+		}
+		if err := p.cfg.Auth.Refresh(); err != nil {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// openAPIResponse maps an HTTP response to a tool response. JSON objects
+// become structured content (with the raw JSON as text), other JSON and
+// non-JSON bodies become text.
+func openAPIResponse(op *openAPIOperation, resp *http.Response, data []byte) (*ToolResponse, error) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	var value any
+	isJSON := isJSONMediaType(mediaType) && len(bytes.TrimSpace(data)) > 0 && json.Unmarshal(data, &value) == nil
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text := fmt.Sprintf("%s %s returned %s", op.method, op.path, resp.Status)
+		if len(data) > 0 {
+			text += "\n" + string(data)
+		}
+		return NewToolResponseError(text), nil
+	}
+
+	if len(data) == 0 {
+		return NewToolResponseText(resp.Status), nil
+	}
+	if isJSON {
+		if _, ok := value.(map[string]any); ok {
+			return NewToolResponseMulti(NewToolResponseText(string(data)), NewToolResponseStructured(value)), nil
+		}
+	}
+	return NewToolResponseText(string(data)), nil
+}
+
+func encodeOpenAPIBody(contentType string, value any) ([]byte, error) {
+	switch {
+	case isJSONMediaType(contentType):
+		return json.Marshal(value)
+	case contentType == "application/x-www-form-urlencoded":
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object for %s", contentType)
+		}
+		form := url.Values{}
+		for key, v := range fields {
+			addOpenAPIQuery(form, openAPIParam{name: key, explode: true}, v)
+		}
+		return []byte(form.Encode()), nil
+	default:
+		if s, ok := value.(string); ok {
+			return []byte(s), nil
+		}
+		return nil, fmt.Errorf("expected a string for %s", contentType)
+	}
+}
+
+// addOpenAPIQuery adds a value using the form style: exploded arrays repeat the
+// key, exploded objects contribute one key per property, and non-exploded
+// values are comma separated.
+func addOpenAPIQuery(values url.Values, param openAPIParam, value any) {
+	switch v := value.(type) {
+	case []any:
+		if !param.explode {
+			values.Add(param.name, joinOpenAPIValue(v))
+			return
+		}
+		for _, item := range v {
+			values.Add(param.name, formatOpenAPIValue(item))
+		}
+	case map[string]any:
+		if !param.explode {
+			values.Add(param.name, joinOpenAPIValue(v))
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			values.Add(key, formatOpenAPIValue(v[key]))
+		}
+	default:
+		values.Add(param.name, formatOpenAPIValue(v))
+	}
+}
+
+// joinOpenAPIValue serializes a value using the simple style: arrays become
+// "a,b,c" and objects "k1,v1,k2,v2".
+func joinOpenAPIValue(value any) string {
+	switch v := value.(type) {
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatOpenAPIValue(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(v)*2)
+		for _, key := range keys {
+			parts = append(parts, key, formatOpenAPIValue(v[key]))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return formatOpenAPIValue(v)
+	}
+}
+
+func formatOpenAPIValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func openAPIDescription(op map[string]any, method, path string) string {
+	summary, _ := op["summary"].(string)
+	description, _ := op["description"].(string)
+	switch {
+	case summary != "" && description != "" && summary != description:
+		return summary + "\n\n" + description
+	case summary != "":
+		return summary
+	case description != "":
+		return description
+	default:
+		return method + " " + path
+	}
+}
+
+// sanitizeOpenAPIName reduces s to the characters allowed in tool names,
+// replacing runs of anything else with a single underscore.
+func sanitizeOpenAPIName(s string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			if pending && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pending = false
+			b.WriteRune(r)
+			continue
+		}
+		pending = true
+	}
+	return b.String()
+}
+
+// isReservedOpenAPIHeader reports header parameters that OpenAPI says must be
+// ignored because they are controlled elsewhere.
+func isReservedOpenAPIHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "accept", "content-type", "authorization":
+		return true
+	}
+	return false
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// selectOpenAPIMediaType picks the media type to use from a content map,
+// preferring JSON. For request bodies form and text types are accepted as a
+// fallback; for responses only JSON is considered.
+func selectOpenAPIMediaType(content map[string]any, request bool) (string, map[string]any) {
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+
+	pick := func(match func(string) bool) (string, map[string]any) {
+		for _, contentType := range types {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil || !match(mediaType) {
+				continue
+			}
+			media, _ := content[contentType].(map[string]any)
+			return mediaType, media
+		}
+		return "", nil
+	}
+
+	if contentType, media := pick(isJSONMediaType); contentType != "" || !request {
+		return contentType, media
+	}
+	if contentType, media := pick(func(t string) bool { return t == "application/x-www-form-urlencoded" }); contentType != "" {
+		return contentType, media
+	}
+	return pick(func(t string) bool { return strings.HasPrefix(t, "text/") })
+}
+
+// openAPIDocument is a parsed OpenAPI document with helpers for resolving
+// local references and converting OpenAPI schemas to JSON Schema.
+type openAPIDocument struct {
+	root map[string]any
+}
+
+func parseOpenAPIDocument(spec []byte) (*openAPIDocument, error) {
+	var root map[string]any
+	if err := json.Unmarshal(spec, &root); err != nil {
+		// Not JSON: decode as YAML and round-trip through JSON so numbers
+		// and maps have the same types as in a JSON document.
+		var doc any
+		if err := yaml.Unmarshal(spec, &doc); err != nil {
+			return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+		}
+		root = nil
+		if err := json.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+		}
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q: only 3.x documents are supported", version)
+	}
+	return &openAPIDocument{root: root}, nil
+}
+
+// serverURL returns the first server URL with its variables set to their
+// defaults, or "" when the document lists no servers.
+func (d *openAPIDocument) serverURL() string {
+	servers, _ := d.root["servers"].([]any)
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]any)
+	u, _ := server["url"].(string)
+	if vars, ok := server["variables"].(map[string]any); ok {
+		for name, raw := range vars {
+			if v, ok := raw.(map[string]any); ok {
+				if def, ok := v["default"].(string); ok {
+					u = strings.ReplaceAll(u, "{"+name+"}", def)
+				}
+			}
+		}
+	}
+	return strings.TrimRight(u, "/")
+}
+
+// lookup resolves a local JSON pointer such as "#/components/schemas/Pet".
+func (d *openAPIDocument) lookup(ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported reference %q: only local references are supported", ref)
+	}
+	var current any = d.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference %q", ref)
+		}
+		if current, ok = obj[token]; !ok {
+			return nil, fmt.Errorf("unresolved reference %q", ref)
+		}
+	}
+	return current, nil
+}
+
+// resolve follows $ref chains for non-schema objects (path items,
+// parameters, request bodies and responses).
+func (d *openAPIDocument) resolve(v any) (map[string]any, error) {
+	for depth := 0; depth < 32; depth++ {
+		obj, _ := v.(map[string]any)
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj, nil
+		}
+		target, err := d.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		v = target
+	}
+	return nil, fmt.Errorf("reference chain too deep")
+}
+
+// parameters merges path-level and operation-level parameter lists.
+func (d *openAPIDocument) parameters(lists ...any) ([]map[string]any, error) {
+	var params []map[string]any
+	index := make(map[string]int)
+	for _, list := range lists {
+		items, _ := list.([]any)
+		for _, item := range items {
+			param, err := d.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			key := in + "\x00" + name
+			if i, ok := index[key]; ok {
+				params[i] = param
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, param)
+		}
+	}
+	return params, nil
+}
+
+// parameterSchema returns the JSON Schema for a parameter. Parameters declared
+// with content instead of schema are sent as JSON text, so asJSON is true.
+func (d *openAPIDocument) parameterSchema(param map[string]any) (schema map[string]any, asJSON bool, err error) {
+	if raw, ok := param["schema"]; ok {
+		schema, err = d.schema(raw)
+		return schema, false, err
+	}
+	if content, ok := param["content"].(map[string]any); ok {
+		if _, media := selectOpenAPIMediaType(content, false); media != nil {
+			schema, err = d.mediaSchema(media)
+			return schema, true, err
+		}
+	}
+	return map[string]any{"type": "string"}, false, nil
+}
+
+func (d *openAPIDocument) mediaSchema(media map[string]any) (map[string]any, error) {
+	if raw, ok := media["schema"]; ok {
+		return d.schema(raw)
+	}
+	return map[string]any{}, nil
+}
+
+// outputSchema returns the schema of the first 2xx JSON response when it
+// describes an object, as required for a tool output schema.
+func (d *openAPIDocument) outputSchema(raw any) (map[string]any, error) {
+	responses, _ := raw.(map[string]any)
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		resp, err := d.resolve(responses[code])
+		if err != nil {
+			return nil, fmt.Errorf("response %s: %w", code, err)
+		}
+		content, _ := resp["content"].(map[string]any)
+		contentType, media := selectOpenAPIMediaType(content, false)
+		if contentType == "" || media["schema"] == nil {
+			continue
+		}
+		schema, err := d.mediaSchema(media)
+		if err != nil {
+			return nil, fmt.Errorf("response %s: %w", code, err)
+		}
+		if schema["type"] == "object" {
+			return schema, nil
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
+// schema converts an OpenAPI schema object to a self-contained JSON Schema.
+func (d *openAPIDocument) schema(raw any) (map[string]any, error) {
+	converted, err := d.convertSchema(raw, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+	schema, ok := converted.(map[string]any)
+	if !ok {
+		return map[string]any{}, nil
+	}
+	return schema, nil
+}
+
+// openAPIOnlyKeywords are schema keywords with no JSON Schema meaning that are
+// dropped from generated tool schemas.
+var openAPIOnlyKeywords = map[string]bool{
+	"discriminator": true,
+	"xml":           true,
+	"externalDocs":  true,
+	"example":       true,
+}
+
+// convertSchema copies a schema, inlining local references (recursive
+// references are cut off as a plain object) and rewriting OpenAPI 3.0
+// keywords: nullable becomes a "null" type, and boolean exclusiveMinimum /
+// exclusiveMaximum become their numeric JSON Schema form.
+func (d *openAPIDocument) convertSchema(raw any, visiting map[string]bool) (any, error) {
+	switch v := raw.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			if visiting[ref] {
+				return map[string]any{"type": "object"}, nil
+			}
+			target, err := d.lookup(ref)
+			if err != nil {
+				return nil, err
+			}
+			visiting[ref] = true
+			resolved, err := d.convertSchema(target, visiting)
+			delete(visiting, ref)
+			if err != nil {
+				return nil, err
+			}
+			// OpenAPI 3.1 allows keywords such as description next to $ref.
+			if obj, ok := resolved.(map[string]any); ok && len(v) > 1 {
+				for key, value := range v {
+					if key != "$ref" && !openAPIOnlyKeywords[key] {
+						obj[key] = value
+					}
+				}
+			}
+			return resolved, nil
+		}
+
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			switch {
+			case openAPIOnlyKeywords[key]:
+			case key == "enum" || key == "const" || key == "default" || key == "examples":
+				out[key] = value
+			case key == "properties" || key == "patternProperties" || key == "$defs" || key == "definitions":
+				// Maps of name to schema: the names are not keywords.
+				schemas, _ := value.(map[string]any)
+				converted := make(map[string]any, len(schemas))
+				for name, schema := range schemas {
+					c, err := d.convertSchema(schema, visiting)
+					if err != nil {
+						return nil, err
+					}
+					converted[name] = c
+				}
+				out[key] = converted
+			default:
+				converted, err := d.convertSchema(value, visiting)
+				if err != nil {
+					return nil, err
+				}
+				out[key] = converted
+			}
+		}
+
+		if nullable, _ := out["nullable"].(bool); nullable {
+			if t, ok := out["type"].(string); ok {
+				out["type"] = []any{t, "null"}
+			}
+		}
+		delete(out, "nullable")
+		for _, bound := range [][2]string{{"exclusiveMinimum", "minimum"}, {"exclusiveMaximum", "maximum"}} {
+			exclusive, ok := out[bound[0]].(bool)
+			if !ok {
+				continue
+			}
+			delete(out, bound[0])
+			if limit, ok := out[bound[1]]; ok && exclusive {
+				out[bound[0]] = limit
+				delete(out, bound[1])
+			}
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			converted, err := d.convertSchema(item, visiting)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = converted
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
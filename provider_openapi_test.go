@@ -0,0 +1,374 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const petstoreSpec = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: "1.0"
+servers:
+  - url: https://{host}/v1
+    variables:
+      host:
+        default: pets.example.com
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      operationId: getPet
+      summary: Get a pet
+      tags: [pets]
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+        - name: X-Trace
+          in: header
+          schema:
+            type: string
+      responses:
+        "200":
+          description: The pet
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+        "404":
+          description: Not found
+    delete:
+      responses:
+        "204":
+          description: Deleted
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+  /upload:
+    post:
+      operationId: upload
+      requestBody:
+        content:
+          application/octet-stream:
+            schema:
+              type: string
+              format: binary
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          example: Rex
+        age:
+          type: integer
+          minimum: 0
+          exclusiveMinimum: true
+        tag:
+          type: string
+          nullable: true
+        parent:
+          $ref: "#/components/schemas/Pet"
+`
+
+func openAPITools(t *testing.T, provider *OpenAPIProvider) map[string]MCPTool {
+	t.Helper()
+	tools, err := provider.GetTools(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]MCPTool)
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	return byName
+}
+
+func TestOpenAPIProviderGeneratesTools(t *testing.T) {
+	provider, err := NewOpenAPIProvider([]byte(petstoreSpec), OpenAPIProviderConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.baseURL != "https://pets.example.com/v1" {
+		t.Errorf("server variables not applied: %q", provider.baseURL)
+	}
+
+	tools := openAPITools(t, provider)
+	if len(tools) != 3 {
+		t.Fatalf("expected getPet, delete_pets_petId and createPet, got %v", tools)
+	}
+	if _, ok := tools["upload"]; ok {
+		t.Error("operations with unsupported bodies should be skipped")
+	}
+
+	get := tools["getPet"]
+	if get.Description != "Get a pet" || !reflect.DeepEqual(get.Keywords, []string{"openapi", "pets"}) {
+		t.Errorf("unexpected description or keywords: %q %v", get.Description, get.Keywords)
+	}
+	input := get.InputSchema.(map[string]any)
+	props := input["properties"].(map[string]any)
+	if props["petId"].(map[string]any)["type"] != "integer" || props["fields"] == nil || props["X-Trace"] == nil {
+		t.Errorf("unexpected properties: %v", props)
+	}
+	if !reflect.DeepEqual(input["required"], []string{"petId"}) {
+		t.Errorf("path parameters should be required: %v", input["required"])
+	}
+
+	output := get.OutputSchema.(map[string]any)
+	pet := output["properties"].(map[string]any)
+	if _, ok := pet["name"].(map[string]any)["example"]; ok {
+		t.Error("example should be dropped")
+	}
+	if age := pet["age"].(map[string]any); age["exclusiveMinimum"] != 0.0 || age["minimum"] != nil {
+		t.Errorf("boolean exclusiveMinimum not converted: %v", age)
+	}
+	if tag := pet["tag"].(map[string]any); !reflect.DeepEqual(tag["type"], []any{"string", "null"}) {
+		t.Errorf("nullable not converted: %v", tag)
+	}
+	if !reflect.DeepEqual(pet["parent"], map[string]any{"type": "object"}) {
+		t.Errorf("recursive reference should be cut off: %v", pet["parent"])
+	}
+
+	if _, ok := tools["delete_pets_petId"]; !ok {
+		t.Error("operations without operationId should be named from the method and path")
+	}
+
+	create := tools["createPet"]
+	if create.OutputSchema != nil {
+		t.Error("array responses should not produce an output schema")
+	}
+	createInput := create.InputSchema.(map[string]any)
+	if !reflect.DeepEqual(createInput["required"], []string{"body"}) {
+		t.Errorf("required body not marked: %v", createInput["required"])
+	}
+}
+
+func TestOpenAPIProviderExecutesCalls(t *testing.T) {
+	var lastRequest *http.Request
+	var lastBody string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		data, _ := io.ReadAll(r.Body)
+		lastBody = string(data)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/pets/7":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"Rex","age":3}`))
+		case r.Method == http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"no such pet"}`))
+		case r.Method == http.MethodPost:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`[{"name":"Rex"}]`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer api.Close()
+
+	provider, err := NewOpenAPIProvider([]byte(petstoreSpec), OpenAPIProviderConfig{
+		BaseURL:   api.URL,
+		Namespace: "pets",
+		Auth:      NewBearerTokenAuth("secret"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	resp, err := provider.ExecuteTool(ctx, "pets__getPet", map[string]any{
+		"petId":   float64(7),
+		"fields":  []any{"name", "age"},
+		"X-Trace": "abc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastRequest.URL.RawQuery != "fields=name&fields=age" || lastRequest.Header.Get("X-Trace") != "abc" {
+		t.Errorf("unexpected request: %s %v", lastRequest.URL, lastRequest.Header)
+	}
+	if lastRequest.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("auth header missing: %v", lastRequest.Header)
+	}
+	if !reflect.DeepEqual(resp.StructuredContent, map[string]any{"name": "Rex", "age": 3.0}) || len(resp.Content) != 1 {
+		t.Errorf("object responses should be structured: %+v", resp)
+	}
+
+	resp, err = provider.ExecuteTool(ctx, "pets__getPet", map[string]any{"petId": float64(8)})
+	if err != nil || !resp.IsError || !strings.Contains(resp.Content[0].Text, "404") ||
+		!strings.Contains(resp.Content[0].Text, "no such pet") {
+		t.Fatalf("expected an isError result with the status and body, got %v %+v", err, resp)
+	}
+
+	tools, _ := provider.GetTools(ctx)
+	tools[0].Name = "changed"
+	if tools, _ := provider.GetTools(ctx); tools[0].Name == "changed" {
+		t.Error("GetTools should return a copy")
+	}
+
+	resp, err = provider.ExecuteTool(ctx, "pets__createPet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent map[string]any
+	if json.Unmarshal([]byte(lastBody), &sent) != nil || sent["name"] != "Rex" || lastRequest.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("body not sent as JSON: %q", lastBody)
+	}
+	if resp.StructuredContent != nil || resp.Content[0].Text != `[{"name":"Rex"}]` {
+		t.Errorf("array responses should be text: %+v", resp)
+	}
+
+	resp, err = provider.ExecuteTool(ctx, "pets__delete_pets_petId", map[string]any{"petId": float64(7)})
+	if err != nil || !strings.HasPrefix(resp.Content[0].Text, "204") {
+		t.Errorf("empty responses should report the status: %v %+v", err, resp)
+	}
+
+	if _, err := provider.ExecuteTool(ctx, "pets__getPet", map[string]any{}); err == nil {
+		t.Error("missing path parameters should fail")
+	}
+	if resp, err := provider.ExecuteTool(ctx, "other", nil); resp != nil || err != nil {
+		t.Errorf("unknown tools should be a miss, got %v %v", resp, err)
+	}
+}
+
+type refreshingAuth struct {
+	token     atomic.Value
+	refreshes atomic.Int32
+}
+
+func (a *refreshingAuth) GetAuthHeader() (string, error) {
+	return "Bearer " + a.token.Load().(string), nil
+}
+
+func (a *refreshingAuth) Refresh() error {
+	a.refreshes.Add(1)
+	a.token.Store("fresh")
+	return nil
+}
+
+func TestOpenAPIProviderRefreshesOnUnauthorized(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer api.Close()
+
+	auth := &refreshingAuth{}
+	auth.token.Store("stale")
+	provider, err := NewOpenAPIProvider([]byte(petstoreSpec), OpenAPIProviderConfig{BaseURL: api.URL, Auth: auth})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := provider.ExecuteTool(context.Background(), "getPet", map[string]any{"petId": float64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content[0].Text != "ok" || auth.refreshes.Load() != 1 {
+		t.Errorf("expected one refresh and a retried call, got %q after %d refreshes", resp.Content[0].Text, auth.refreshes.Load())
+	}
+}
+
+func TestOpenAPIProviderCapsResponseSize(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer api.Close()
+
+	for limit, ok := range map[int64]bool{100: true, 99: false} {
+		provider, err := NewOpenAPIProvider([]byte(petstoreSpec), OpenAPIProviderConfig{BaseURL: api.URL, MaxResponseBytes: limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = provider.ExecuteTool(context.Background(), "getPet", map[string]any{"petId": float64(1)})
+		if (err == nil) != ok {
+			t.Errorf("limit %d: got %v", limit, err)
+		}
+	}
+}
+
+func TestOpenAPIProviderThroughServer(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"Rex"}`))
+	}))
+	defer api.Close()
+
+	provider, err := NewOpenAPIProvider([]byte(petstoreSpec), OpenAPIProviderConfig{
+		BaseURL: api.URL,
+		OperationFilter: func(op OpenAPIOperation) bool {
+			return op.Method == http.MethodGet
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(openAPITools(t, provider)) != 1 {
+		t.Fatal("operation filter not applied")
+	}
+
+	server := NewServer("test", "1.0")
+	ctx := WithToolProviders(context.Background(), provider)
+	if _, err := server.CallTool(ctx, "getPet", map[string]any{"petId": "seven"}); err == nil {
+		t.Error("arguments should be validated against the generated schema")
+	}
+	resp, err := server.CallTool(ctx, "getPet", map[string]any{"petId": float64(7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StructuredContent == nil {
+		t.Error("expected structured content")
+	}
+}
+
+func TestOpenAPIProviderRejectsInvalidDocuments(t *testing.T) {
+	if _, err := NewOpenAPIProvider([]byte(`{"swagger": "2.0"}`), OpenAPIProviderConfig{}); err == nil {
+		t.Error("Swagger 2.0 documents should be rejected")
+	}
+	spec := `{"openapi": "3.1.0", "paths": {"/a": {"get": {"parameters": [{"$ref": "other.yaml#/p"}]}}}}`
+	if _, err := NewOpenAPIProvider([]byte(spec), OpenAPIProviderConfig{}); err == nil || !strings.Contains(err.Error(), "only local references") {
+		t.Errorf("external references should be rejected, got %v", err)
+	}
+}
@@ -2,8 +2,11 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/paularlott/mcp/pool"
@@ -13,6 +16,11 @@ import (
 // when a RemoteProviderConfig does not specify CacheTTL.
 const DefaultRemoteToolCacheTTL = 60 * time.Second
 
+// DefaultRemoteToolStaleTTL is how long past its CacheTTL a remote tool list
+// may still be served while the server cannot be reached, when a
+// RemoteProviderConfig does not specify StaleTTL.
+const DefaultRemoteToolStaleTTL = time.Hour
+
 // AuthResolver lazily resolves the auth provider for a remote server for the
 // current request. It is only called when the provider actually needs to talk
 // to the server (listing or calling a tool), so per-user token lookups are not
@@ -29,9 +37,35 @@ type RemoteProviderConfig struct {
 	// It must be unique within a single resolver result.
 	Name string
 
-	// URL is the remote MCP server endpoint.
+	// Separator joins Name to the server's tool and prompt names. Empty uses
+	// DefaultNamespaceSeparator.
+	Separator string
+
+	// URL is the remote MCP server endpoint. Leave it empty when Stdio is set.
 	URL string
 
+	// Replicas lists further endpoints serving the same server as URL.
+	// Requests are balanced over all of them with passive health tracking, as
+	// NewReplicatedClient describes; the health state is kept across requests.
+	Replicas []string
+
+	// ReplicaSelection chooses among URL and Replicas. The default is
+	// ReplicaRoundRobin.
+	ReplicaSelection ReplicaSelection
+
+	// Stdio runs the server as a local process instead of reaching it over
+	// HTTP. The process is started on first use, stopped when idle and
+	// restarted after a crash (see NewManagedStdioClient); Auth, AuthFunc and
+	// HTTPPool are not used. Processes are shared by every request with the
+	// same ProcessKey and live until the provider is closed.
+	Stdio *StdioServerConfig
+
+	// ProcessKey identifies the process serving a Stdio server. Defaults to
+	// Name plus the command line, directory and environment, so requests that
+	// resolve the same configuration share one process. Include a user or
+	// tenant identifier to give each their own.
+	ProcessKey string
+
 	// Auth is a static auth provider for the server. Ignored when AuthFunc is set.
 	// May be nil for unauthenticated servers.
 	Auth AuthProvider
@@ -51,12 +85,20 @@ type RemoteProviderConfig struct {
 	// on both the list and call paths. Nil means expose all tools.
 	ToolFilter ToolFilterFunc
 
-	// CacheTTL is how long this server's tool list is cached. Zero uses
+	// Transforms renames tools, overrides descriptions and hides or fixes
+	// parameters (see ToolTransform), keyed by original tool name. The
+	// advertised schemas are rewritten and calls are mapped back. Nil leaves
+	// tools unchanged. Transforms that fail Validate make the server's
+	// requests fail.
+	Transforms ToolTransforms
+
+	// CacheTTL is how long this server's tool, resource and prompt lists are
+	// cached. Zero uses
 	// DefaultRemoteToolCacheTTL. Negative disables caching.
 	CacheTTL time.Duration
 
 	// CacheKey overrides the cache key for this server's tool list. Defaults to
-	// Name + "\x00" + URL. Set this to include a user/tenant identifier when tool
+	// Name + "\x00" + URL, or the ProcessKey for a Stdio server. Set this to include a user/tenant identifier when tool
 	// catalogs differ per user and must not be shared.
 	//
 	// The cache is bounded: it holds at most a fixed number of entries (see
@@ -66,6 +108,18 @@ type RemoteProviderConfig struct {
 	// shorter CacheTTL or a larger max so active users are not evicted too soon.
 	CacheKey string
 
+	// StaleTTL is how long past CacheTTL the last tool list fetched is still
+	// served while the server cannot be reached or its circuit is open
+	// (stale-while-revalidate). Zero uses DefaultRemoteToolStaleTTL; negative
+	// serves nothing stale.
+	StaleTTL time.Duration
+
+	// CircuitBreaker configures the server's circuit breaker (see
+	// CircuitBreakerConfig). Nil uses the defaults; a negative
+	// FailureThreshold disables it. The breaker is shared by every request
+	// reaching the same server and is configured by the first of them.
+	CircuitBreaker *CircuitBreakerConfig
+
 	// HTTPPool optionally provides a custom HTTP pool (e.g. for self-signed
 	// internal services). Nil uses the default secure pool.
 	HTTPPool pool.HTTPPool
@@ -83,7 +137,10 @@ type RemoteProviderConfig struct {
 type RemoteProviderResolver func(ctx context.Context) ([]RemoteProviderConfig, error)
 
 // RemoteProvider is a request-scoped ToolProvider that exposes tools from one
-// or more remote MCP servers. It is intended to be created once and reused for
+// or more remote MCP servers. It is also a ResourceProvider and PromptProvider
+// for the servers' resources and prompts, namespaced like the tools. Servers
+// may be reached over HTTP or run as local processes (see
+// RemoteProviderConfig.Stdio). It is intended to be created once and reused for
 // the lifetime of the process: it resolves the per-request server set from the
 // context, so a single instance safely serves many users without leaking tools
 // between them. Reusing one instance also lets its tool-list cache persist
@@ -95,14 +152,37 @@ type RemoteProviderResolver func(ctx context.Context) ([]RemoteProviderConfig, e
 //	})
 //	// per request:
 //	ctx := mcp.WithToolProviders(r.Context(), provider)
+//	ctx = mcp.WithResourceProviders(ctx, provider) // optional
+//	ctx = mcp.WithPromptProviders(ctx, provider)   // optional
 //	server.HandleRequest(w, r.WithContext(ctx))
 type RemoteProvider struct {
-	resolve RemoteProviderResolver
-	cache   *remoteToolCache
+	resolve   RemoteProviderResolver
+	cache     *remoteToolCache
+	resources *remoteListCache[MCPResource]         // Resource lists, keyed like cache
+	templates *remoteListCache[MCPResourceTemplate] // Resource template lists, keyed like cache
+	prompts   *remoteListCache[MCPPrompt]           // Prompt lists, keyed like cache
+
+	mu        sync.Mutex
+	processes map[string]*managedStdioTransport // Stdio servers by ProcessKey; dropped when idle
+	replicas  *lruMap[*replicaSet]              // Replicated servers by replicaKey
+	breakers  *lruMap[*remoteBreaker]           // By serverKey
 }
 
-// Ensure RemoteProvider implements ToolProvider.
-var _ ToolProvider = (*RemoteProvider)(nil)
+// remoteBreaker is the circuit breaker of one of a RemoteProvider's servers.
+type remoteBreaker struct {
+	name     string
+	endpoint string
+	breaker  *circuitBreaker // Nil when disabled
+}
+
+// Ensure RemoteProvider implements ToolProvider, ToolLookup, ResourceProvider
+// and PromptProvider.
+var (
+	_ ToolProvider     = (*RemoteProvider)(nil)
+	_ ToolLookup       = (*RemoteProvider)(nil)
+	_ ResourceProvider = (*RemoteProvider)(nil)
+	_ PromptProvider   = (*RemoteProvider)(nil)
+)
 
 // RemoteProviderOption configures a RemoteProvider.
 type RemoteProviderOption func(*remoteProviderOptions)
@@ -114,7 +194,8 @@ type remoteProviderOptions struct {
 // WithMaxCacheEntries bounds how many distinct cache keys the provider keeps
 // tool lists for. Once exceeded, the least-recently-used entry is evicted. This
 // keeps memory bounded even when CacheKey embeds a per-user/per-tenant id.
-// A value <= 0 uses DefaultRemoteToolCacheMaxEntries.
+// The same bound applies to the circuit breakers and replica health kept per
+// server. A value <= 0 uses DefaultRemoteToolCacheMaxEntries.
 func WithMaxCacheEntries(n int) RemoteProviderOption {
 	return func(o *remoteProviderOptions) {
 		o.maxCacheEntries = n
@@ -129,8 +210,13 @@ func NewRemoteProvider(resolve RemoteProviderResolver, opts ...RemoteProviderOpt
 		opt(&o)
 	}
 	return &RemoteProvider{
-		resolve: resolve,
-		cache:   newRemoteToolCache(o.maxCacheEntries),
+		resolve:   resolve,
+		cache:     newRemoteToolCache(o.maxCacheEntries),
+		resources: newRemoteListCache[MCPResource](o.maxCacheEntries),
+		templates: newRemoteListCache[MCPResourceTemplate](o.maxCacheEntries),
+		prompts:   newRemoteListCache[MCPPrompt](o.maxCacheEntries),
+		replicas:  newLRUMap[*replicaSet](o.maxCacheEntries),
+		breakers:  newLRUMap[*remoteBreaker](o.maxCacheEntries),
 	}
 }
 
@@ -138,9 +224,19 @@ func (cfg RemoteProviderConfig) cacheKey() string {
 	if cfg.CacheKey != "" {
 		return cfg.CacheKey
 	}
+	if cfg.Stdio != nil {
+		return cfg.processKey()
+	}
 	return cfg.Name + "\x00" + cfg.URL
 }
 
+func (cfg RemoteProviderConfig) processKey() string {
+	if cfg.ProcessKey != "" {
+		return cfg.ProcessKey
+	}
+	return cfg.Name + "\x00" + cfg.Stdio.key()
+}
+
 func (cfg RemoteProviderConfig) resolveAuth(ctx context.Context) (AuthProvider, error) {
 	if cfg.AuthFunc != nil {
 		return cfg.AuthFunc(ctx)
@@ -148,6 +244,34 @@ func (cfg RemoteProviderConfig) resolveAuth(ctx context.Context) (AuthProvider,
 	return cfg.Auth, nil
 }
 
+// serverKey identifies the server cfg reaches, whichever user it is for.
+func (cfg RemoteProviderConfig) serverKey() string {
+	if cfg.Stdio != nil {
+		return cfg.processKey()
+	}
+	return cfg.replicaKey()
+}
+
+// replicaKey identifies a replicated server's replica set.
+func (cfg RemoteProviderConfig) replicaKey() string {
+	return strings.Join(append([]string{cfg.Name, cfg.URL}, cfg.Replicas...), "\x00")
+}
+
+// separator returns the separator between Name and the server's tool and
+// prompt names.
+func (cfg RemoteProviderConfig) separator() string {
+	if cfg.Separator != "" {
+		return cfg.Separator
+	}
+	return DefaultNamespaceSeparator
+}
+
+// remoteName returns name without the server's namespace, and whether name
+// carries that namespace.
+func (cfg RemoteProviderConfig) remoteName(name string) (string, bool) {
+	return strings.CutPrefix(name, cfg.Name+cfg.separator())
+}
+
 func (cfg RemoteProviderConfig) newClient(auth AuthProvider) *Client {
 	if cfg.HTTPPool != nil {
 		return NewClientWithPool(cfg.URL, auth, cfg.Name, cfg.HTTPPool)
@@ -155,6 +279,155 @@ func (cfg RemoteProviderConfig) newClient(auth AuthProvider) *Client {
 	return NewClient(cfg.URL, auth, cfg.Name)
 }
 
+// connect resolves auth for the request and creates a client for the server
+// with its tool filter applied. A Stdio server's client shares the process
+// kept for its ProcessKey, starting one if there is none, and a replicated
+// server's client shares the health of its replicas with other requests.
+func (p *RemoteProvider) connect(ctx context.Context, cfg RemoteProviderConfig) (*Client, error) {
+	if err := cfg.Transforms.Validate(); err != nil {
+		return nil, fmt.Errorf("remote %q: %w", cfg.Name, err)
+	}
+	var client *Client
+	if cfg.Stdio != nil {
+		client = newSharedStdioClient(p.process(cfg), cfg.Name)
+	} else {
+		auth, err := cfg.resolveAuth(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolve auth for %q: %w", cfg.Name, err)
+		}
+		if len(cfg.Replicas) > 0 {
+			client = p.replicaSet(cfg).client(auth, cfg.Name)
+		} else {
+			client = cfg.newClient(auth)
+		}
+	}
+	if sep := cfg.separator(); sep != client.separator {
+		client.namespace = cfg.Name + sep
+		client.separator = sep
+	}
+	if cfg.ToolFilter != nil {
+		client.WithToolFilter(cfg.ToolFilter)
+	}
+	if cfg.Transforms != nil {
+		client.WithToolTransforms(cfg.Transforms)
+	}
+	if breaker := p.breaker(cfg); breaker != nil {
+		client.breaker.Store(breaker)
+	}
+	return client, nil
+}
+
+// process returns the transport of the process for cfg's ProcessKey, creating
+// it if need be. The process itself starts on the first request. Once the
+// process stops for idleness the transport is forgotten, so keys no longer
+// used do not accumulate.
+func (p *RemoteProvider) process(cfg RemoteProviderConfig) *managedStdioTransport {
+	key := cfg.processKey()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.processes[key]; ok {
+		return t
+	}
+	if p.processes == nil {
+		p.processes = make(map[string]*managedStdioTransport)
+	}
+	t := newManagedStdioTransport(*cfg.Stdio, nil)
+	t.onIdle = func() { p.forgetProcess(key, t) }
+	p.processes[key] = t
+	return t
+}
+
+// forgetProcess drops the transport kept for key if it is still t. A request
+// that fetched t just before may start its process again; that process stops
+// when idle as before, but is no longer stopped by Close.
+func (p *RemoteProvider) forgetProcess(key string, t *managedStdioTransport) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.processes[key] == t {
+		delete(p.processes, key)
+	}
+}
+
+// replicaSet returns the replica set of a replicated server, creating it if
+// need be.
+func (p *RemoteProvider) replicaSet(cfg RemoteProviderConfig) *replicaSet {
+	key := cfg.replicaKey()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if set, ok := p.replicas.get(key); ok {
+		return set
+	}
+	set := newReplicaSet(append([]string{cfg.URL}, cfg.Replicas...),
+		WithReplicaSelection(cfg.ReplicaSelection), WithReplicaHTTPPool(cfg.HTTPPool))
+	p.replicas.put(key, set)
+	return set
+}
+
+// breaker returns the circuit breaker of cfg's server, creating it if need
+// be, or nil if it is disabled.
+func (p *RemoteProvider) breaker(cfg RemoteProviderConfig) *circuitBreaker {
+	key := cfg.serverKey()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if rb, ok := p.breakers.get(key); ok {
+		return rb.breaker
+	}
+	var breakerCfg CircuitBreakerConfig
+	if cfg.CircuitBreaker != nil {
+		breakerCfg = *cfg.CircuitBreaker
+	}
+	endpoint := cfg.URL
+	if cfg.Stdio != nil {
+		endpoint = cfg.Stdio.commandLine()
+	}
+	rb := &remoteBreaker{name: cfg.Name, endpoint: endpoint, breaker: newCircuitBreaker(breakerCfg)}
+	p.breakers.put(key, rb)
+	return rb.breaker
+}
+
+// CircuitStatus reports the circuit breakers of the servers the provider has
+// reached, sorted by name then endpoint. Only the most recently used servers
+// are kept, up to the WithMaxCacheEntries bound.
+func (p *RemoteProvider) CircuitStatus() []CircuitStatus {
+	p.mu.Lock()
+	statuses := make([]CircuitStatus, 0, p.breakers.len())
+	for _, rb := range p.breakers.values() {
+		if rb.breaker == nil {
+			continue
+		}
+		status := rb.breaker.status()
+		status.Name, status.Endpoint = rb.name, rb.endpoint
+		statuses = append(statuses, status)
+	}
+	p.mu.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Name != statuses[j].Name {
+			return statuses[i].Name < statuses[j].Name
+		}
+		return statuses[i].Endpoint < statuses[j].Endpoint
+	})
+	return statuses
+}
+
+// Close stops the processes of all Stdio servers the provider has started.
+// It does nothing for HTTP servers. A Stdio server used after Close gets a
+// new process.
+func (p *RemoteProvider) Close() error {
+	p.mu.Lock()
+	processes := p.processes
+	p.processes = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, t := range processes {
+		if err := t.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // resolveServers resolves the request's remote servers, memoized for the
 // lifetime of the request context so the resolver's I/O (e.g. a DB lookup) runs
 // once even though the server queries providers several times per request.
@@ -194,41 +467,54 @@ func (p *RemoteProvider) GetTools(ctx context.Context) ([]MCPTool, error) {
 }
 
 // toolsForServer returns the (namespaced, filtered, visibility-tagged) tools for
-// a single server, using the cached list when still valid.
+// a single server, using the cached list when still valid, or when the server
+// cannot be reached and the list is not too stale.
 func (p *RemoteProvider) toolsForServer(ctx context.Context, cfg RemoteProviderConfig) ([]MCPTool, error) {
+	return cachedList(ctx, p, p.cache, cfg, func(client *Client) ([]MCPTool, error) {
+		remoteTools, err := client.ListTools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list tools for %q: %w", cfg.Name, err)
+		}
+
+		visibility := cfg.Visibility
+		keywords := append([]string{cfg.Name, "remote"}, cfg.Keywords...)
+
+		tools := make([]MCPTool, 0, len(remoteTools))
+		for _, tool := range remoteTools {
+			tool.Visibility = visibility
+			tool.Keywords = keywords
+			tools = append(tools, tool)
+		}
+		return tools, nil
+	})
+}
+
+// cachedList returns a list fetched from cfg's server, using the list cached
+// under its cache key when still valid, or when fetch fails and the list is
+// not too stale. Errors connecting are returned without serving anything
+// stale, so a request whose auth is refused gets nothing.
+func cachedList[T any](ctx context.Context, p *RemoteProvider, cache *remoteListCache[T], cfg RemoteProviderConfig, fetch func(client *Client) ([]T, error)) ([]T, error) {
 	key := cfg.cacheKey()
 	caching := cfg.CacheTTL >= 0
 	now := time.Now()
 
 	if caching {
-		if tools, ok := p.cache.get(key, now); ok {
-			return tools, nil
+		if values, ok := cache.get(key, now); ok {
+			return values, nil
 		}
 	}
 
-	auth, err := cfg.resolveAuth(ctx)
+	client, err := p.connect(ctx, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("resolve auth for %q: %w", cfg.Name, err)
-	}
-
-	client := cfg.newClient(auth)
-	if cfg.ToolFilter != nil {
-		client.WithToolFilter(cfg.ToolFilter)
+		return nil, err
 	}
 
-	remoteTools, err := client.ListTools(ctx)
+	values, err := fetch(client)
 	if err != nil {
-		return nil, fmt.Errorf("list tools for %q: %w", cfg.Name, err)
-	}
-
-	visibility := cfg.Visibility
-	keywords := append([]string{cfg.Name, "remote"}, cfg.Keywords...)
-
-	tools := make([]MCPTool, 0, len(remoteTools))
-	for _, tool := range remoteTools {
-		tool.Visibility = visibility
-		tool.Keywords = keywords
-		tools = append(tools, tool)
+		if stale, ok := cache.getStale(key, now); ok && caching {
+			return stale, nil
+		}
+		return nil, err
 	}
 
 	if caching {
@@ -236,40 +522,60 @@ func (p *RemoteProvider) toolsForServer(ctx context.Context, cfg RemoteProviderC
 		if ttl == 0 {
 			ttl = DefaultRemoteToolCacheTTL
 		}
-		p.cache.put(key, tools, ttl, now)
+		staleTTL := cfg.StaleTTL
+		if staleTTL == 0 {
+			staleTTL = DefaultRemoteToolStaleTTL
+		}
+		cache.putWithStale(key, values, ttl, staleTTL, now)
 	}
 
-	return tools, nil
+	return values, nil
+}
+
+// LookupTool returns the tool called name from the one remote server its
+// namespace names, using that server's cached tool list, or nil if the server
+// does not list it.
+func (p *RemoteProvider) LookupTool(ctx context.Context, name string) (*MCPTool, error) {
+	servers, err := p.resolveServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range servers {
+		if _, ok := cfg.remoteName(name); !ok {
+			continue
+		}
+		tools, err := p.toolsForServer(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		for i := range tools {
+			if tools[i].Name == name {
+				tool := tools[i]
+				return &tool, nil
+			}
+		}
+		return nil, nil
+	}
+	return nil, nil
 }
 
 // ExecuteTool dispatches a namespaced tool call to the owning remote server.
 // Returns ErrUnknownTool when the tool is not a namespaced tool belonging to one
 // of this request's servers, so other providers can handle it.
 func (p *RemoteProvider) ExecuteTool(ctx context.Context, name string, params map[string]any) (*ToolResponse, error) {
-	idx := strings.Index(name, DefaultNamespaceSeparator)
-	if idx < 0 {
-		return nil, ErrUnknownTool
-	}
-	namespace := name[:idx]
-
 	servers, err := p.resolveServers(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, cfg := range servers {
-		if cfg.Name != namespace {
+		if _, ok := cfg.remoteName(name); !ok {
 			continue
 		}
 
-		auth, err := cfg.resolveAuth(ctx)
+		client, err := p.connect(ctx, cfg)
 		if err != nil {
-			return nil, fmt.Errorf("resolve auth for %q: %w", cfg.Name, err)
-		}
-
-		client := cfg.newClient(auth)
-		if cfg.ToolFilter != nil {
-			client.WithToolFilter(cfg.ToolFilter)
+			return nil, err
 		}
 
 		result, err := client.CallTool(ctx, name, params)
@@ -282,14 +588,150 @@ func (p *RemoteProvider) ExecuteTool(ctx context.Context, name string, params ma
 	return nil, ErrUnknownTool
 }
 
-// InvalidateCache removes the cached tool list for a single server. The key must
-// match the server's CacheKey (or, when CacheKey is unset, Name + "\x00" + URL).
-// Call this when a server's configuration or tool set changes.
+// GetResources returns the resources and resource templates of the request's
+// remote servers, with URIs namespaced by server name (a Name of "github"
+// exposes "repo://x" as "github+repo://x") and the server's visibility.
+// Both lists are cached like tool lists (see CacheTTL and StaleTTL). Servers
+// that fail to respond are skipped.
+func (p *RemoteProvider) GetResources(ctx context.Context) (*ProvidedResources, error) {
+	servers, err := p.resolveServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	provided := &ProvidedResources{}
+	for _, cfg := range servers {
+		resources, err := cachedList(ctx, p, p.resources, cfg, func(client *Client) ([]MCPResource, error) {
+			resources, err := client.ListResources(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for i := range resources {
+				resources[i].URI = namespaceURI(cfg.Name, resources[i].URI)
+				resources[i].Visibility = cfg.Visibility
+			}
+			return resources, nil
+		})
+		if err == nil {
+			provided.Resources = append(provided.Resources, resources...)
+		}
+		templates, err := cachedList(ctx, p, p.templates, cfg, func(client *Client) ([]MCPResourceTemplate, error) {
+			templates, err := client.ListResourceTemplates(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for i := range templates {
+				templates[i].URITemplate = namespaceURI(cfg.Name, templates[i].URITemplate)
+				templates[i].Visibility = cfg.Visibility
+			}
+			return templates, nil
+		})
+		if err == nil {
+			provided.Templates = append(provided.Templates, templates...)
+		}
+	}
+	return provided, nil
+}
+
+// ReadResource reads a namespaced resource from the owning remote server.
+// Returns ErrUnknownResource when the URI does not carry the namespace of one
+// of this request's servers.
+func (p *RemoteProvider) ReadResource(ctx context.Context, uri string) (*ResourceResponse, error) {
+	namespace, remoteURI, ok := strings.Cut(uri, ResourceNamespaceSeparator)
+	if !ok {
+		return nil, ErrUnknownResource
+	}
+
+	servers, err := p.resolveServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range servers {
+		if cfg.Name != namespace {
+			continue
+		}
+		client, err := p.connect(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.ReadResource(ctx, remoteURI)
+		if err != nil {
+			return nil, err
+		}
+		return namespaceResourceResponse(cfg.Name, resp), nil
+	}
+	return nil, ErrUnknownResource
+}
+
+// GetPrompts returns the prompts of the request's remote servers, with names
+// namespaced like tools and the server's visibility. Prompt lists are cached
+// like tool lists (see CacheTTL and StaleTTL). Servers that fail to respond
+// are skipped.
+func (p *RemoteProvider) GetPrompts(ctx context.Context) ([]MCPPrompt, error) {
+	servers, err := p.resolveServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompts []MCPPrompt
+	for _, cfg := range servers {
+		serverPrompts, err := cachedList(ctx, p, p.prompts, cfg, func(client *Client) ([]MCPPrompt, error) {
+			prompts, err := client.ListPrompts(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for i := range prompts {
+				prompts[i].Name = cfg.Name + cfg.separator() + prompts[i].Name
+				prompts[i].Visibility = cfg.Visibility
+			}
+			return prompts, nil
+		})
+		if err != nil {
+			continue
+		}
+		prompts = append(prompts, serverPrompts...)
+	}
+	return prompts, nil
+}
+
+// GetPrompt renders a namespaced prompt on the owning remote server. Returns
+// ErrUnknownPrompt when the name does not carry the namespace of one of this
+// request's servers.
+func (p *RemoteProvider) GetPrompt(ctx context.Context, name string, args map[string]string) (*PromptResponse, error) {
+	servers, err := p.resolveServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range servers {
+		remoteName, ok := cfg.remoteName(name)
+		if !ok {
+			continue
+		}
+		client, err := p.connect(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPrompt(ctx, remoteName, args)
+	}
+	return nil, ErrUnknownPrompt
+}
+
+// InvalidateCache removes the cached tool, resource and prompt lists for a
+// single server. The key must match the server's CacheKey (or, when CacheKey
+// is unset, Name + "\x00" + URL). Call this when a server's configuration or
+// tool set changes.
 func (p *RemoteProvider) InvalidateCache(cacheKey string) {
 	p.cache.invalidate(cacheKey)
+	p.resources.invalidate(cacheKey)
+	p.templates.invalidate(cacheKey)
+	p.prompts.invalidate(cacheKey)
 }
 
-// InvalidateAllCache clears every cached remote tool list.
+// InvalidateAllCache clears every cached remote tool, resource and prompt
+// list.
 func (p *RemoteProvider) InvalidateAllCache() {
 	p.cache.clear()
+	p.resources.clear()
+	p.templates.clear()
+	p.prompts.clear()
 }
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // testUserKey is a context key used to vary the per-user cache key in tests.
@@ -126,6 +128,72 @@ func TestRemoteProvider_BoundedCacheAcrossUsers(t *testing.T) {
 	}
 }
 
+func TestRemoteProvider_BoundedServerState(t *testing.T) {
+	ts := newRemoteWithTools(func(s *Server) {
+		s.RegisterTool(NewTool("alpha", "Alpha"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+			return NewToolResponseText("a"), nil
+		})
+	})
+	defer ts.Close()
+
+	// A per-user URL gives every user their own breaker and replica set.
+	p := NewRemoteProvider(func(ctx context.Context) ([]RemoteProviderConfig, error) {
+		user, _ := ctx.Value(testUserKey{}).(string)
+		return []RemoteProviderConfig{{
+			Name:     "svc",
+			URL:      ts.URL + "/" + user,
+			Replicas: []string{ts.URL + "/" + user + "/b"},
+		}}, nil
+	}, WithMaxCacheEntries(5))
+
+	for i := 0; i < 50; i++ {
+		ctx := context.WithValue(context.Background(), testUserKey{}, fmt.Sprintf("user%d", i))
+		if _, err := p.ExecuteTool(ctx, "svc__alpha", nil); err != nil {
+			t.Fatalf("ExecuteTool for user%d: %v", i, err)
+		}
+	}
+	if p.breakers.len() > 5 || p.replicas.len() > 5 || len(p.CircuitStatus()) > 5 {
+		t.Fatalf("server state grew unbounded: %d breakers, %d replica sets", p.breakers.len(), p.replicas.len())
+	}
+}
+
+func TestRemoteProvider_ForgetsIdleProcesses(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The test binary serves stdio in this mode; see TestMain in stdio_test.go.
+	stdio := StdioServerConfig{
+		Command:     exe,
+		Env:         []string{"MCP_TEST_STDIO_CHILD=1", "MCP_TEST_STDIO_MANAGED=1"},
+		IdleTimeout: 50 * time.Millisecond,
+	}
+	p := NewRemoteProvider(resolverFor(RemoteProviderConfig{Name: "local", Stdio: &stdio}))
+	defer p.Close()
+
+	if _, err := p.ExecuteTool(context.Background(), "local__pid", nil); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		p.mu.Lock()
+		n := len(p.processes)
+		p.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("idle process was not forgotten")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The next request starts a new process.
+	if _, err := p.ExecuteTool(context.Background(), "local__pid", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func newRemoteWithTools(register func(s *Server)) *httptest.Server {
 	remote := NewServer("remote", "1")
 	register(remote)
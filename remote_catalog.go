@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// ResourceNamespaceSeparator joins a remote server's namespace to the URIs of
+// its resources, giving a URI scheme such as "github+repo://" that stays a
+// valid URI and tells the server which remote owns it.
+const ResourceNamespaceSeparator = "+"
+
+// namespaceURI prefixes a remote resource URI or URI template with namespace.
+func namespaceURI(namespace, uri string) string {
+	if namespace == "" {
+		return uri
+	}
+	return namespace + ResourceNamespaceSeparator + uri
+}
+
+// namespaceResourceResponse rewrites the URIs in a remote's response so they
+// match the namespaced URIs it was listed under.
+func namespaceResourceResponse(namespace string, resp *ResourceResponse) *ResourceResponse {
+	if resp == nil || namespace == "" {
+		return resp
+	}
+	contents := make([]ResourceContent, len(resp.Contents))
+	for i, c := range resp.Contents {
+		c.URI = namespaceURI(namespace, c.URI)
+		contents[i] = c
+	}
+	return &ResourceResponse{Contents: contents}
+}
+
+// remoteCatalog holds the resources, templates and prompts of a remote server,
+// fetched when it is registered, on RefreshTools and when the remote reports
+// a change. Resource URIs and prompt names carry the remote's namespace.
+type remoteCatalog struct {
+	client    *Client
+	namespace string // Bare namespace; "" for none
+	prefix    string // Namespace prefix on prompt names
+	resources []MCPResource
+	templates []MCPResourceTemplate
+	prompts   []MCPPrompt
+}
+
+// fetchRemoteCatalog lists the resources, templates and prompts of a remote
+// server, tagging them with its visibility. A remote that does not support
+// one of the lists contributes none of that kind.
+func fetchRemoteCatalog(ctx context.Context, rc *registeredClient) *remoteCatalog {
+	catalog := &remoteCatalog{client: rc.client, namespace: rc.namespace}
+	if rc.namespace != "" {
+		catalog.prefix = rc.namespace + rc.client.separator
+	}
+
+	if resources, err := rc.client.ListResources(ctx); err == nil {
+		for _, res := range resources {
+			res.URI = namespaceURI(rc.namespace, res.URI)
+			res.Visibility = rc.visibility
+			catalog.resources = append(catalog.resources, res)
+		}
+	}
+	if templates, err := rc.client.ListResourceTemplates(ctx); err == nil {
+		for _, tmpl := range templates {
+			tmpl.URITemplate = namespaceURI(rc.namespace, tmpl.URITemplate)
+			tmpl.Visibility = rc.visibility
+			catalog.templates = append(catalog.templates, tmpl)
+		}
+	}
+	if prompts, err := rc.client.ListPrompts(ctx); err == nil {
+		for _, prompt := range prompts {
+			prompt.Name = catalog.prefix + prompt.Name
+			prompt.Visibility = rc.visibility
+			catalog.prompts = append(catalog.prompts, prompt)
+		}
+	}
+	return catalog
+}
+
+// owns reports whether uri belongs to the remote: it carries the remote's
+// namespace, or, for a remote without one, is listed or matches a template.
+func (c *remoteCatalog) owns(uri string) bool {
+	if c.namespace != "" {
+		return strings.HasPrefix(uri, c.namespace+ResourceNamespaceSeparator)
+	}
+	for _, res := range c.resources {
+		if res.URI == uri {
+			return true
+		}
+	}
+	for _, tmpl := range c.templates {
+		if _, err := MatchResourceTemplate(tmpl.URITemplate, uri); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshRemoteCatalog re-fetches the catalog of a remote server.
+func (s *Server) refreshRemoteCatalog(ctx context.Context, rc *registeredClient) {
+	catalog := fetchRemoteCatalog(ctx, rc)
+	s.mu.Lock()
+	rc.catalog = catalog
+	s.mu.Unlock()
+}
+
+// remoteCatalogs returns the catalogs of the registered remote servers.
+func (s *Server) remoteCatalogs() []*remoteCatalog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var catalogs []*remoteCatalog
+	for _, rc := range s.remoteClients {
+		if rc.catalog != nil {
+			catalogs = append(catalogs, rc.catalog)
+		}
+	}
+	sort.Slice(catalogs, func(i, j int) bool { return catalogs[i].prefix < catalogs[j].prefix })
+	return catalogs
+}
+
+// readRemoteResource reads uri from the remote server that owns it. Returns
+// ErrUnknownResource if none does.
+func (s *Server) readRemoteResource(ctx context.Context, uri string) (*ResourceResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, catalog := range s.remoteCatalogs() {
+		if catalog.owns(uri) {
+			remoteURI := strings.TrimPrefix(uri, namespaceURI(catalog.namespace, ""))
+			resp, err := catalog.client.ReadResource(ctx, remoteURI)
+			if err != nil {
+				return nil, err
+			}
+			return namespaceResourceResponse(catalog.namespace, resp), nil
+		}
+	}
+	return nil, ErrUnknownResource
+}
+
+// getRemotePrompt renders a remote server's prompt by its namespaced name.
+// Returns ErrUnknownPrompt if no remote has it.
+func (s *Server) getRemotePrompt(ctx context.Context, name string, args map[string]string) (*PromptResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, catalog := range s.remoteCatalogs() {
+		for _, prompt := range catalog.prompts {
+			if prompt.Name == name {
+				return catalog.client.GetPrompt(ctx, strings.TrimPrefix(name, catalog.prefix), args)
+			}
+		}
+	}
+	return nil, ErrUnknownPrompt
+}
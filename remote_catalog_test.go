@@ -0,0 +1,265 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func catalogRemote(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	remote := NewServer("remote", "1.0")
+	remote.RegisterResource(NewResource("docs://guide", "guide", "User guide", "text/plain"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), "the guide", "text/plain"), nil
+		})
+	remote.RegisterResourceTemplate(NewResourceTemplate("docs://pages/{page}", "page", "A page", "text/plain"),
+		func(ctx context.Context, req *ResourceRequest) (*ResourceResponse, error) {
+			return NewResourceResponseText(req.URI(), "page "+req.URI(), "text/plain"), nil
+		})
+	remote.RegisterPrompt(NewPrompt("summarize", "Summarize a document").Argument("doc", "Document", true),
+		func(ctx context.Context, req *PromptRequest) (*PromptResponse, error) {
+			doc, _ := req.String("doc")
+			return NewPromptResponseText("Summarize " + doc), nil
+		})
+	ts := httptest.NewServer(http.HandlerFunc(remote.HandleRequest))
+	t.Cleanup(ts.Close)
+	return remote, ts
+}
+
+func TestRemoteResourcesAndPromptsAggregated(t *testing.T) {
+	_, ts := catalogRemote(t)
+	server := NewServer("gateway", "1.0")
+	server.RegisterResource(NewResource("file:///local", "local", "A local file", "text/plain"), nil)
+	if err := server.RegisterRemoteServer(NewClient(ts.URL, nil, "docs")); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	resources := server.ListResources(ctx)
+	if len(resources) != 2 || resources[0].URI != "file:///local" || resources[1].URI != "docs+docs://guide" {
+		t.Errorf("unexpected resources %+v", resources)
+	}
+	templates := server.ListResourceTemplates(ctx)
+	if len(templates) != 1 || templates[0].URITemplate != "docs+docs://pages/{page}" {
+		t.Errorf("unexpected templates %+v", templates)
+	}
+	prompts := server.ListPrompts(ctx)
+	if len(prompts) != 1 || prompts[0].Name != "docs__summarize" {
+		t.Errorf("unexpected prompts %+v", prompts)
+	}
+
+	resp, err := server.ReadResource(ctx, "docs+docs://pages/intro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := resp.Contents[0]; c.URI != "docs+docs://pages/intro" || c.Text != "page docs://pages/intro" {
+		t.Errorf("read should route to the remote and keep the namespace: %+v", c)
+	}
+	if _, err := server.ReadResource(ctx, "other+docs://guide"); err != ErrUnknownResource {
+		t.Errorf("expected ErrUnknownResource, got %v", err)
+	}
+
+	prompt, err := server.GetPrompt(ctx, "docs__summarize", map[string]string{"doc": "README"})
+	if err != nil || prompt.Messages[0].Content.Text != "Summarize README" {
+		t.Errorf("prompt should route to the remote: %+v, %v", prompt, err)
+	}
+
+	// Over the wire too
+	result := doMCP(t, server, "resources/read", map[string]any{"uri": "docs+docs://guide"})
+	if contents, _ := result["contents"].([]any); len(contents) != 1 {
+		t.Errorf("unexpected resources/read result %v", result)
+	}
+}
+
+func TestRemoteResourcesWithoutNamespace(t *testing.T) {
+	_, ts := catalogRemote(t)
+	server := NewServer("gateway", "1.0")
+	if err := server.RegisterRemoteServer(NewClient(ts.URL, nil, "")); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if resources := server.ListResources(ctx); len(resources) != 1 || resources[0].URI != "docs://guide" {
+		t.Errorf("URIs should be unchanged without a namespace: %+v", resources)
+	}
+	resp, err := server.ReadResource(ctx, "docs://pages/intro")
+	if err != nil || resp.Contents[0].URI != "docs://pages/intro" {
+		t.Errorf("template match should route to the remote: %+v, %v", resp, err)
+	}
+	if _, err := server.ReadResource(ctx, "other://x"); err != ErrUnknownResource {
+		t.Errorf("expected ErrUnknownResource, got %v", err)
+	}
+	if _, err := server.GetPrompt(ctx, "summarize", map[string]string{"doc": "x"}); err != nil {
+		t.Errorf("prompt should route to the remote: %v", err)
+	}
+}
+
+func TestRemoteProviderResourcesAndPrompts(t *testing.T) {
+	_, ts := catalogRemote(t)
+	provider := NewRemoteProvider(func(ctx context.Context) ([]RemoteProviderConfig, error) {
+		return []RemoteProviderConfig{{Name: "docs", URL: ts.URL}}, nil
+	})
+	server := NewServer("gateway", "1.0")
+	ctx := WithPromptProviders(WithResourceProviders(context.Background(), provider), provider)
+
+	if resources := server.ListResources(ctx); len(resources) != 1 || resources[0].URI != "docs+docs://guide" {
+		t.Errorf("unexpected resources %+v", resources)
+	}
+	if templates := server.ListResourceTemplates(ctx); len(templates) != 1 || templates[0].URITemplate != "docs+docs://pages/{page}" {
+		t.Errorf("unexpected templates %+v", templates)
+	}
+	resp, err := server.ReadResource(ctx, "docs+docs://guide")
+	if err != nil || resp.Contents[0].URI != "docs+docs://guide" || resp.Contents[0].Text != "the guide" {
+		t.Errorf("read should route to the remote: %+v, %v", resp, err)
+	}
+	if _, err := server.ReadResource(ctx, "git+ssh://host/repo"); err != ErrUnknownResource {
+		t.Errorf("expected ErrUnknownResource, got %v", err)
+	}
+
+	if prompts := server.ListPrompts(ctx); len(prompts) != 1 || prompts[0].Name != "docs__summarize" {
+		t.Errorf("unexpected prompts %+v", prompts)
+	}
+	prompt, err := server.GetPrompt(ctx, "docs__summarize", map[string]string{"doc": "README"})
+	if err != nil || prompt.Messages[0].Content.Text != "Summarize README" {
+		t.Errorf("prompt should route to the remote: %+v, %v", prompt, err)
+	}
+}
+
+func TestRemoteProviderCachesResourcesAndPrompts(t *testing.T) {
+	remote, _ := catalogRemote(t)
+	var lists sync.Map // method -> *atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req MCPRequest
+		if json.Unmarshal(body, &req) == nil && isListMethod(req.Method) {
+			n, _ := lists.LoadOrStore(req.Method, new(atomic.Int32))
+			n.(*atomic.Int32).Add(1)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		remote.HandleRequest(w, r)
+	}))
+	defer ts.Close()
+	count := func(method string) int32 {
+		n, ok := lists.Load(method)
+		if !ok {
+			return 0
+		}
+		return n.(*atomic.Int32).Load()
+	}
+
+	provider := NewRemoteProvider(func(ctx context.Context) ([]RemoteProviderConfig, error) {
+		return []RemoteProviderConfig{{Name: "docs", URL: ts.URL}}, nil
+	})
+	server := NewServer("gateway", "1.0")
+	for range 3 {
+		ctx := WithPromptProviders(WithResourceProviders(context.Background(), provider), provider)
+		if len(server.ListResources(ctx)) != 1 || len(server.ListResourceTemplates(ctx)) != 1 || len(server.ListPrompts(ctx)) != 1 {
+			t.Fatal("missing remote resources or prompts")
+		}
+	}
+	for _, method := range []string{"resources/list", "resources/templates/list", "prompts/list"} {
+		if n := count(method); n != 1 {
+			t.Errorf("%s sent %d times, want 1", method, n)
+		}
+	}
+
+	provider.InvalidateCache("docs\x00" + ts.URL)
+	ctx := WithPromptProviders(context.Background(), provider)
+	server.ListPrompts(ctx)
+	if n := count("prompts/list"); n != 2 {
+		t.Errorf("prompts/list sent %d times after invalidation, want 2", n)
+	}
+}
+
+func TestRegisterRemoteServerFetchesOutsideLock(t *testing.T) {
+	remote, _ := catalogRemote(t)
+	listing, release := make(chan struct{}), make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req MCPRequest
+		if json.Unmarshal(body, &req) == nil && req.Method == "resources/list" {
+			close(listing)
+			<-release
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		remote.HandleRequest(w, r)
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	server := NewServer("gateway", "1.0")
+	server.RegisterResource(NewResource("file:///local", "local", "A local file", "text/plain"), nil)
+	go server.RegisterRemoteServer(NewClient(ts.URL, nil, "docs"))
+	<-listing
+
+	// The server keeps answering while the remote is slow to list.
+	done := make(chan int, 1)
+	go func() { done <- len(server.ListResources(context.Background())) }()
+	select {
+	case n := <-done:
+		if n != 1 {
+			t.Errorf("got %d resources, want the local one", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("listing blocked on a remote being registered")
+	}
+}
+
+func TestRemoteResourceAndPromptChangesPropagate(t *testing.T) {
+	upstream, uts := catalogRemote(t)
+
+	gateway := NewServer("gateway", "1.0")
+	gts := httptest.NewServer(http.HandlerFunc(gateway.HandleRequest))
+	defer gts.Close()
+
+	upClient := NewClient(uts.URL, nil, "docs")
+	upClient.EnableNotifications()
+	defer upClient.Close()
+	if err := gateway.RegisterRemoteServer(upClient); err != nil {
+		t.Fatal(err)
+	}
+
+	var resourcesChanged, promptsChanged atomic.Int32
+	downstream := NewClient(gts.URL, nil, "")
+	defer downstream.Close()
+	downstream.OnResourcesChanged(func() { resourcesChanged.Add(1) }).
+		OnPromptsChanged(func() { promptsChanged.Add(1) }).
+		EnableNotifications()
+	if _, err := downstream.ListTools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscribers(t, upstream, 1)
+	waitForSubscribers(t, gateway, 1)
+
+	upstream.RegisterResource(NewResource("docs://faq", "faq", "FAQ", "text/plain"), nil)
+	upstream.RegisterPrompt(NewPrompt("translate", "Translate a document"), nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && (resourcesChanged.Load() == 0 || promptsChanged.Load() == 0) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if resourcesChanged.Load() == 0 || promptsChanged.Load() == 0 {
+		t.Fatalf("changes should propagate downstream: resources %d, prompts %d", resourcesChanged.Load(), promptsChanged.Load())
+	}
+
+	resources, err := downstream.ListResources(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, res := range resources {
+		found = found || res.URI == "docs+docs://faq"
+	}
+	if !found {
+		t.Errorf("downstream should see the new remote resource: %+v", resources)
+	}
+}
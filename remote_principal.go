@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// errPrincipalRemote is returned when a remote registered with
+// WithAuthResolver cannot be served per principal.
+var errPrincipalRemote = errors.New("remote servers with an auth resolver need a namespace and an HTTP URL, and do not support remote search")
+
+// principalRemotesKey marks a context that already carries the server's
+// per-principal remotes, so they are attached once per request.
+type principalRemotesKey struct{}
+
+// WithAuthResolver resolves the remote server's auth for each request instead
+// of using the client's, so every user reaches the server with their own
+// credentials. Tool lists are fetched per request and cached per principal
+// (see WithPrincipal), so each user sees the tools the server offers them;
+// requests without a principal are not cached. The client must have a
+// namespace and an HTTP URL, and remote search is not supported.
+func WithAuthResolver(resolve AuthResolver) RemoteServerOption {
+	return func(o *remoteServerOptions) {
+		o.authResolver = resolve
+	}
+}
+
+// httpClientPool hands out a client's HTTP client as a pool, so a
+// per-principal remote keeps the transport it was registered with.
+type httpClientPool struct{ client *http.Client }
+
+func (p httpClientPool) GetHTTPClient() *http.Client { return p.client }
+
+// registerPrincipalRemote registers client as a remote served per principal
+// by the server's internal RemoteProvider. Its tools, resources and prompts
+// are not fetched into the shared caches.
+func (s *Server) registerPrincipalRemote(client *Client, visibility ToolVisibility, o *remoteServerOptions) error {
+	namespace := strings.TrimSuffix(client.Namespace(), client.separator)
+	if namespace == "" || client.transport != nil || o.remoteSearch {
+		return errPrincipalRemote
+	}
+
+	client.mu.RLock()
+	cfg := RemoteProviderConfig{
+		Name:           namespace,
+		Separator:      client.separator,
+		URL:            client.baseURL,
+		AuthFunc:       o.authResolver,
+		Visibility:     visibility,
+		ToolFilter:     client.toolFilter,
+		Transforms:     client.transforms,
+		CircuitBreaker: o.breaker,
+		HTTPPool:       httpClientPool{client.httpClient},
+	}
+	client.mu.RUnlock()
+
+	s.mu.Lock()
+	s.principalRemotes[client.registryKey()] = cfg
+	s.mu.Unlock()
+
+	// A remote registered again at the same URL may offer other tools.
+	s.principalProvider.InvalidateAllCache()
+	return nil
+}
+
+// resolvePrincipalRemotes is the resolver of the server's internal
+// RemoteProvider. It keys each remote's cached tool list by the request's
+// principal, and turns caching off for requests without one.
+func (s *Server) resolvePrincipalRemotes(ctx context.Context) ([]RemoteProviderConfig, error) {
+	s.mu.RLock()
+	configs := make([]RemoteProviderConfig, 0, len(s.principalRemotes))
+	for _, cfg := range s.principalRemotes {
+		configs = append(configs, cfg)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Name < configs[j].Name })
+
+	principal, ok := PrincipalFromContext(ctx)
+	for i := range configs {
+		if ok && principal.ID != "" {
+			configs[i].CacheKey = configs[i].cacheKey() + "\x00" + principal.ID
+		} else {
+			configs[i].CacheTTL = -1
+		}
+	}
+	return configs, nil
+}
+
+// withPrincipalRemotes attaches the server's per-principal remotes to ctx as
+// tool, resource and prompt providers, unless there are none or they are
+// already attached.
+func (s *Server) withPrincipalRemotes(ctx context.Context) context.Context {
+	if ctx == nil || ctx.Value(principalRemotesKey{}) == s {
+		return ctx
+	}
+	s.mu.RLock()
+	n := len(s.principalRemotes)
+	s.mu.RUnlock()
+	if n == 0 {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, principalRemotesKey{}, s)
+	ctx = WithToolProviders(ctx, s.principalProvider)
+	ctx = WithResourceProviders(ctx, s.principalProvider)
+	return WithPromptProviders(ctx, s.principalProvider)
+}
+
+// principalRemoteOfLocked returns the namespace of the per-principal remote
+// a call to the tool called name goes to, or "" if there is none. The caller
+// must hold s.mu.
+func (s *Server) principalRemoteOfLocked(name string) string {
+	for _, cfg := range s.principalRemotes {
+		if _, ok := cfg.remoteName(name); ok {
+			return cfg.Name
+		}
+	}
+	return ""
+}
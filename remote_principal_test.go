@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newPerUserRemote starts a remote whose tools depend on the bearer token, and
+// counts the tools/list requests made with each token.
+func newPerUserRemote(t *testing.T) (*httptest.Server, func(token string) int) {
+	t.Helper()
+	remote := NewServer("remote", "1")
+	var mu sync.Mutex
+	lists := make(map[string]int)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if bytes.Contains(body, []byte(`"tools/list"`)) {
+			mu.Lock()
+			lists[token]++
+			mu.Unlock()
+		}
+
+		name := strings.TrimSuffix(token, "-token") + "_tool"
+		provider := &staticProvider{
+			tools:   []MCPTool{{Name: name, Description: "for " + token, InputSchema: map[string]any{"type": "object"}}},
+			results: map[string]any{name: token},
+		}
+		remote.HandleRequest(w, r.WithContext(WithToolProviders(r.Context(), provider)))
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, func(token string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return lists[token]
+	}
+}
+
+func principalToken(ctx context.Context) (AuthProvider, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	return NewBearerTokenAuth(principal.ID + "-token"), nil
+}
+
+func TestRemoteServerWithAuthResolver(t *testing.T) {
+	ts, lists := newPerUserRemote(t)
+
+	host := NewServer("host", "1")
+	client := NewClient(ts.URL, nil, "crm")
+	if err := host.RegisterRemoteServer(client, WithAuthResolver(principalToken)); err != nil {
+		t.Fatal(err)
+	}
+	if lists("-token") != 0 {
+		t.Error("registration fetched tools without a principal")
+	}
+
+	alice := WithPrincipal(context.Background(), Principal{ID: "alice"})
+	bob := WithPrincipal(context.Background(), Principal{ID: "bob"})
+
+	if tools := host.ListToolsWithContext(alice); !containsToolNamed(tools, "crm__alice_tool") || containsToolNamed(tools, "crm__bob_tool") {
+		t.Errorf("alice sees %v", toolNames(tools))
+	}
+	if tools := host.ListToolsWithContext(bob); !containsToolNamed(tools, "crm__bob_tool") || containsToolNamed(tools, "crm__alice_tool") {
+		t.Errorf("bob sees %v", toolNames(tools))
+	}
+
+	host.ListToolsWithContext(alice)
+	if n := lists("alice-token"); n != 1 {
+		t.Errorf("alice's tools were listed %d times, want 1 (cached per principal)", n)
+	}
+
+	resp, err := host.CallTool(alice, "crm__alice_tool", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := resp.Content[0].Text; text != "alice-token" {
+		t.Errorf("call went out as %q", text)
+	}
+	if _, err := host.CallTool(alice, "crm__bob_tool", nil); err == nil {
+		t.Error("alice called bob's tool")
+	}
+
+	host.UnregisterRemoteServer(client)
+	if tools := host.ListToolsWithContext(alice); containsToolNamed(tools, "crm__alice_tool") {
+		t.Errorf("tools still listed after unregistering: %v", toolNames(tools))
+	}
+}
+
+func TestRemoteServerWithAuthResolverNoPrincipal(t *testing.T) {
+	ts, lists := newPerUserRemote(t)
+
+	host := NewServer("host", "1")
+	if err := host.ReplaceRemoteServers([]RemoteServerEntry{{
+		Client:       NewClient(ts.URL, nil, "crm"),
+		Visibility:   ToolVisibilityNative,
+		AuthResolver: principalToken,
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	host.ListToolsWithContext(context.Background())
+	first := lists("-token")
+	host.ListToolsWithContext(context.Background())
+	if first == 0 || lists("-token") == first {
+		t.Errorf("anonymous tools were listed %d then %d times; want them fetched for each request", first, lists("-token"))
+	}
+}
+
+func TestRemoteServerWithAuthResolverErrors(t *testing.T) {
+	host := NewServer("host", "1")
+	if err := host.RegisterRemoteServer(NewClient("http://localhost:1", nil, ""), WithAuthResolver(principalToken)); err == nil {
+		t.Error("registered a remote without a namespace")
+	}
+	if err := host.RegisterRemoteServer(NewClient("http://localhost:1", nil, "crm"), WithAuthResolver(principalToken), WithRemoteSearch()); err == nil {
+		t.Error("registered a remote with remote search")
+	}
+}
+
+func TestRemoteServerWithAuthResolverCustomSeparator(t *testing.T) {
+	ts, _ := newPerUserRemote(t)
+
+	// The client keeps the separator in force when it was created.
+	DefaultNamespaceSeparator = "::"
+	client := NewClient(ts.URL, nil, "crm")
+	DefaultNamespaceSeparator = "__"
+
+	host := NewServer("host", "1")
+	if err := host.RegisterRemoteServer(client, WithAuthResolver(principalToken)); err != nil {
+		t.Fatal(err)
+	}
+
+	alice := WithPrincipal(context.Background(), Principal{ID: "alice"})
+	if tools := host.ListToolsWithContext(alice); !containsToolNamed(tools, "crm::alice_tool") {
+		t.Fatalf("alice sees %v", toolNames(tools))
+	}
+	resp, err := host.CallTool(alice, "crm::alice_tool", nil)
+	if err != nil || resp.Content[0].Text != "alice-token" {
+		t.Fatalf("call: %v, %v", resp, err)
+	}
+	if remote := host.remoteOf("crm::alice_tool"); remote != "crm" {
+		t.Errorf("remoteOf = %q, want crm", remote)
+	}
+}
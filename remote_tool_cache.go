@@ -12,40 +12,61 @@ import (
 // with WithMaxCacheEntries.
 const DefaultRemoteToolCacheMaxEntries = 1024
 
-// remoteToolCache is a bounded, TTL-aware LRU cache of remote tool lists.
+// remoteListCache is a bounded, TTL-aware LRU cache of lists fetched from
+// remote servers: tools, resources, resource templates or prompts.
 // Entries expire by time (TTL) and are also evicted in least-recently-used
 // order once the number of live entries exceeds the configured maximum, so the
 // cache stays bounded regardless of how many distinct keys are seen.
-type remoteToolCache struct {
+type remoteListCache[T any] struct {
 	mu    sync.Mutex
 	max   int
-	ll    *list.List // front = most recently used; Value is *remoteToolCacheEntry
+	ll    *list.List // front = most recently used; Value is *remoteCacheEntry[T]
 	items map[string]*list.Element
 }
 
-type remoteToolCacheEntry struct {
-	key       string
-	tools     []MCPTool
-	expiresAt time.Time
+// remoteToolCache caches remote tool lists.
+type remoteToolCache = remoteListCache[MCPTool]
+
+type remoteCacheEntry[T any] struct {
+	key        string
+	values     []T
+	expiresAt  time.Time
+	staleUntil time.Time // Until when getStale may still return the list
 }
 
-// newRemoteToolCache creates a cache holding at most max live entries.
-// A max <= 0 uses DefaultRemoteToolCacheMaxEntries.
+// newRemoteToolCache creates a tool-list cache holding at most max live
+// entries. A max <= 0 uses DefaultRemoteToolCacheMaxEntries.
 func newRemoteToolCache(max int) *remoteToolCache {
+	return newRemoteListCache[MCPTool](max)
+}
+
+// newRemoteListCache creates a cache holding at most max live entries.
+// A max <= 0 uses DefaultRemoteToolCacheMaxEntries.
+func newRemoteListCache[T any](max int) *remoteListCache[T] {
 	if max <= 0 {
 		max = DefaultRemoteToolCacheMaxEntries
 	}
-	return &remoteToolCache{
+	return &remoteListCache[T]{
 		max:   max,
 		ll:    list.New(),
 		items: make(map[string]*list.Element),
 	}
 }
 
-// get returns a copy of the cached tools for key when present and not expired
-// as of now. Expired entries are evicted on access. A hit moves the entry to
-// the most-recently-used position.
-func (c *remoteToolCache) get(key string, now time.Time) ([]MCPTool, bool) {
+// get returns a copy of the cached list for key when present and not expired
+// as of now. Expired entries are evicted on access unless still usable by
+// getStale. A hit moves the entry to the most-recently-used position.
+func (c *remoteListCache[T]) get(key string, now time.Time) ([]T, bool) {
+	return c.lookup(key, now, false)
+}
+
+// getStale is get for a server that cannot be reached: it also returns
+// expired lists, up to the stale period given to putWithStale.
+func (c *remoteListCache[T]) getStale(key string, now time.Time) ([]T, bool) {
+	return c.lookup(key, now, true)
+}
+
+func (c *remoteListCache[T]) lookup(key string, now time.Time, stale bool) ([]T, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -53,39 +74,50 @@ func (c *remoteToolCache) get(key string, now time.Time) ([]MCPTool, bool) {
 	if !ok {
 		return nil, false
 	}
-	entry := el.Value.(*remoteToolCacheEntry)
-	if !now.Before(entry.expiresAt) {
+	entry := el.Value.(*remoteCacheEntry[T])
+	if !now.Before(entry.staleUntil) {
 		c.removeElement(el)
 		return nil, false
 	}
+	if !stale && !now.Before(entry.expiresAt) {
+		return nil, false
+	}
 	c.ll.MoveToFront(el)
 
-	out := make([]MCPTool, len(entry.tools))
-	copy(out, entry.tools)
+	out := make([]T, len(entry.values))
+	copy(out, entry.values)
 	return out, true
 }
 
-// put stores a copy of tools under key, expiring ttl after now, evicting the
+// put stores a copy of values under key, expiring ttl after now, evicting the
 // least-recently-used entries when the cache exceeds its maximum size. Taking
 // now (rather than reading the clock internally) keeps it symmetric with get
 // and lets tests control expiry deterministically.
-func (c *remoteToolCache) put(key string, tools []MCPTool, ttl time.Duration, now time.Time) {
+func (c *remoteListCache[T]) put(key string, values []T, ttl time.Duration, now time.Time) {
+	c.putWithStale(key, values, ttl, 0, now)
+}
+
+// putWithStale is put for values that getStale may return for staleFor after
+// they expire.
+func (c *remoteListCache[T]) putWithStale(key string, values []T, ttl, staleFor time.Duration, now time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	stored := make([]MCPTool, len(tools))
-	copy(stored, tools)
+	stored := make([]T, len(values))
+	copy(stored, values)
 	expiresAt := now.Add(ttl)
+	staleUntil := expiresAt.Add(max(staleFor, 0))
 
 	if el, ok := c.items[key]; ok {
-		entry := el.Value.(*remoteToolCacheEntry)
-		entry.tools = stored
+		entry := el.Value.(*remoteCacheEntry[T])
+		entry.values = stored
 		entry.expiresAt = expiresAt
+		entry.staleUntil = staleUntil
 		c.ll.MoveToFront(el)
 		return
 	}
 
-	el := c.ll.PushFront(&remoteToolCacheEntry{key: key, tools: stored, expiresAt: expiresAt})
+	el := c.ll.PushFront(&remoteCacheEntry[T]{key: key, values: stored, expiresAt: expiresAt, staleUntil: staleUntil})
 	c.items[key] = el
 
 	for c.ll.Len() > c.max {
@@ -94,7 +126,7 @@ func (c *remoteToolCache) put(key string, tools []MCPTool, ttl time.Duration, no
 }
 
 // invalidate removes the entry for key, if present.
-func (c *remoteToolCache) invalidate(key string) {
+func (c *remoteListCache[T]) invalidate(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if el, ok := c.items[key]; ok {
@@ -103,7 +135,7 @@ func (c *remoteToolCache) invalidate(key string) {
 }
 
 // clear removes all entries.
-func (c *remoteToolCache) clear() {
+func (c *remoteListCache[T]) clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.ll.Init()
@@ -111,21 +143,88 @@ func (c *remoteToolCache) clear() {
 }
 
 // len reports the number of live entries (primarily for tests/metrics).
-func (c *remoteToolCache) len() int {
+func (c *remoteListCache[T]) len() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	return c.ll.Len()
 }
 
-func (c *remoteToolCache) removeOldest() {
+func (c *remoteListCache[T]) removeOldest() {
 	if el := c.ll.Back(); el != nil {
 		c.removeElement(el)
 	}
 }
 
 // removeElement must be called with c.mu held.
-func (c *remoteToolCache) removeElement(el *list.Element) {
+func (c *remoteListCache[T]) removeElement(el *list.Element) {
 	c.ll.Remove(el)
-	entry := el.Value.(*remoteToolCacheEntry)
+	entry := el.Value.(*remoteCacheEntry[T])
 	delete(c.items, entry.key)
 }
+
+// lruMap maps keys to values, keeping at most max entries by dropping the
+// least recently used. It is not safe for concurrent use.
+type lruMap[V any] struct {
+	max   int
+	ll    *list.List // front = most recently used; Value is *lruMapEntry[V]
+	items map[string]*list.Element
+}
+
+type lruMapEntry[V any] struct {
+	key   string
+	value V
+}
+
+// newLRUMap creates a map holding at most max entries. A max <= 0 uses
+// DefaultRemoteToolCacheMaxEntries.
+func newLRUMap[V any](max int) *lruMap[V] {
+	if max <= 0 {
+		max = DefaultRemoteToolCacheMaxEntries
+	}
+	return &lruMap[V]{
+		max:   max,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get returns the value for key, marking it most recently used.
+func (m *lruMap[V]) get(key string) (V, bool) {
+	el, ok := m.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	m.ll.MoveToFront(el)
+	return el.Value.(*lruMapEntry[V]).value, true
+}
+
+// put sets the value for key, dropping the least recently used entries once
+// there are more than max.
+func (m *lruMap[V]) put(key string, value V) {
+	if el, ok := m.items[key]; ok {
+		el.Value.(*lruMapEntry[V]).value = value
+		m.ll.MoveToFront(el)
+		return
+	}
+	m.items[key] = m.ll.PushFront(&lruMapEntry[V]{key: key, value: value})
+	for m.ll.Len() > m.max {
+		el := m.ll.Back()
+		m.ll.Remove(el)
+		delete(m.items, el.Value.(*lruMapEntry[V]).key)
+	}
+}
+
+// values returns every value, most recently used first.
+func (m *lruMap[V]) values() []V {
+	out := make([]V, 0, m.ll.Len())
+	for el := m.ll.Front(); el != nil; el = el.Next() {
+		out = append(out, el.Value.(*lruMapEntry[V]).value)
+	}
+	return out
+}
+
+// len reports the number of entries.
+func (m *lruMap[V]) len() int {
+	return m.ll.Len()
+}
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -251,6 +252,44 @@ func TestRemoteExecuteTool(t *testing.T) {
 	})
 }
 
+func TestRemoteDiscoveredToolValidation(t *testing.T) {
+	var calls int
+	remoteServer := NewServer("remote", "1.0.0")
+	remoteServer.SetSchemaValidation(SchemaValidationOff)
+	remoteServer.RegisterTool(
+		NewTool("resize", "Resize the pool",
+			Integer("size", "Pool size", Required(), Minimum(1)),
+			Output(Integer("size", "New size", Required())),
+		).Discoverable("pool"),
+		func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+			calls++
+			return NewToolResponseStructured(map[string]any{"size": "big"}), nil
+		},
+	)
+	remoteTS := httptest.NewServer(http.HandlerFunc(remoteServer.HandleRequest))
+	defer remoteTS.Close()
+
+	mainServer := NewServer("main", "1.0.0")
+	mainServer.SetOutputValidation(OutputValidationError)
+	if err := mainServer.ReplaceRemoteServers([]RemoteServerEntry{
+		{Client: NewClient(remoteTS.URL, nil, "remote"), Visibility: ToolVisibilityDiscoverable, RemoteSearch: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tool is looked up on the remote, since nothing has searched for it.
+	_, err := mainServer.CallTool(context.Background(), "remote__resize", map[string]any{"size": 0})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInvalidParams || calls != 0 {
+		t.Fatalf("expected the input schema to be enforced locally, got %v after %d calls", err, calls)
+	}
+
+	_, err = mainServer.CallTool(context.Background(), "remote__resize", map[string]any{"size": 2})
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInternalError || calls != 1 {
+		t.Fatalf("expected the output schema to be enforced, got %v after %d calls", err, calls)
+	}
+}
+
 func TestRemoteToolSearchHTTP(t *testing.T) {
 	remoteServer := NewServer("remote", "1.0.0")
 	remoteServer.RegisterTool(
@@ -65,10 +65,12 @@ func (r *ResourceRequest) Vars() map[string]string {
 // has a fixed URI, appears in resources/list, and is read verbatim by
 // resources/read. Register it with [Server.RegisterResource].
 type ResourceBuilder struct {
-	uri         string
-	name        string
-	description string
-	mimeType    string
+	uri          string
+	name         string
+	description  string
+	mimeType     string
+	discoverable bool     // If true, found via resource_search but not in resources/list
+	keywords     []string // Keywords for resource_search
 }
 
 // NewResource creates a static resource descriptor.
@@ -98,6 +100,21 @@ func (r *ResourceBuilder) Description() string { return r.description }
 // MimeType returns the resource's MIME type (may be empty).
 func (r *ResourceBuilder) MimeType() string { return r.mimeType }
 
+// Discoverable hides the resource from resources/list; it is found with the
+// resource_search meta-tool instead and can still be read by URI. Keywords
+// improve search relevance.
+func (r *ResourceBuilder) Discoverable(keywords ...string) *ResourceBuilder {
+	r.discoverable = true
+	r.keywords = keywords
+	return r
+}
+
+// IsDiscoverable returns true if the resource is marked as discoverable.
+func (r *ResourceBuilder) IsDiscoverable() bool { return r.discoverable }
+
+// Keywords returns the keywords set for this resource.
+func (r *ResourceBuilder) Keywords() []string { return r.keywords }
+
 // ToMCPResource converts the builder to an MCPResource descriptor.
 func (r *ResourceBuilder) ToMCPResource() MCPResource {
 	return MCPResource{
@@ -105,6 +122,8 @@ func (r *ResourceBuilder) ToMCPResource() MCPResource {
 		Name:        r.name,
 		Description: r.description,
 		MimeType:    r.mimeType,
+		Keywords:    r.keywords,
+		Visibility:  visibilityFor(r.discoverable),
 	}
 }
 
@@ -114,10 +133,12 @@ func (r *ResourceBuilder) ToMCPResource() MCPResource {
 // concrete URI before being read via resources/read. Register it with
 // [Server.RegisterResourceTemplate].
 type ResourceTemplateBuilder struct {
-	uriTemplate string
-	name        string
-	description string
-	mimeType    string
+	uriTemplate  string
+	name         string
+	description  string
+	mimeType     string
+	discoverable bool     // If true, found via resource_search but not in resources/templates/list
+	keywords     []string // Keywords for resource_search
 }
 
 // NewResourceTemplate creates a parameterized resource template descriptor.
@@ -146,6 +167,21 @@ func (t *ResourceTemplateBuilder) Description() string { return t.description }
 // MimeType returns the template's MIME type (may be empty).
 func (t *ResourceTemplateBuilder) MimeType() string { return t.mimeType }
 
+// Discoverable hides the template from resources/templates/list; it is found
+// with the resource_search meta-tool instead and URIs matching it can still be
+// read. Keywords improve search relevance.
+func (t *ResourceTemplateBuilder) Discoverable(keywords ...string) *ResourceTemplateBuilder {
+	t.discoverable = true
+	t.keywords = keywords
+	return t
+}
+
+// IsDiscoverable returns true if the template is marked as discoverable.
+func (t *ResourceTemplateBuilder) IsDiscoverable() bool { return t.discoverable }
+
+// Keywords returns the keywords set for this template.
+func (t *ResourceTemplateBuilder) Keywords() []string { return t.keywords }
+
 // ToMCPResourceTemplate converts the builder to an MCPResourceTemplate descriptor.
 func (t *ResourceTemplateBuilder) ToMCPResourceTemplate() MCPResourceTemplate {
 	return MCPResourceTemplate{
@@ -153,5 +189,7 @@ func (t *ResourceTemplateBuilder) ToMCPResourceTemplate() MCPResourceTemplate {
 		Name:        t.name,
 		Description: t.description,
 		MimeType:    t.mimeType,
+		Keywords:    t.keywords,
+		Visibility:  visibilityFor(t.discoverable),
 	}
 }
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 )
 
 // registeredResource holds a static resource and its read handler.
@@ -96,9 +98,18 @@ func (s *Server) RegisterResourceTemplate(tb *ResourceTemplateBuilder, handler R
 }
 
 // ListResources returns all registered static resources plus any contributed by
-// [ResourceProvider]s on ctx, sorted by URI. Duplicates (by URI) are removed,
-// with static registrations taking precedence.
+// [ResourceProvider]s on ctx, sorted by URI, followed by those of registered
+// remote servers under namespaced URIs (see [ResourceNamespaceSeparator]).
+// Duplicates (by URI) are removed, with static registrations taking
+// precedence. Discoverable resources are
+// left out unless ctx is in show-all mode (see [WithShowAllTools]).
 func (s *Server) ListResources(ctx context.Context) []MCPResource {
+	return s.listResources(ctx, GetShowAllTools(ctx))
+}
+
+// listResources lists resources, including discoverable ones if all is set.
+func (s *Server) listResources(ctx context.Context, all bool) []MCPResource {
+	ctx = s.withPrincipalRemotes(ctx)
 	s.mu.RLock()
 	result := make([]MCPResource, 0, len(s.resources))
 	seen := make(map[string]bool, len(s.resources))
@@ -113,13 +124,36 @@ func (s *Server) ListResources(ctx context.Context) []MCPResource {
 	if ctx != nil {
 		result = append(result, listResourcesFromProviders(ctx, seen)...)
 	}
+	for _, catalog := range s.remoteCatalogs() {
+		for _, res := range catalog.resources {
+			if !seen[res.URI] {
+				result = append(result, res)
+				seen[res.URI] = true
+			}
+		}
+	}
+	if policy := s.getPolicy(); policy != nil {
+		result = slices.DeleteFunc(result, func(r MCPResource) bool { return !s.allowResource(ctx, policy, r.URI) })
+	}
+	if !all {
+		return nativeOnly(result, func(r MCPResource) ToolVisibility { return r.Visibility })
+	}
 	return result
 }
 
 // ListResourceTemplates returns all registered resource templates plus any
-// contributed by [ResourceProvider]s on ctx, sorted by URITemplate. Duplicates
+// contributed by [ResourceProvider]s on ctx, sorted by URITemplate, followed
+// by those of registered remote servers under namespaced URIs. Duplicates
 // (by URITemplate) are removed, with static registrations taking precedence.
+// Discoverable templates are left out unless ctx is in show-all mode.
 func (s *Server) ListResourceTemplates(ctx context.Context) []MCPResourceTemplate {
+	return s.listResourceTemplates(ctx, GetShowAllTools(ctx))
+}
+
+// listResourceTemplates lists templates, including discoverable ones if all
+// is set.
+func (s *Server) listResourceTemplates(ctx context.Context, all bool) []MCPResourceTemplate {
+	ctx = s.withPrincipalRemotes(ctx)
 	s.mu.RLock()
 	result := make([]MCPResourceTemplate, 0, len(s.resourceTemplates))
 	seen := make(map[string]bool, len(s.resourceTemplates))
@@ -134,16 +168,55 @@ func (s *Server) ListResourceTemplates(ctx context.Context) []MCPResourceTemplat
 	if ctx != nil {
 		result = append(result, listResourceTemplatesFromProviders(ctx, seen)...)
 	}
+	for _, catalog := range s.remoteCatalogs() {
+		for _, tmpl := range catalog.templates {
+			if !seen[tmpl.URITemplate] {
+				result = append(result, tmpl)
+				seen[tmpl.URITemplate] = true
+			}
+		}
+	}
+	if policy := s.getPolicy(); policy != nil {
+		result = slices.DeleteFunc(result, func(t MCPResourceTemplate) bool { return !s.allowResource(ctx, policy, t.URITemplate) })
+	}
+	if !all {
+		return nativeOnly(result, func(t MCPResourceTemplate) ToolVisibility { return t.Visibility })
+	}
 	return result
 }
 
-// ReadResource resolves a URI to its content. Resolution order:
+// ReadResource resolves a URI to its content. Discoverable resources are read
+// like any other. Resolution order:
 //  1. Static resources by exact URI match.
 //  2. Static resource templates by pattern match (first match wins).
 //  3. [ResourceProvider]s on ctx, in attachment order (first hit wins).
+//  4. The remote server whose namespace the uri carries, with the namespace
+//     removed before the read and restored in the response.
 //
-// Returns ErrUnknownResource if nothing handles the uri.
+// Returns ErrUnknownResource if nothing handles the uri, and ErrPolicyDenied
+// if the server's policy forbids reading it (see SetPolicy). The read is
+// recorded in the audit log, if there is one (see SetAuditLog), and fails with
+// ErrAuditFailed if that log fails closed and cannot record it.
 func (s *Server) ReadResource(ctx context.Context, uri string) (*ResourceResponse, error) {
+	ctx = s.withPrincipalRemotes(ctx)
+	audit := s.auditLog()
+	if audit == nil {
+		return s.readResource(ctx, uri)
+	}
+	start := time.Now()
+	resp, err := s.readResource(ctx, uri)
+	if auditErr := audit.recordResourceRead(ctx, uri, start, err); auditErr != nil {
+		return nil, auditErr
+	}
+	return resp, err
+}
+
+// readResource is ReadResource without auditing.
+func (s *Server) readResource(ctx context.Context, uri string) (*ResourceResponse, error) {
+	if policy := s.getPolicy(); policy != nil && !s.allowResource(ctx, policy, uri) {
+		return nil, ErrPolicyDenied
+	}
+
 	s.mu.RLock()
 
 	// 1. Static exact match.
@@ -170,9 +243,14 @@ func (s *Server) ReadResource(ctx context.Context, uri string) (*ResourceRespons
 
 	// 3. Providers.
 	if ctx != nil {
-		return readResourceFromProviders(ctx, uri)
+		resp, err := readResourceFromProviders(ctx, uri)
+		if err != ErrUnknownResource {
+			return resp, err
+		}
 	}
-	return nil, ErrUnknownResource
+
+	// 4. Remote servers.
+	return s.readRemoteResource(ctx, uri)
 }
 
 // handleResourcesList handles resources/list over HTTP.
@@ -0,0 +1,758 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaValidationMode controls how strictly tool arguments are checked
+// against a tool's InputSchema before the handler is invoked.
+type SchemaValidationMode int
+
+const (
+	// SchemaValidationLenient validates types, required properties, nested
+	// shapes and value constraints (enum, minimum, pattern, ...), but tolerates
+	// unknown properties and treats "format" as an annotation. This is the
+	// default: it rejects arguments a handler cannot safely use without
+	// breaking clients that send harmless extra fields.
+	SchemaValidationLenient SchemaValidationMode = iota
+
+	// SchemaValidationStrict enforces every supported keyword, including
+	// additionalProperties: false (unknown properties are rejected) and
+	// "format" assertions such as date-time, email and uuid.
+	SchemaValidationStrict
+
+	// SchemaValidationOff disables schema validation. Only the legacy check
+	// that required parameters are present and non-empty is performed.
+	SchemaValidationOff
+)
+
+// String returns a human-readable name for the validation mode.
+func (m SchemaValidationMode) String() string {
+	switch m {
+	case SchemaValidationLenient:
+		return "lenient"
+	case SchemaValidationStrict:
+		return "strict"
+	case SchemaValidationOff:
+		return "off"
+	default:
+		return "unknown"
+	}
+}
+
+// SchemaError describes a single JSON Schema validation failure. Path is a
+// JSON pointer (RFC 6901) to the offending value within the validated
+// instance; the empty string denotes the instance itself.
+type SchemaError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidateSchema validates instance against a JSON Schema (a subset of draft
+// 2020-12) and returns every violation found, or nil if the instance is valid.
+// All supported keywords are enforced, including additionalProperties and
+// format.
+//
+// The schema is typically a map[string]any as produced by
+// [ToolBuilder.BuildSchema] or received from a remote server. Supported
+// keywords: type, enum, const, properties, required, additionalProperties,
+// patternProperties, minProperties, maxProperties, items, prefixItems,
+// minItems, maxItems, uniqueItems, minLength, maxLength, pattern, format,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, allOf,
+// anyOf, oneOf, not and local $ref (e.g. "#/$defs/address"). Unknown keywords
+// are ignored.
+func ValidateSchema(schema any, instance any) []SchemaError {
+	return validateSchema(schema, instance, true)
+}
+
+// validateSchema is the shared implementation behind ValidateSchema and the
+// server's argument validation. When strict is false, additionalProperties
+// and format are not asserted.
+func validateSchema(schema any, instance any, strict bool) []SchemaError {
+	if schema == nil {
+		return nil
+	}
+	return validateNormalizedSchema(toJSONValue(schema), instance, strict)
+}
+
+// validateNormalizedSchema is validateSchema for a schema that has already
+// been through toJSONValue.
+func validateNormalizedSchema(schema any, instance any, strict bool) []SchemaError {
+	root, _ := schema.(map[string]any)
+	v := &schemaValidator{root: root, strict: strict}
+	v.validate(schema, toJSONValue(instance), "", 0)
+	return v.errs
+}
+
+// newSchemaValidationError converts validation failures into the ToolError
+// returned to clients: ErrorCodeInvalidParams, the first failure in the
+// message and the full list under data.errors.
+func newSchemaValidationError(errs []SchemaError) error {
+	return NewToolError(ErrorCodeInvalidParams, "invalid parameters: "+errs[0].Error(), map[string]any{
+		"errors": errs,
+	})
+}
+
+// validateToolArguments runs the checks configured by mode against the input
+// schema of the tool called name, normalizing the schema through schemas. The
+// legacy required-parameter check always runs first so its error messages are
+// unchanged.
+func validateToolArguments(mode SchemaValidationMode, schemas *schemaCache, name string, schema any, args map[string]any) error {
+	if err := validateRequiredParameters(schema, args); err != nil {
+		return err
+	}
+	if mode == SchemaValidationOff || schema == nil {
+		return nil
+	}
+	var instance any = args
+	if args == nil {
+		instance = map[string]any{}
+	}
+	if errs := validateNormalizedSchema(schemas.normalized(name, schema), instance, mode == SchemaValidationStrict); len(errs) > 0 {
+		return newSchemaValidationError(errs)
+	}
+	return nil
+}
+
+// maxSchemaCacheEntries bounds the schema cache; it is emptied when full.
+const maxSchemaCacheEntries = 1024
+
+// schemaCache holds the normalized input schema of each tool called, by tool
+// name, so a schema is normalized once rather than on every call. An entry is
+// reused while the tool advertises the same schema map, which holds until its
+// tool list changes; schemas must not be modified once advertised.
+type schemaCache struct {
+	mu      sync.Mutex
+	entries map[string]schemaCacheEntry
+}
+
+type schemaCacheEntry struct {
+	source     map[string]any
+	normalized any
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{entries: make(map[string]schemaCacheEntry)}
+}
+
+// normalized returns schema after toJSONValue, from the cache when the tool
+// called name last had the same schema. Schemas that are not maps, such as
+// raw JSON, are normalized every time.
+func (c *schemaCache) normalized(name string, schema any) any {
+	source, ok := schema.(map[string]any)
+	if !ok || c == nil {
+		return toJSONValue(schema)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && sameMap(entry.source, source) {
+		return entry.normalized
+	}
+
+	normalized := toJSONValue(source)
+	c.mu.Lock()
+	if len(c.entries) >= maxSchemaCacheEntries {
+		clear(c.entries)
+	}
+	c.entries[name] = schemaCacheEntry{source: source, normalized: normalized}
+	c.mu.Unlock()
+	return normalized
+}
+
+// sameMap reports whether a and b are the same map, not merely equal ones.
+func sameMap(a, b map[string]any) bool {
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
+
+// maxSchemaDepth bounds recursion so a self-referencing $ref cannot loop forever.
+const maxSchemaDepth = 64
+
+type schemaValidator struct {
+	root   map[string]any
+	strict bool
+	errs   []SchemaError
+}
+
+func (v *schemaValidator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether instance satisfies schema without recording errors.
+// Used by the combinators (anyOf, oneOf, not).
+func (v *schemaValidator) matches(schema, instance any, path string, depth int) bool {
+	sub := &schemaValidator{root: v.root, strict: v.strict}
+	sub.validate(schema, instance, path, depth)
+	return len(sub.errs) == 0
+}
+
+func (v *schemaValidator) validate(schemaRaw, instance any, path string, depth int) {
+	if depth > maxSchemaDepth {
+		v.fail(path, "schema nesting too deep")
+		return
+	}
+
+	switch s := schemaRaw.(type) {
+	case bool:
+		if !s {
+			v.fail(path, "value is not allowed")
+		}
+		return
+	case map[string]any:
+		v.validateObjectSchema(s, instance, path, depth)
+	}
+}
+
+func (v *schemaValidator) validateObjectSchema(schema map[string]any, instance any, path string, depth int) {
+	if ref, ok := schema["$ref"].(string); ok {
+		if target, ok := v.resolveRef(ref); ok {
+			v.validate(target, instance, path, depth+1)
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !v.checkType(t, instance, path) {
+		// A type mismatch makes the remaining keywords meaningless.
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonEqual(candidate, instance) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "value must be one of %s", formatEnum(enum))
+		}
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, instance) {
+		v.fail(path, "value must be %s", formatJSON(c))
+	}
+
+	switch val := instance.(type) {
+	case string:
+		v.validateString(schema, val, path)
+	case float64:
+		v.validateNumber(schema, val, path)
+	case []any:
+		v.validateArray(schema, val, path, depth)
+	case map[string]any:
+		v.validateObject(schema, val, path, depth)
+	}
+
+	v.validateCombinators(schema, instance, path, depth)
+}
+
+func (v *schemaValidator) checkType(t any, instance any, path string) bool {
+	var types []string
+	switch tv := t.(type) {
+	case string:
+		types = []string{tv}
+	case []any:
+		for _, item := range tv {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return true
+	}
+	if len(types) == 0 {
+		return true
+	}
+
+	for _, typ := range types {
+		if instanceHasType(instance, typ) {
+			return true
+		}
+	}
+	if len(types) == 1 {
+		v.fail(path, "expected %s, got %s", types[0], jsonTypeName(instance))
+	} else {
+		v.fail(path, "expected one of [%s], got %s", strings.Join(types, ", "), jsonTypeName(instance))
+	}
+	return false
+}
+
+func instanceHasType(instance any, typ string) bool {
+	switch typ {
+	case "null":
+		return instance == nil
+	case "boolean":
+		_, ok := instance.(bool)
+		return ok
+	case "string":
+		_, ok := instance.(string)
+		return ok
+	case "number":
+		_, ok := instance.(float64)
+		return ok
+	case "integer":
+		f, ok := instance.(float64)
+		return ok && !math.IsInf(f, 0) && f == math.Trunc(f)
+	case "array":
+		_, ok := instance.([]any)
+		return ok
+	case "object":
+		_, ok := instance.(map[string]any)
+		return ok
+	}
+	// Unknown type names never match, so a typo in a schema is surfaced
+	// rather than silently accepting everything.
+	return false
+}
+
+func (v *schemaValidator) validateString(schema map[string]any, s string, path string) {
+	length := utf8.RuneCountInString(s)
+	if n, ok := schemaInt(schema, "minLength"); ok && length < n {
+		v.fail(path, "must be at least %d characters long", n)
+	}
+	if n, ok := schemaInt(schema, "maxLength"); ok && length > n {
+		v.fail(path, "must be at most %d characters long", n)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re := compileSchemaPattern(pattern); re != nil && !re.MatchString(s) {
+			v.fail(path, "does not match pattern %q", pattern)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && v.strict {
+		if !checkFormat(format, s) {
+			v.fail(path, "is not a valid %s", format)
+		}
+	}
+}
+
+func (v *schemaValidator) validateNumber(schema map[string]any, f float64, path string) {
+	if lo, ok := schemaNumber(schema, "minimum"); ok && f < lo {
+		v.fail(path, "must be >= %s", formatNumber(lo))
+	}
+	if hi, ok := schemaNumber(schema, "maximum"); ok && f > hi {
+		v.fail(path, "must be <= %s", formatNumber(hi))
+	}
+	if lo, ok := schemaNumber(schema, "exclusiveMinimum"); ok && f <= lo {
+		v.fail(path, "must be > %s", formatNumber(lo))
+	}
+	if hi, ok := schemaNumber(schema, "exclusiveMaximum"); ok && f >= hi {
+		v.fail(path, "must be < %s", formatNumber(hi))
+	}
+	if m, ok := schemaNumber(schema, "multipleOf"); ok && m > 0 {
+		q := f / m
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, "must be a multiple of %s", formatNumber(m))
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(schema map[string]any, arr []any, path string, depth int) {
+	if n, ok := schemaInt(schema, "minItems"); ok && len(arr) < n {
+		v.fail(path, "must contain at least %d items", n)
+	}
+	if n, ok := schemaInt(schema, "maxItems"); ok && len(arr) > n {
+		v.fail(path, "must contain at most %d items", n)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := 1; i < len(arr); i++ {
+			for j := 0; j < i; j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					v.fail(path, "items at index %d and %d are identical", j, i)
+					i = len(arr)
+					break
+				}
+			}
+		}
+	}
+
+	start := 0
+	if prefix, ok := schema["prefixItems"].([]any); ok {
+		for i := 0; i < len(prefix) && i < len(arr); i++ {
+			v.validate(prefix[i], arr[i], path+"/"+strconv.Itoa(i), depth+1)
+		}
+		start = len(prefix)
+	}
+	if items, ok := schema["items"]; ok {
+		for i := start; i < len(arr); i++ {
+			v.validate(items, arr[i], path+"/"+strconv.Itoa(i), depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema map[string]any, obj map[string]any, path string, depth int) {
+	for _, name := range schemaStrings(schema["required"]) {
+		if _, ok := obj[name]; !ok {
+			v.fail(joinPointer(path, name), "missing required property")
+		}
+	}
+	if n, ok := schemaInt(schema, "minProperties"); ok && len(obj) < n {
+		v.fail(path, "must have at least %d properties", n)
+	}
+	if n, ok := schemaInt(schema, "maxProperties"); ok && len(obj) > n {
+		v.fail(path, "must have at most %d properties", n)
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	patternProps, _ := schema["patternProperties"].(map[string]any)
+	additional, hasAdditional := schema["additionalProperties"]
+
+	// Iterate in sorted order so errors are reported deterministically.
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := obj[key]
+		propPath := joinPointer(path, key)
+		matched := false
+
+		if propSchema, ok := properties[key]; ok {
+			v.validate(propSchema, value, propPath, depth+1)
+			matched = true
+		}
+		for pattern, propSchema := range patternProps {
+			if re := compileSchemaPattern(pattern); re != nil && re.MatchString(key) {
+				v.validate(propSchema, value, propPath, depth+1)
+				matched = true
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+
+		switch a := additional.(type) {
+		case bool:
+			if !a && v.strict {
+				v.fail(propPath, "unknown property")
+			}
+		case map[string]any:
+			v.validate(a, value, propPath, depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) validateCombinators(schema map[string]any, instance any, path string, depth int) {
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			v.validate(sub, instance, path, depth+1)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.matches(sub, instance, path, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "must match at least one schema in anyOf")
+		}
+	}
+	if one, ok := schema["oneOf"].([]any); ok {
+		count := 0
+		for _, sub := range one {
+			if v.matches(sub, instance, path, depth+1) {
+				count++
+			}
+		}
+		if count != 1 {
+			v.fail(path, "must match exactly one schema in oneOf (matched %d)", count)
+		}
+	}
+	if not, ok := schema["not"]; ok && v.matches(not, instance, path, depth+1) {
+		v.fail(path, "must not match the schema in not")
+	}
+}
+
+// resolveRef resolves a local JSON pointer reference ("#", "#/$defs/name",
+// "#/definitions/name", ...) against the root schema. Remote references are
+// not fetched and resolve to nothing, so they are effectively ignored.
+func (v *schemaValidator) resolveRef(ref string) (any, bool) {
+	if v.root == nil || !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+	pointer := strings.TrimPrefix(ref, "#")
+	var current any = v.root
+	if pointer == "" {
+		return current, true
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch c := current.(type) {
+		case map[string]any:
+			next, ok := c[token]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, false
+			}
+			current = c[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// joinPointer appends a property name to a JSON pointer, escaping it per RFC 6901.
+func joinPointer(path, token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return path + "/" + token
+}
+
+var schemaPatternCache sync.Map // pattern string -> *regexp.Regexp (nil when invalid)
+
+// compileSchemaPattern compiles and caches a schema regular expression.
+// Invalid patterns return nil and are ignored rather than failing validation.
+func compileSchemaPattern(pattern string) *regexp.Regexp {
+	if cached, ok := schemaPatternCache.Load(pattern); ok {
+		re, _ := cached.(*regexp.Regexp)
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		schemaPatternCache.Store(pattern, (*regexp.Regexp)(nil))
+		return nil
+	}
+	schemaPatternCache.Store(pattern, re)
+	return re
+}
+
+var (
+	hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+	uuidPattern     = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// checkFormat asserts the well-known string formats. Unknown formats pass.
+func checkFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	case "time":
+		if _, err := time.Parse("15:04:05Z07:00", s); err == nil {
+			return true
+		}
+		_, err := time.Parse(time.TimeOnly, s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	case "uri-reference":
+		_, err := url.Parse(s)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(s)
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && strings.Contains(s, ".")
+	case "ipv6":
+		ip := net.ParseIP(s)
+		return ip != nil && strings.Contains(s, ":")
+	case "hostname":
+		return len(s) <= 253 && hostnamePattern.MatchString(s)
+	case "regex":
+		_, err := regexp.Compile(s)
+		return err == nil
+	}
+	return true
+}
+
+func schemaNumber(schema map[string]any, key string) (float64, bool) {
+	f, ok := schema[key].(float64)
+	return f, ok
+}
+
+func schemaInt(schema map[string]any, key string) (int, bool) {
+	f, ok := schema[key].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int(f), true
+}
+
+func schemaStrings(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func jsonTypeName(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) && !math.IsInf(val, 0) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func formatJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func formatEnum(values []any) string {
+	parts := make([]string, len(values))
+	for i, val := range values {
+		parts[i] = formatJSON(val)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// jsonEqual compares two normalized JSON values structurally.
+func jsonEqual(a, b any) bool {
+	return reflect.DeepEqual(toJSONValue(a), toJSONValue(b))
+}
+
+// toJSONValue normalizes a Go value into the shapes produced by
+// encoding/json decoding into an any: map[string]any, []any, float64, string,
+// bool and nil. Arguments passed to Server.CallTool directly (rather than
+// decoded from the wire) may use typed slices, maps and integer types, and
+// schemas built in Go may use []string or int literals; normalizing lets the
+// validator treat both uniformly.
+func toJSONValue(v any) any {
+	switch val := v.(type) {
+	case nil, bool, string, float64:
+		return val
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = toJSONValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = toJSONValue(item)
+		}
+		return out
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	case json.RawMessage:
+		var decoded any
+		if err := json.Unmarshal(val, &decoded); err != nil {
+			return nil
+		}
+		return decoded
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return toJSONValue(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			break // []byte marshals as a base64 string
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = toJSONValue(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			if rv.IsNil() {
+				return nil
+			}
+			out := make(map[string]any, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				out[iter.Key().String()] = toJSONValue(iter.Value().Interface())
+			}
+			return out
+		}
+	}
+
+	// Structs and anything else: round-trip through encoding/json.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var decoded any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return v
+	}
+	return decoded
+}
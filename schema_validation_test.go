@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchemaTypesAndPaths(t *testing.T) {
+	tool := NewTool("t", "test",
+		String("name", "name", Required()),
+		Integer("count", "count"),
+		Object("address", "address",
+			String("city", "city", Required()),
+			Integer("zip", "zip"),
+		),
+		ObjectArray("items", "items",
+			String("sku", "sku", Required()),
+		),
+	)
+	schema := tool.BuildSchema()
+
+	valid := map[string]any{
+		"name":    "x",
+		"count":   float64(3),
+		"address": map[string]any{"city": "London", "zip": float64(12345)},
+		"items":   []any{map[string]any{"sku": "a"}},
+	}
+	if errs := ValidateSchema(schema, valid); len(errs) != 0 {
+		t.Fatalf("expected valid, got %v", errs)
+	}
+
+	invalid := map[string]any{
+		"name":    "x",
+		"count":   1.5,
+		"address": map[string]any{"zip": "abc"},
+		"items":   []any{map[string]any{}},
+	}
+	errs := ValidateSchema(schema, invalid)
+	paths := make(map[string]bool)
+	for _, e := range errs {
+		paths[e.Path] = true
+	}
+	for _, want := range []string{"/count", "/address/city", "/address/zip", "/items/0/sku"} {
+		if !paths[want] {
+			t.Errorf("expected error at %s, got %v", want, errs)
+		}
+	}
+}
+
+func TestValidateSchemaKeywords(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"$defs": map[string]any{
+			"tag": map[string]any{"type": "string", "minLength": 2},
+		},
+		"properties": map[string]any{
+			"mode":  map[string]any{"enum": []any{"fast", "slow"}},
+			"level": map[string]any{"type": "number", "minimum": 1, "maximum": 10},
+			"code":  map[string]any{"type": "string", "pattern": "^[A-Z]{3}$"},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/tag"}, "uniqueItems": true},
+			"id":    map[string]any{"oneOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "integer"}}},
+			"when":  map[string]any{"type": "string", "format": "date-time"},
+		},
+	}
+
+	cases := []struct {
+		name  string
+		value map[string]any
+		path  string
+	}{
+		{"enum", map[string]any{"mode": "medium"}, "/mode"},
+		{"minimum", map[string]any{"level": 0}, "/level"},
+		{"maximum", map[string]any{"level": 11.0}, "/level"},
+		{"pattern", map[string]any{"code": "abc"}, "/code"},
+		{"ref", map[string]any{"tags": []string{"ok", "x"}}, "/tags/1"},
+		{"unique", map[string]any{"tags": []any{"ab", "ab"}}, "/tags"},
+		{"oneOf", map[string]any{"id": true}, "/id"},
+		{"format", map[string]any{"when": "yesterday"}, "/when"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateSchema(schema, tc.value)
+			if len(errs) == 0 {
+				t.Fatalf("expected error for %v", tc.value)
+			}
+			if errs[0].Path != tc.path {
+				t.Errorf("expected path %s, got %s (%v)", tc.path, errs[0].Path, errs)
+			}
+		})
+	}
+
+	ok := map[string]any{
+		"mode":  "fast",
+		"level": 5,
+		"code":  "ABC",
+		"tags":  []any{"ab", "cd"},
+		"id":    float64(7),
+		"when":  "2026-01-02T15:04:05Z",
+	}
+	if errs := ValidateSchema(schema, ok); len(errs) != 0 {
+		t.Fatalf("expected valid, got %v", errs)
+	}
+}
+
+func TestValidateSchemaPointerEscaping(t *testing.T) {
+	schema := map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"type": "integer"},
+	}
+	errs := ValidateSchema(schema, map[string]any{"a/b~c": "x"})
+	if len(errs) != 1 || errs[0].Path != "/a~1b~0c" {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestCallToolSchemaValidationModes(t *testing.T) {
+	server := NewServer("test", "1.0")
+	called := false
+	server.RegisterTool(NewTool("t", "test",
+		Integer("count", "count", Required()),
+		String("when", "when"),
+	), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		called = true
+		return NewToolResponseText("ok"), nil
+	})
+	ctx := context.Background()
+
+	// Wrong type is rejected in the default (lenient) mode.
+	_, err := server.CallTool(ctx, "t", map[string]any{"count": "three"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInvalidParams {
+		t.Fatalf("expected invalid params error, got %v", err)
+	}
+	if !strings.Contains(toolErr.Message, "/count") {
+		t.Errorf("expected JSON pointer in message, got %q", toolErr.Message)
+	}
+	if called {
+		t.Fatal("handler should not run on invalid arguments")
+	}
+
+	// Unknown properties are tolerated in lenient mode.
+	if _, err := server.CallTool(ctx, "t", map[string]any{"count": 1, "extra": true}); err != nil {
+		t.Fatalf("lenient mode should accept unknown properties: %v", err)
+	}
+
+	server.SetSchemaValidation(SchemaValidationStrict)
+	if _, err := server.CallTool(ctx, "t", map[string]any{"count": 1, "extra": true}); err == nil {
+		t.Fatal("strict mode should reject unknown properties")
+	}
+
+	server.SetSchemaValidation(SchemaValidationOff)
+	if _, err := server.CallTool(ctx, "t", map[string]any{"count": "three"}); err != nil {
+		t.Fatalf("validation off should only check required parameters: %v", err)
+	}
+}
+
+func TestCallToolSchemaValidationProviders(t *testing.T) {
+	server := NewServer("test", "1.0")
+	provider := &ProviderFuncs{
+		GetToolsFunc: func(ctx context.Context) ([]MCPTool, error) {
+			return []MCPTool{NewTool("p", "provider tool", Integer("n", "n", Required())).ToMCPTool()}, nil
+		},
+		ExecuteToolFunc: func(ctx context.Context, name string, params map[string]any) (*ToolResponse, error) {
+			if name != "p" {
+				return nil, nil
+			}
+			return NewToolResponseText("ok"), nil
+		},
+	}
+	ctx := WithToolProviders(context.Background(), provider)
+
+	if _, err := server.CallTool(ctx, "p", map[string]any{"n": []any{1}}); err == nil {
+		t.Fatal("expected provider tool arguments to be validated")
+	}
+	if _, err := server.CallTool(ctx, "p", map[string]any{"n": 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// lookupCountingProvider counts how often the server lists or looks up its
+// tools.
+type lookupCountingProvider struct {
+	ProviderFuncs
+	lists, lookups int
+}
+
+func (p *lookupCountingProvider) GetTools(ctx context.Context) ([]MCPTool, error) {
+	p.lists++
+	return p.ProviderFuncs.GetTools(ctx)
+}
+
+func (p *lookupCountingProvider) LookupTool(ctx context.Context, name string) (*MCPTool, error) {
+	p.lookups++
+	tools, _ := p.ProviderFuncs.GetTools(ctx)
+	for i := range tools {
+		if tools[i].Name == name {
+			return &tools[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func TestCallToolProviderLookup(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.SetPolicy(&Policy{})
+	tool := NewTool("p", "provider tool", Integer("n", "n", Required())).ToMCPTool()
+	owner := &lookupCountingProvider{ProviderFuncs: ProviderFuncs{
+		GetToolsFunc: func(ctx context.Context) ([]MCPTool, error) { return []MCPTool{tool}, nil },
+		ExecuteToolFunc: func(ctx context.Context, name string, params map[string]any) (*ToolResponse, error) {
+			return NewToolResponseText("ok"), nil
+		},
+	}}
+	other := &lookupCountingProvider{}
+	other.GetToolsFunc = func(ctx context.Context) ([]MCPTool, error) { return nil, nil }
+
+	ctx := WithToolProviders(context.Background(), owner, other)
+	if _, err := server.CallTool(ctx, "p", map[string]any{"n": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if owner.lookups != 1 || owner.lists != 0 {
+		t.Errorf("owner looked up %d times and listed %d times, want one lookup", owner.lookups, owner.lists)
+	}
+	if other.lookups != 0 || other.lists != 0 {
+		t.Errorf("providers after the owner should not be asked, got %d lookups and %d lists", other.lookups, other.lists)
+	}
+}
+
+func TestSchemaCacheReusesNormalizedSchema(t *testing.T) {
+	cache := newSchemaCache()
+	schema := map[string]any{"type": "object", "required": []string{"n"}}
+
+	first := cache.normalized("t", schema)
+	if second := cache.normalized("t", schema); !sameMap(first.(map[string]any), second.(map[string]any)) {
+		t.Error("the same schema should be normalized once")
+	}
+
+	replaced := map[string]any{"type": "object"}
+	if got := cache.normalized("t", replaced).(map[string]any); sameMap(got, first.(map[string]any)) || got["required"] != nil {
+		t.Errorf("a changed schema should be normalized again, got %v", got)
+	}
+}
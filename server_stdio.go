@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 
 	"github.com/paularlott/jsonrpc"
 )
@@ -67,6 +69,12 @@ func (s *Server) ServeStream(ctx context.Context, in io.Reader, out io.Writer, o
 	defer s.notifications.unsubscribe(subID)
 	defer sink.stop()
 
+	// Requests from the server to the client share the stream too, and their
+	// responses arrive in the read loop below.
+	requester := newStreamRequester(out, &writeMu)
+	defer requester.close()
+	ctx = withClientRequester(ctx, requester)
+
 	// Close the reader on ctx cancellation so decoder.Decode unblocks. Without
 	// this, Ctrl+C cancels the context but the read loop is stuck in Decode and
 	// the process hangs. os.Stdin (the common case) is an io.Closer.
@@ -93,6 +101,9 @@ func (s *Server) ServeStream(ctx context.Context, in io.Reader, out io.Writer, o
 		if len(bytes.TrimSpace(raw)) == 0 {
 			continue
 		}
+		if requester.receive(raw) {
+			continue
+		}
 		wg.Add(1)
 		go func(msg json.RawMessage) {
 			defer wg.Done()
@@ -154,6 +165,144 @@ func (sn *streamNotifySink) drain() {
 
 func (sn *streamNotifySink) stop() { close(sn.done) }
 
+// errStreamClosed is returned for requests to the client of a stream that has
+// ended.
+var errStreamClosed = errors.New("mcp: stream closed")
+
+// clientRequester sends requests from the server to the client of the request
+// being handled, on transports that allow it.
+type clientRequester interface {
+	request(ctx context.Context, method string, params, result any) error
+	supportsElicitation() bool
+}
+
+type clientRequesterKey struct{}
+
+func withClientRequester(ctx context.Context, r clientRequester) context.Context {
+	return context.WithValue(ctx, clientRequesterKey{}, r)
+}
+
+// clientRequesterFromContext returns the requester for the client of the
+// request on ctx, or nil if the transport has none.
+func clientRequesterFromContext(ctx context.Context) clientRequester {
+	r, _ := ctx.Value(clientRequesterKey{}).(clientRequester)
+	return r
+}
+
+// streamMessage holds the fields of an inbound stream message needed to tell
+// requests from responses to the server's own requests.
+type streamMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		Capabilities map[string]any `json:"capabilities"`
+	} `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *MCPError       `json:"error"`
+}
+
+// streamRequester is the clientRequester of a stream. Requests are written under
+// the stream's write mutex and their responses are passed to receive by the
+// read loop.
+type streamRequester struct {
+	out         io.Writer
+	mu          *sync.Mutex
+	elicitation atomic.Bool // The client declared the elicitation capability
+
+	pendingMu sync.Mutex
+	nextID    int
+	pending   map[string]chan *streamMessage
+	done      chan struct{}
+}
+
+func newStreamRequester(out io.Writer, mu *sync.Mutex) *streamRequester {
+	return &streamRequester{
+		out:     out,
+		mu:      mu,
+		pending: make(map[string]chan *streamMessage),
+		done:    make(chan struct{}),
+	}
+}
+
+// receive handles raw if it is a response to one of the server's requests,
+// and notes the client's capabilities from its initialize request. It reports
+// whether raw was a response.
+func (c *streamRequester) receive(raw json.RawMessage) bool {
+	var msg streamMessage
+	if raw[0] != '{' || json.Unmarshal(raw, &msg) != nil {
+		return false
+	}
+	if msg.Method == "initialize" {
+		_, ok := msg.Params.Capabilities["elicitation"]
+		c.elicitation.Store(ok)
+	}
+	if msg.Method != "" || len(msg.ID) == 0 {
+		return false
+	}
+
+	var id string
+	if json.Unmarshal(msg.ID, &id) != nil {
+		return true // Not one of ours
+	}
+	c.pendingMu.Lock()
+	ch, ok := c.pending[id]
+	delete(c.pending, id)
+	c.pendingMu.Unlock()
+	if ok {
+		ch <- &msg
+	}
+	return true
+}
+
+func (c *streamRequester) supportsElicitation() bool {
+	return c.elicitation.Load()
+}
+
+func (c *streamRequester) request(ctx context.Context, method string, params, result any) error {
+	c.pendingMu.Lock()
+	c.nextID++
+	id := fmt.Sprintf("server-%d", c.nextID)
+	ch := make(chan *streamMessage, 1)
+	c.pending[id] = ch
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}()
+
+	payload, err := json.Marshal(MCPRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	_, err = c.out.Write(append(payload, '\n'))
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return fmt.Errorf("%s: %s", method, msg.Error.Message)
+		}
+		if result == nil || len(msg.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-c.done:
+		return errStreamClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close fails the requests still waiting for a response.
+func (c *streamRequester) close() {
+	close(c.done)
+}
+
 // newStdioDispatcher builds the jsonrpc.Server that frames and dispatches MCP
 // methods over a stream. The MCP protocol is JSON-RPC 2.0, so the framing,
 // batching and notification handling are provided by the jsonrpc package; the
@@ -255,7 +404,7 @@ func (s *Server) stdioToolsCall(ctx context.Context, raw json.RawMessage) (any,
 	return ToolResult{
 		Content:           response.Content,
 		StructuredContent: response.StructuredContent,
-		IsError:           false,
+		IsError:           response.IsError,
 	}, nil
 }
 
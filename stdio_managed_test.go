@@ -0,0 +1,249 @@
+package mcp_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paularlott/mcp"
+)
+
+const managedChildEnv = "MCP_TEST_STDIO_MANAGED"
+
+// registerManagedTestTools adds tools for the managed stdio tests: "pid"
+// reports the child's process id and "crash" makes it exit.
+func registerManagedTestTools(s *mcp.Server) {
+	s.RegisterTool(mcp.NewTool("pid", "Report the process id"),
+		func(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+			return mcp.NewToolResponseText(strconv.Itoa(os.Getpid())), nil
+		})
+	s.RegisterTool(mcp.NewTool("crash", "Exit the process"),
+		func(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+			os.Exit(3)
+			return nil, nil
+		})
+}
+
+// managedStdioConfig returns a config that runs the test binary as a stdio
+// server, counting its exits.
+func managedStdioConfig(t *testing.T, exits *atomic.Int32) mcp.StdioServerConfig {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	return mcp.StdioServerConfig{
+		Command: exe,
+		Env:     []string{stdioChildEnv + "=1", managedChildEnv + "=1"},
+		OnExit:  func(error) { exits.Add(1) },
+	}
+}
+
+func callPID(t *testing.T, client *mcp.Client, name string) string {
+	t.Helper()
+	resp, err := client.CallTool(context.Background(), name, nil)
+	if err != nil {
+		t.Fatalf("CallTool %s: %v", name, err)
+	}
+	return resp.Content[0].Text
+}
+
+func waitForExits(t *testing.T, exits *atomic.Int32, n int32) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for exits.Load() < n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := exits.Load(); got < n {
+		t.Fatalf("expected %d process exits, got %d", n, got)
+	}
+}
+
+func TestManagedStdioIdleStopAndRestart(t *testing.T) {
+	var exits atomic.Int32
+	cfg := managedStdioConfig(t, &exits)
+	cfg.IdleTimeout = 100 * time.Millisecond
+	client := mcp.NewManagedStdioClient(cfg, "")
+	defer client.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	if exits.Load() != 0 {
+		t.Fatal("no process should run before the first request")
+	}
+
+	first := callPID(t, client, "pid")
+	waitForExits(t, &exits, 1)
+
+	// The next request starts a new process and initializes it again.
+	second := callPID(t, client, "pid")
+	if first == second {
+		t.Errorf("expected a new process after idle stop, got pid %s twice", first)
+	}
+}
+
+func TestManagedStdioCrashRestart(t *testing.T) {
+	var exits atomic.Int32
+	client := mcp.NewManagedStdioClient(managedStdioConfig(t, &exits), "local")
+	defer client.Close()
+
+	first := callPID(t, client, "local__pid")
+	if _, err := client.CallTool(context.Background(), "local__crash", nil); err == nil {
+		t.Fatal("expected an error from a crashing server")
+	}
+	waitForExits(t, &exits, 1)
+
+	second := callPID(t, client, "local__pid")
+	if first == second {
+		t.Errorf("expected a restarted process, got pid %s twice", first)
+	}
+}
+
+func TestManagedStdioClose(t *testing.T) {
+	var exits atomic.Int32
+	client := mcp.NewManagedStdioClient(managedStdioConfig(t, &exits), "")
+	callPID(t, client, "pid")
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	waitForExits(t, &exits, 1)
+	if _, err := client.CallTool(context.Background(), "pid", nil); err == nil {
+		t.Error("expected an error after Close")
+	}
+}
+
+func TestReplaceRemoteServersStdio(t *testing.T) {
+	var exits atomic.Int32
+	cfg := managedStdioConfig(t, &exits)
+	server := mcp.NewServer("gateway", "1.0")
+	entries := []mcp.RemoteServerEntry{{Stdio: &cfg, Namespace: "local"}}
+	if err := server.ReplaceRemoteServers(entries); err != nil {
+		t.Fatalf("ReplaceRemoteServers: %v", err)
+	}
+	ctx := context.Background()
+
+	resp, err := server.CallTool(ctx, "local__greet", map[string]any{"name": "Ada"})
+	if err != nil || resp.Content[0].Text != "Hello, Ada!" {
+		t.Fatalf("CallTool local__greet: %+v, %v", resp, err)
+	}
+	pidOf := func() string {
+		resp, err := server.CallTool(ctx, "local__pid", nil)
+		if err != nil {
+			t.Fatalf("CallTool local__pid: %v", err)
+		}
+		return resp.Content[0].Text
+	}
+	first := pidOf()
+
+	// An unchanged entry keeps its process.
+	if err := server.ReplaceRemoteServers(entries); err != nil {
+		t.Fatalf("ReplaceRemoteServers: %v", err)
+	}
+	if pid := pidOf(); pid != first {
+		t.Errorf("process should be reused, pid %s became %s", first, pid)
+	}
+	if exits.Load() != 0 {
+		t.Error("no process should have exited")
+	}
+
+	// A removed entry has its process stopped.
+	if err := server.ReplaceRemoteServers(nil); err != nil {
+		t.Fatalf("ReplaceRemoteServers: %v", err)
+	}
+	waitForExits(t, &exits, 1)
+
+	if err := server.ReplaceRemoteServers([]mcp.RemoteServerEntry{{}}); err == nil {
+		t.Error("expected an error for an entry with neither Client nor Stdio")
+	}
+}
+
+func TestRemoteProviderStdio(t *testing.T) {
+	var exits atomic.Int32
+	cfg := managedStdioConfig(t, &exits)
+	provider := mcp.NewRemoteProvider(func(ctx context.Context) ([]mcp.RemoteProviderConfig, error) {
+		user, _ := ctx.Value(userKey{}).(string)
+		return []mcp.RemoteProviderConfig{{
+			Name:       "local",
+			Stdio:      &cfg,
+			ProcessKey: "local/" + user,
+			ToolFilter: func(name string) bool { return name != "crash" },
+		}}, nil
+	})
+	defer provider.Close()
+	server := mcp.NewServer("gateway", "1.0")
+
+	pidFor := func(user string) string {
+		ctx := mcp.WithToolProviders(context.WithValue(context.Background(), userKey{}, user), provider)
+		resp, err := server.CallTool(ctx, "local__pid", nil)
+		if err != nil {
+			t.Fatalf("CallTool local__pid for %s: %v", user, err)
+		}
+		return resp.Content[0].Text
+	}
+
+	ctx := mcp.WithToolProviders(context.WithValue(context.Background(), userKey{}, "ada"), provider)
+	tools, err := provider.GetTools(ctx)
+	if err != nil {
+		t.Fatalf("GetTools: %v", err)
+	}
+	for _, tool := range tools {
+		if tool.Name == "local__crash" {
+			t.Error("filtered tool should not be listed")
+		}
+	}
+	if len(tools) != 4 {
+		t.Errorf("expected 4 tools, got %d", len(tools))
+	}
+
+	ada := pidFor("ada")
+	if again := pidFor("ada"); again != ada {
+		t.Errorf("requests with the same ProcessKey should share a process: %s, %s", ada, again)
+	}
+	if bob := pidFor("bob"); bob == ada {
+		t.Error("requests with different ProcessKeys should not share a process")
+	}
+
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	waitForExits(t, &exits, 2)
+}
+
+type userKey struct{}
+
+func TestStdioClientRespawn(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	var exits, reconnects atomic.Int32
+	client, err := mcp.NewStdioClient(exe, nil, "local",
+		mcp.WithClientExtraEnv(stdioChildEnv+"=1", managedChildEnv+"=1"),
+		mcp.WithClientOnExit(func(error) { exits.Add(1) }),
+		mcp.WithClientRespawn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.OnReconnect(func() { reconnects.Add(1) })
+
+	first := callPID(t, client, "local__pid")
+	if _, err := client.CallTool(context.Background(), "local__crash", nil); err == nil {
+		t.Fatal("expected an error from a crashing server")
+	}
+	waitForExits(t, &exits, 1)
+
+	second := callPID(t, client, "local__pid")
+	if first == second {
+		t.Errorf("expected a respawned process, got pid %s twice", first)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for reconnects.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if reconnects.Load() != 1 {
+		t.Errorf("OnReconnect fired %d times, want 1", reconnects.Load())
+	}
+}
@@ -60,7 +60,11 @@ const stdioChildEnv = "MCP_TEST_STDIO_CHILD"
 func TestMain(m *testing.M) {
 	if os.Getenv(stdioChildEnv) == "1" {
 		// Child mode: act as an MCP stdio server.
-		_ = buildStdioTestServer().ServeStdio(context.Background())
+		s := buildStdioTestServer()
+		if os.Getenv(managedChildEnv) == "1" {
+			registerManagedTestTools(s)
+		}
+		_ = s.ServeStdio(context.Background())
 		return
 	}
 	os.Exit(m.Run())
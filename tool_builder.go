@@ -8,8 +8,17 @@ type ToolBuilder struct {
 	description  string
 	params       []paramDef
 	outputParams []paramDef
-	discoverable bool     // If true, tool is discoverable via tool_search but not in tools/list
-	keywords     []string // Keywords for discovery search
+	defs         []paramDef // Reusable schemas emitted under $defs
+	discoverable bool       // If true, tool is discoverable via tool_search but not in tools/list
+	keywords     []string   // Keywords for discovery search
+	category     string     // tool_search facet
+	tags         []string   // tool_search facets
+	annotations  *ToolAnnotations
+	approval     *ApprovalConfig // Calls wait for approval when set
+
+	// Prebuilt schemas used instead of params/outputParams (see NewTypedTool)
+	inputSchema  map[string]any
+	outputSchema map[string]any
 }
 
 type paramDef struct {
@@ -18,14 +27,23 @@ type paramDef struct {
 	description string
 	required    bool
 	properties  map[string]*paramDef // For object types
-	itemSchema  *paramDef            // For array types with complex items
+	itemSchema  *paramDef            // For array types with complex items, and map values
+	variants    []*paramDef          // For oneOf/anyOf types
+	ref         string               // For ref types, the $defs entry name
+	constraints paramConstraints     // Schema keywords set through options
 }
 
 func (t *ToolBuilder) buildSchema() map[string]any {
+	if t.inputSchema != nil {
+		return t.inputSchema
+	}
 	return t.buildSchemaFromParams(t.params)
 }
 
 func (t *ToolBuilder) buildOutputSchema() map[string]any {
+	if t.outputSchema != nil {
+		return t.outputSchema
+	}
 	if len(t.outputParams) == 0 {
 		return nil
 	}
@@ -87,10 +105,28 @@ func (t *ToolBuilder) buildSchemaFromParams(params []paramDef) map[string]any {
 	if len(required) > 0 {
 		schema["required"] = required
 	}
+	if len(t.defs) > 0 {
+		defs := make(map[string]any, len(t.defs))
+		for i := range t.defs {
+			defs[t.defs[i].name] = t.buildNestedSchema(&t.defs[i])
+		}
+		schema["$defs"] = defs
+	}
+	return schema
+}
+
+// buildNestedSchema builds the schema for a parameter used as an array item,
+// map value, union variant or definition, including its description.
+func (t *ToolBuilder) buildNestedSchema(param *paramDef) map[string]any {
+	schema := t.buildParamSchema(param)
+	if param.description != "" {
+		schema["description"] = param.description
+	}
 	return schema
 }
 
 func (t *ToolBuilder) buildParamSchema(param *paramDef) map[string]any {
+	var schema map[string]any
 	if strings.HasPrefix(param.paramType, "array:") {
 		itemType := strings.TrimPrefix(param.paramType, "array:")
 
@@ -99,19 +135,46 @@ func (t *ToolBuilder) buildParamSchema(param *paramDef) map[string]any {
 			// Array of objects with defined schema
 			itemSchema = t.buildObjectSchema(param.itemSchema)
 		} else {
-			// Array of primitives
+			// Array of primitives; value constraints apply to each element
 			itemSchema = map[string]any{"type": itemType}
+			param.constraints.applyValueConstraints(itemSchema)
 		}
 
-		return map[string]any{
+		schema = map[string]any{
 			"type":  "array",
 			"items": itemSchema,
 		}
+		param.constraints.applyArrayConstraints(schema)
 	} else if param.paramType == "object" {
-		return t.buildObjectSchema(param)
+		schema = t.buildObjectSchema(param)
+	} else if param.paramType == "array" {
+		schema = map[string]any{
+			"type":  "array",
+			"items": t.buildNestedSchema(param.itemSchema),
+		}
+		param.constraints.applyArrayConstraints(schema)
+	} else if param.paramType == "map" {
+		schema = map[string]any{
+			"type":                 "object",
+			"additionalProperties": t.buildNestedSchema(param.itemSchema),
+		}
+	} else if param.paramType == "oneOf" || param.paramType == "anyOf" {
+		variants := make([]any, len(param.variants))
+		for i, variant := range param.variants {
+			variants[i] = t.buildNestedSchema(variant)
+		}
+		schema = map[string]any{param.paramType: variants}
+	} else if param.paramType == "ref" {
+		schema = map[string]any{"$ref": "#/$defs/" + param.ref}
 	} else {
-		return map[string]any{"type": param.paramType}
+		schema = map[string]any{"type": param.paramType}
+		param.constraints.applyValueConstraints(schema)
+	}
+
+	if param.constraints.hasDefault {
+		schema["default"] = param.constraints.defaultValue
 	}
+	return schema
 }
 
 func (t *ToolBuilder) buildObjectSchema(param *paramDef) map[string]any {
@@ -170,6 +233,46 @@ func (t *ToolBuilder) Keywords() []string {
 	return t.keywords
 }
 
+// Category places the tool in a category, such as "github" or "billing".
+// tool_search can filter by category and reports how many matching tools are
+// in each, so agents can browse a large catalogue one category at a time.
+func (t *ToolBuilder) Category(category string) *ToolBuilder {
+	t.category = category
+	return t
+}
+
+// Tags labels the tool for faceted tool_search, such as "issues" or "admin".
+// Unlike keywords, which are matched against the query, tags are exact
+// filter values. Repeated tags are kept once.
+func (t *ToolBuilder) Tags(tags ...string) *ToolBuilder {
+	t.tags = uniqueStrings(tags)
+	return t
+}
+
+// Annotations sets the MCP behaviour hints sent to clients with the tool.
+func (t *ToolBuilder) Annotations(annotations ToolAnnotations) *ToolBuilder {
+	t.annotations = &annotations
+	return t
+}
+
+// ReadOnly annotates the tool as not modifying its environment. Clients may
+// use this to skip confirmation, and tool_search can filter on it.
+func (t *ToolBuilder) ReadOnly() *ToolBuilder {
+	if t.annotations == nil {
+		t.annotations = &ToolAnnotations{}
+	}
+	t.annotations.ReadOnlyHint = true
+	return t
+}
+
+// RequireApproval makes every call to the tool wait for an approver to allow
+// it; rejected calls return an isError result without running the handler.
+// It applies to tools registered on a Server. See ApprovalConfig.
+func (t *ToolBuilder) RequireApproval(cfg ApprovalConfig) *ToolBuilder {
+	t.approval = &cfg
+	return t
+}
+
 // ToMCPTool converts the ToolBuilder to an MCPTool struct.
 // This is useful for tool providers that use the fluent API to build tools
 // but need to return MCPTool structs from their GetTools method.
@@ -185,7 +288,10 @@ func (t *ToolBuilder) ToMCPTool() MCPTool {
 		Name:        t.name,
 		Description: t.Description(),
 		InputSchema: t.buildSchema(),
+		Annotations: t.annotations,
 		Keywords:    t.keywords,
+		Category:    t.category,
+		Tags:        t.tags,
 		Visibility:  visibility,
 	}
 	if outputSchema := t.buildOutputSchema(); outputSchema != nil {
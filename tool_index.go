@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ToolQuery is a tool_search request as seen by a ToolIndex.
+type ToolQuery struct {
+	// Text is the search text. An empty Text lists every tool.
+	Text string
+
+	// MaxResults limits the number of results; 0 means no limit.
+	MaxResults int
+
+	// Tools are request-scoped tools to rank alongside the indexed ones:
+	// discoverable tools from context providers and tools already in
+	// tools/list. An indexed tool shadows a request-scoped tool of the same
+	// name.
+	Tools []MCPTool
+
+	// Filter, when set, excludes every tool for which it returns false.
+	Filter func(tool *MCPTool) bool
+}
+
+// ToolIndex ranks tools for tool_search. The server keeps the index in step
+// with its discoverable tools, calling Index when a tool is registered or
+// refreshed from a remote server and Remove when it goes away; Search is
+// called for every tool_search request.
+//
+// The default index is created with NewToolIndex. Replace it with
+// Server.SetToolIndex to rank tools another way, such as by usage, per
+// tenant, or with an external search service. Implementations must be safe
+// for concurrent use.
+//
+// Index and Remove are called one at a time, in the order tools were
+// registered and removed, and without the server's locks held, so a slow
+// index does not hold up other requests and may read from the server. They
+// must not run tool_search themselves, as tool_search waits for pending
+// changes to reach the index first. Search may run alongside them.
+type ToolIndex interface {
+	// Index adds a tool, replacing any indexed tool with the same name. The
+	// tool's Keywords are the words it should be found by.
+	Index(tool MCPTool)
+
+	// Remove drops the tool with the given name. Removing an unknown name is
+	// not an error.
+	Remove(name string)
+
+	// Search returns the tools matching the query, best first. Scores should
+	// stay in the 0–1 range, with 1 for an exact name match, so they can be
+	// merged with results from remote servers. Build results with
+	// NewSearchResult, and apply query.Filter before limiting the results.
+	Search(ctx context.Context, query ToolQuery) ([]SearchResult, error)
+}
+
+// SetToolIndex replaces the index tool_search ranks local tools with. Every
+// discoverable tool already registered is indexed straight away, and later
+// registrations, remote refreshes and removals are passed on. Pass nil to
+// restore the built-in index, which uses the mode set with SetToolSearchMode.
+//
+// Semantic search (SetSemanticToolSearch) still applies on top: it ranks the
+// tools this index lists for an empty query, blending its similarity scores
+// with the scores from this index, so a tool the index hides stays hidden. Remote servers with
+// remote search enabled are searched separately and merged as before.
+func (s *Server) SetToolIndex(index ToolIndex) {
+	s.internalRegistry.setIndex(index)
+}
+
+// lexicalToolIndex is the default ToolIndex. It ranks tools with the
+// heuristic scorer or BM25, depending on its mode.
+type lexicalToolIndex struct {
+	mu    sync.RWMutex
+	tools map[string]MCPTool
+	bm25  *bm25Index // Kept in step with tools; used in ToolSearchBM25 mode
+	mode  ToolSearchMode
+}
+
+// Ensure lexicalToolIndex implements ToolIndex
+var _ ToolIndex = (*lexicalToolIndex)(nil)
+
+// NewToolIndex creates the built-in in-memory index, ranking tools as
+// SetToolSearchMode describes. It is useful as a base for a custom index that
+// adjusts the built-in scores:
+//
+//	type boostedIndex struct{ mcp.ToolIndex }
+//
+//	func (b boostedIndex) Search(ctx context.Context, q mcp.ToolQuery) ([]mcp.SearchResult, error) {
+//		results, err := b.ToolIndex.Search(ctx, q)
+//		// re-weight results ...
+//		return results, err
+//	}
+//
+//	server.SetToolIndex(boostedIndex{mcp.NewToolIndex(mcp.ToolSearchBM25)})
+func NewToolIndex(mode ToolSearchMode) ToolIndex {
+	return newLexicalToolIndex(mode)
+}
+
+func newLexicalToolIndex(mode ToolSearchMode) *lexicalToolIndex {
+	return &lexicalToolIndex{
+		tools: make(map[string]MCPTool),
+		bm25:  newBM25Index(),
+		mode:  mode,
+	}
+}
+
+// Index adds or replaces a tool.
+func (x *lexicalToolIndex) Index(tool MCPTool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.tools[tool.Name] = tool
+	x.bm25.add(tool.Name, tool.Description, tool.Keywords)
+}
+
+// Remove drops a tool by name.
+func (x *lexicalToolIndex) Remove(name string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.tools, name)
+	x.bm25.remove(name)
+}
+
+// setMode selects the ranking used by Search.
+func (x *lexicalToolIndex) setMode(mode ToolSearchMode) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.mode = mode
+}
+
+// Search ranks the indexed and request-scoped tools against the query.
+func (x *lexicalToolIndex) Search(ctx context.Context, query ToolQuery) ([]SearchResult, error) {
+	queryLower := strings.ToLower(strings.TrimSpace(query.Text))
+	listAll := queryLower == ""
+
+	x.mu.RLock()
+	useBM25 := x.mode == ToolSearchBM25 && !listAll
+	candidates := make([]MCPTool, 0, len(x.tools)+len(query.Tools))
+	for _, tool := range x.tools {
+		candidates = append(candidates, tool)
+	}
+	seen := make(map[string]bool, cap(candidates))
+	for _, tool := range candidates {
+		seen[tool.Name] = true
+	}
+	extra := newBM25Index()
+	for _, tool := range query.Tools {
+		if seen[tool.Name] {
+			continue
+		}
+		seen[tool.Name] = true
+		candidates = append(candidates, tool)
+		if useBM25 {
+			extra.add(tool.Name, tool.Description, tool.Keywords)
+		}
+	}
+
+	var bm25Scores map[string]float64
+	if useBM25 {
+		// Request-scoped tools are indexed on the fly and ranked together
+		// with the indexed ones so both share the same corpus statistics.
+		bm25Scores = bm25Score(queryLower, x.bm25, extra)
+	}
+	x.mu.RUnlock()
+
+	var results []SearchResult
+	for i := range candidates {
+		tool := &candidates[i]
+		if query.Filter != nil && !query.Filter(tool) {
+			continue
+		}
+
+		var score float64
+		switch {
+		case listAll:
+			score = 1.0
+		case strings.ToLower(tool.Name) == queryLower:
+			score = 1.0
+		case useBM25:
+			score = bm25Scores[tool.Name]
+		default:
+			score = calculateScore(queryLower, tool.Name, tool.Description, tool.Keywords)
+		}
+		if score > 0 {
+			results = append(results, NewSearchResult(tool, score))
+		}
+	}
+
+	sortSearchResults(results)
+	if query.MaxResults > 0 && len(results) > query.MaxResults {
+		results = results[:query.MaxResults]
+	}
+	return results, nil
+}
+
+// sortSearchResults orders results by score descending, then by name.
+func sortSearchResults(results []SearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Name < results[j].Name
+	})
+}
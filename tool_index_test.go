@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// prefixIndex is a custom ToolIndex that matches tools whose name starts with
+// the query text.
+type prefixIndex struct {
+	mu    sync.Mutex
+	tools map[string]MCPTool
+	err   error
+}
+
+func (p *prefixIndex) Index(tool MCPTool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tools[tool.Name] = tool
+}
+
+func (p *prefixIndex) Remove(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tools, name)
+}
+
+func (p *prefixIndex) Search(ctx context.Context, query ToolQuery) ([]SearchResult, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var results []SearchResult
+	for _, tool := range p.tools {
+		if strings.HasPrefix(tool.Name, query.Text) && (query.Filter == nil || query.Filter(&tool)) {
+			results = append(results, SearchResult{Name: tool.Name, Score: 0.5, Keywords: tool.Keywords})
+		}
+	}
+	sortSearchResults(results)
+	return results, nil
+}
+
+func TestSetToolIndex(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("send_email", "Send an email").Discoverable("mail"), nil)
+
+	index := &prefixIndex{tools: make(map[string]MCPTool)}
+	server.SetToolIndex(index)
+	if tool, ok := index.tools["send_email"]; !ok || len(tool.Keywords) != 1 || tool.Keywords[0] != "mail" {
+		t.Fatalf("existing tools should be indexed with their keywords: %+v", index.tools)
+	}
+
+	server.RegisterTool(NewTool("send_sms", "Send a text message").Discoverable(), nil)
+	results := searchTools(t, server, "send")
+	if len(results) != 2 || results[0].Name != "send_email" || results[1].Name != "send_sms" {
+		t.Fatalf("expected both send tools from the custom index, got %+v", results)
+	}
+
+	server.UnregisterTool("send_sms")
+	if _, ok := index.tools["send_sms"]; ok {
+		t.Error("removed tool should be dropped from the custom index")
+	}
+
+	index.err = errors.New("search backend down")
+	if _, err := server.CallTool(context.Background(), ToolSearchName, map[string]any{"query": "send"}); err == nil {
+		t.Error("index errors should fail the search")
+	}
+
+	// nil restores the built-in index, keeping the search mode.
+	server.SetToolSearchMode(ToolSearchBM25)
+	server.SetToolIndex(nil)
+	if results := searchTools(t, server, "emailing"); len(results) != 1 || results[0].Name != "send_email" {
+		t.Errorf("built-in BM25 index should be restored, got %+v", results)
+	}
+}
+
+func TestToolIndexFilter(t *testing.T) {
+	index := NewToolIndex(ToolSearchHeuristic)
+	index.Index(MCPTool{Name: "create_issue", Description: "Create an issue"})
+	index.Index(MCPTool{Name: "close_issue", Description: "Close an issue"})
+
+	results, err := index.Search(context.Background(), ToolQuery{
+		Text:   "issue",
+		Tools:  []MCPTool{{Name: "list_issues", Description: "List issues"}, {Name: "create_issue", Description: "shadowed"}},
+		Filter: func(tool *MCPTool) bool { return !strings.HasPrefix(tool.Name, "close") },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected create_issue and list_issues, got %+v", results)
+	}
+	for _, r := range results {
+		if r.Name == "create_issue" && r.Description != "Create an issue" {
+			t.Errorf("indexed tool should shadow the request-scoped one: %+v", r)
+		}
+	}
+}
+
+// callbackIndex reads from the server while indexing, and blocks until
+// released.
+type callbackIndex struct {
+	prefixIndex
+	server  *Server
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *callbackIndex) Index(tool MCPTool) {
+	if tool.Name == "slow" {
+		close(c.started)
+		<-c.release
+	}
+	c.server.ListTools()
+	c.prefixIndex.Index(tool)
+}
+
+func TestToolIndexCalledWithoutLocks(t *testing.T) {
+	server := NewServer("test", "1.0")
+	index := &callbackIndex{
+		prefixIndex: prefixIndex{tools: make(map[string]MCPTool)},
+		server:      server,
+		started:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	server.SetToolIndex(index)
+	server.RegisterTool(NewTool("fast", "Fast").Discoverable(), nil)
+
+	done := make(chan struct{})
+	go func() {
+		server.RegisterTool(NewTool("slow", "Slow").Discoverable(), nil)
+		close(done)
+	}()
+	<-index.started
+
+	// Readers and writers are not held up while the index works.
+	server.ListTools()
+	if server.internalRegistry.lookupTool("slow") == nil {
+		t.Error("the registry should hold the tool while it is being indexed")
+	}
+	server.RegisterTool(NewTool("native", "Native"), nil)
+
+	close(index.release)
+	<-done
+	if results := searchTools(t, server, "slow"); len(results) != 1 {
+		t.Errorf("expected the slow tool once indexed, got %+v", results)
+	}
+}
@@ -41,8 +41,12 @@ type ToolProvider interface {
 
 // Discovery tool names
 const (
-	ToolSearchName  = "tool_search"
-	ExecuteToolName = "execute_tool"
+	ToolSearchName     = "tool_search"
+	ExecuteToolName    = "execute_tool"
+	ResourceSearchName = "resource_search"
+	ReadResourceName   = "read_resource"
+	PromptSearchName   = "prompt_search"
+	GetPromptName      = "get_prompt"
 )
 
 // toolProvidersKey is the context key for tool providers
@@ -276,6 +280,62 @@ func getNativeToolsFromProviders(ctx context.Context) []MCPTool {
 	return tools
 }
 
+// ToolLookup is an optional interface for ToolProviders that can find one of
+// their tools by name without listing all of them. The server uses it to
+// find the schemas and annotations of a tool being called.
+type ToolLookup interface {
+	// LookupTool returns the tool called name, or nil if the provider does
+	// not list it.
+	LookupTool(ctx context.Context, name string) (*MCPTool, error)
+}
+
+// providerToolKey is the request memo key for a providerTool lookup.
+type providerToolKey struct{ name string }
+
+// providerTool returns the descriptor advertised for name by the first
+// provider in the context that lists it, or nil if none does. The result is
+// memoized for the request, so the policy check and argument validation
+// share one lookup.
+func providerTool(ctx context.Context, name string) *MCPTool {
+	providers := GetToolProviders(ctx)
+	if len(providers) == 0 {
+		return nil
+	}
+	v, _ := memoizeRequest(ctx, providerToolKey{name}, func() (any, error) {
+		for _, provider := range providers {
+			if tool := lookupProviderTool(ctx, provider, name); tool != nil {
+				return tool, nil
+			}
+		}
+		return nil, nil
+	})
+	tool, _ := v.(*MCPTool)
+	return tool
+}
+
+// lookupProviderTool returns the tool called name from provider, using
+// LookupTool when the provider has it and otherwise searching its list.
+// Providers that fail are treated as not listing the tool.
+func lookupProviderTool(ctx context.Context, provider ToolProvider, name string) *MCPTool {
+	if lookup, ok := provider.(ToolLookup); ok {
+		tool, err := lookup.LookupTool(ctx, name)
+		if err != nil {
+			return nil
+		}
+		return tool
+	}
+	tools, err := provider.GetTools(ctx)
+	if err != nil {
+		return nil
+	}
+	for i := range tools {
+		if tools[i].Name == name {
+			return &tools[i]
+		}
+	}
+	return nil
+}
+
 // callToolFromProviders tries to call a tool from the providers in the context.
 // Returns ToolResponse, error - returns ErrUnknownTool if no provider handles the tool.
 func callToolFromProviders(ctx context.Context, name string, params map[string]any) (*ToolResponse, error) {
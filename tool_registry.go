@@ -11,17 +11,56 @@ import (
 
 // SearchResult represents a tool found via search
 type SearchResult struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Score       float64  `json:"score"`
-	InputSchema any      `json:"inputSchema,omitempty"`
-	Keywords    []string `json:"keywords,omitempty"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Score        float64  `json:"score"`
+	InputSchema  any      `json:"inputSchema,omitempty"`
+	OutputSchema any      `json:"outputSchema,omitempty"`
+	Keywords     []string `json:"keywords,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	ReadOnly     bool     `json:"readOnly,omitempty"`
+
+	Annotations *ToolAnnotations `json:"annotations,omitempty"` // The tool's behaviour hints, which policies match on
+}
+
+// NewSearchResult describes tool as a search result with the given score,
+// copying its schemas, keywords, category, tags and annotations. Custom
+// ToolIndex implementations should use it so faceted search sees the same
+// fields as with the built-in index.
+func NewSearchResult(tool *MCPTool, score float64) SearchResult {
+	return SearchResult{
+		Name:         tool.Name,
+		Description:  tool.Description,
+		Score:        score,
+		InputSchema:  tool.InputSchema,
+		OutputSchema: tool.OutputSchema,
+		Keywords:     tool.Keywords,
+		Category:     tool.Category,
+		Tags:         tool.Tags,
+		ReadOnly:     tool.readOnly(),
+		Annotations:  tool.Annotations,
+	}
 }
 
 // internalRegistry implements ToolRegistry and provides tool search functionality
 type internalRegistry struct {
-	mu    sync.RWMutex
-	tools map[string]*internalRegisteredTool
+	mu      sync.RWMutex
+	tools   map[string]*internalRegisteredTool
+	index   ToolIndex      // Kept in step with tools; ranks tool_search results
+	mode    ToolSearchMode // Applied to the default index
+	pending []indexUpdate  // Changes to tools not yet passed to index, oldest first
+
+	// indexMu is held while passing changes to index, without mu, so a slow
+	// index does not block the registry and changes reach it in order.
+	indexMu sync.Mutex
+}
+
+// indexUpdate is a change to the registry to pass on to the index: a tool to
+// index, or the name of one to remove.
+type indexUpdate struct {
+	tool   MCPTool
+	remove string
 }
 
 // internalRegisteredTool holds a tool registered with the internal registry
@@ -35,6 +74,7 @@ type internalRegisteredTool struct {
 func newInternalRegistry() *internalRegistry {
 	return &internalRegistry{
 		tools: make(map[string]*internalRegisteredTool),
+		index: newLexicalToolIndex(ToolSearchHeuristic),
 	}
 }
 
@@ -50,7 +90,10 @@ func (r *internalRegistry) RegisterTool(tool *ToolBuilder, handler ToolHandler,
 		Name:        tool.Name(),
 		Description: tool.Description(),
 		InputSchema: schema,
+		Annotations: tool.annotations,
 		Keywords:    keywords,
+		Category:    tool.category,
+		Tags:        tool.tags,
 	}
 	if outputSchema != nil {
 		mcpTool.OutputSchema = outputSchema
@@ -61,6 +104,7 @@ func (r *internalRegistry) RegisterTool(tool *ToolBuilder, handler ToolHandler,
 		keywords: keywords,
 		handler:  handler,
 	}
+	r.pending = append(r.pending, indexUpdate{tool: *mcpTool})
 }
 
 // RegisterMCPTool registers an already-built MCPTool
@@ -77,6 +121,9 @@ func (r *internalRegistry) RegisterMCPTool(tool *MCPTool, handler ToolHandler, k
 		keywords: allKeywords,
 		handler:  handler,
 	}
+	indexed := *tool
+	indexed.Keywords = allKeywords
+	r.pending = append(r.pending, indexUpdate{tool: indexed})
 }
 
 // UnregisterTool removes a tool by name from the registry.
@@ -84,6 +131,112 @@ func (r *internalRegistry) UnregisterTool(name string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	delete(r.tools, name)
+	r.pending = append(r.pending, indexUpdate{remove: name})
+}
+
+// applyIndexUpdates passes the registrations and removals made since the last
+// call on to the index, in the order they were made, without the registry's
+// lock held. The server calls it once its own lock is released. If another
+// goroutine is already passing changes on, it returns at once and leaves that
+// goroutine to pass on these too.
+func (r *internalRegistry) applyIndexUpdates() {
+	for r.indexMu.TryLock() {
+		r.drainIndexUpdatesLocked()
+		r.indexMu.Unlock()
+
+		// Changes made just before the unlock found indexMu held and were
+		// left for this goroutine.
+		r.mu.RLock()
+		pending := len(r.pending)
+		r.mu.RUnlock()
+		if pending == 0 {
+			return
+		}
+	}
+}
+
+// drainIndexUpdatesLocked passes pending changes to the index until there are
+// none left. The caller must hold indexMu.
+func (r *internalRegistry) drainIndexUpdatesLocked() {
+	for {
+		r.mu.Lock()
+		updates, index := r.pending, r.index
+		r.pending = nil
+		r.mu.Unlock()
+		if len(updates) == 0 {
+			return
+		}
+
+		for _, u := range updates {
+			if u.remove != "" {
+				index.Remove(u.remove)
+			} else {
+				index.Index(u.tool)
+			}
+		}
+	}
+}
+
+// setSearchMode selects the ranking used by the default index. It has no
+// effect on a custom index until the default is restored.
+func (r *internalRegistry) setSearchMode(mode ToolSearchMode) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mode = mode
+	if x, ok := r.index.(*lexicalToolIndex); ok {
+		x.setMode(mode)
+	}
+}
+
+// setIndex replaces the index, loading every registered tool into it without
+// holding the registry's lock. A nil index restores the default.
+func (r *internalRegistry) setIndex(index ToolIndex) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	r.mu.Lock()
+	if index == nil {
+		index = newLexicalToolIndex(r.mode)
+	}
+	tools := make([]MCPTool, 0, len(r.tools))
+	for _, dt := range r.tools {
+		tool := *dt.tool
+		tool.Keywords = dt.keywords
+		tools = append(tools, tool)
+	}
+	// The snapshot includes every pending change; the old index is dropped.
+	r.pending = nil
+	r.mu.Unlock()
+
+	for _, tool := range tools {
+		index.Index(tool)
+	}
+
+	r.mu.Lock()
+	r.index = index
+	r.mu.Unlock()
+}
+
+// toolIndex returns the current index, first waiting for it to be passed
+// every change made so far.
+func (r *internalRegistry) toolIndex() ToolIndex {
+	r.indexMu.Lock()
+	r.drainIndexUpdatesLocked()
+	r.indexMu.Unlock()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.index
+}
+
+// lookupTool returns the registered tool with the given name, or nil. Unlike
+// GetTool it does not consult context providers.
+func (r *internalRegistry) lookupTool(name string) *MCPTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if dt, exists := r.tools[name]; exists {
+		return dt.tool
+	}
+	return nil
 }
 
 // GetRegisteredTools returns all tools in the registry
@@ -106,98 +259,48 @@ func (r *internalRegistry) Search(ctx context.Context, query string, maxResults
 // SearchWithAdditionalTools finds tools matching the query, including additional tools passed in.
 // The additional tools are typically discoverable tools from providers.
 // listedTools are additional searchable tools that may already appear in tools/list.
+// Errors from a custom index are treated as no results.
 func (r *internalRegistry) SearchWithAdditionalTools(ctx context.Context, query string, maxResults int, additionalTools []MCPTool, listedTools []MCPTool) []SearchResult {
-	r.mu.RLock()
-	toolsCopy := make(map[string]*internalRegisteredTool, len(r.tools))
-	for k, v := range r.tools {
-		toolsCopy[k] = v
-	}
-	r.mu.RUnlock()
+	tools := make([]MCPTool, 0, len(additionalTools)+len(listedTools))
+	tools = append(tools, additionalTools...)
+	tools = append(tools, listedTools...)
+	results, _ := r.toolIndex().Search(ctx, ToolQuery{Text: query, MaxResults: maxResults, Tools: tools})
+	return results
+}
 
-	var results []SearchResult
-	queryLower := strings.ToLower(strings.TrimSpace(query))
-	listAll := queryLower == ""
-	seen := make(map[string]bool)
-
-	// Search registered tools (statically registered discoverable tools)
-	for _, dt := range toolsCopy {
-		var score float64
-		if listAll {
-			score = 1.0
-		} else {
-			score = calculateScore(queryLower, dt.tool.Name, dt.tool.Description, dt.keywords)
-		}
-		if score > 0 {
-			results = append(results, SearchResult{
-				Name:        dt.tool.Name,
-				Description: dt.tool.Description,
-				Score:       score,
-				InputSchema: dt.tool.InputSchema,
-				Keywords:    dt.keywords,
-			})
-			seen[dt.tool.Name] = true
-		}
-	}
+// searchCandidate is a tool considered by tool_search, with the keywords it is
+// searched by.
+type searchCandidate struct {
+	tool     *MCPTool
+	keywords []string
+}
 
-	// Search additional tools (discoverable tools from providers)
-	for _, tool := range additionalTools {
-		if seen[tool.Name] {
-			continue
-		}
-		var score float64
-		if listAll {
-			score = 1.0
-		} else {
-			score = calculateScore(queryLower, tool.Name, tool.Description, tool.Keywords)
-		}
-		if score > 0 {
-			results = append(results, SearchResult{
-				Name:        tool.Name,
-				Description: tool.Description,
-				Score:       score,
-				InputSchema: tool.InputSchema,
-				Keywords:    tool.Keywords,
-			})
-			seen[tool.Name] = true
-		}
+// candidates returns every tool tool_search considers: the registered tools
+// (by name), then the additional and listed tools not already included. It
+// applies the same precedence as SearchWithAdditionalTools.
+func (r *internalRegistry) candidates(additionalTools []MCPTool, listedTools []MCPTool) []searchCandidate {
+	r.mu.RLock()
+	out := make([]searchCandidate, 0, len(r.tools)+len(additionalTools)+len(listedTools))
+	for _, dt := range r.tools {
+		out = append(out, searchCandidate{tool: dt.tool, keywords: dt.keywords})
 	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].tool.Name < out[j].tool.Name })
 
-	// Search listed tools
-	for _, tool := range listedTools {
-		if seen[tool.Name] {
-			continue
-		}
-		var score float64
-		if listAll {
-			score = 1.0
-		} else {
-			score = calculateScore(queryLower, tool.Name, tool.Description, tool.Keywords)
-		}
-		if score > 0 {
-			results = append(results, SearchResult{
-				Name:        tool.Name,
-				Description: tool.Description,
-				Score:       score,
-				InputSchema: tool.InputSchema,
-				Keywords:    tool.Keywords,
-			})
-			seen[tool.Name] = true
-		}
+	seen := make(map[string]bool, len(out))
+	for _, c := range out {
+		seen[c.tool.Name] = true
 	}
-
-	// Sort by score descending, then by name
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].Score != results[j].Score {
-			return results[i].Score > results[j].Score
+	for _, tools := range [][]MCPTool{additionalTools, listedTools} {
+		for i := range tools {
+			if seen[tools[i].Name] {
+				continue
+			}
+			seen[tools[i].Name] = true
+			out = append(out, searchCandidate{tool: &tools[i], keywords: tools[i].Keywords})
 		}
-		return results[i].Name < results[j].Name
-	})
-
-	if maxResults > 0 && len(results) > maxResults {
-		results = results[:maxResults]
 	}
-
-	return results
+	return out
 }
 
 // GetTool retrieves a tool by name
@@ -231,8 +334,9 @@ func (r *internalRegistry) CallTool(ctx context.Context, name string, args map[s
 	r.mu.RLock()
 	if dt, exists := r.tools[name]; exists {
 		handler := dt.handler
+		schema, _ := dt.tool.InputSchema.(map[string]any)
 		r.mu.RUnlock()
-		return handler(ctx, NewToolRequest(args))
+		return handler(ctx, &ToolRequest{args: args, defaults: schemaDefaults(schema)})
 	}
 	r.mu.RUnlock()
 
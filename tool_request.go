@@ -12,8 +12,12 @@ type ToolHandler func(ctx context.Context, req *ToolRequest) (*ToolResponse, err
 // ToolRequest provides typed access to tool arguments.
 // Use the accessor methods (String, Int, Bool, etc.) to retrieve parameters
 // with automatic type conversion and validation.
+//
+// When an argument is missing, the accessors fall back to the default declared
+// for it with the Default option, if any.
 type ToolRequest struct {
-	args map[string]any
+	args     map[string]any
+	defaults map[string]any
 }
 
 // NewToolRequest creates a new ToolRequest with the given arguments.
@@ -22,10 +26,20 @@ func NewToolRequest(args map[string]any) *ToolRequest {
 	return &ToolRequest{args: args}
 }
 
+// lookup returns the argument with the given name, falling back to its
+// declared default when the caller omitted it.
+func (r *ToolRequest) lookup(name string) (any, bool) {
+	if val, ok := r.args[name]; ok {
+		return val, true
+	}
+	val, ok := r.defaults[name]
+	return val, ok
+}
+
 // String returns a string parameter by name.
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) String(name string) (string, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return "", ErrUnknownParameter
 	}
@@ -47,7 +61,7 @@ func (r *ToolRequest) StringOr(name, defaultValue string) string {
 // Handles both int and float64 types (JSON numbers are parsed as float64).
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) Int(name string) (int, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return 0, ErrUnknownParameter
 	}
@@ -72,7 +86,7 @@ func (r *ToolRequest) IntOr(name string, defaultValue int) int {
 // Float returns a float64 parameter by name.
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) Float(name string) (float64, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return 0, ErrUnknownParameter
 	}
@@ -93,7 +107,7 @@ func (r *ToolRequest) FloatOr(name string, defaultValue float64) float64 {
 // Bool returns a boolean parameter by name.
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) Bool(name string) (bool, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return false, ErrUnknownParameter
 	}
@@ -114,7 +128,7 @@ func (r *ToolRequest) BoolOr(name string, defaultValue bool) bool {
 // StringSlice returns a string array parameter by name.
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) StringSlice(name string) ([]string, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return nil, ErrUnknownParameter
 	}
@@ -144,7 +158,7 @@ func (r *ToolRequest) StringSliceOr(name string, defaultValue []string) []string
 // Handles both int and float64 array elements (JSON numbers are parsed as float64).
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) IntSlice(name string) ([]int, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return nil, ErrUnknownParameter
 	}
@@ -176,7 +190,7 @@ func (r *ToolRequest) IntSliceOr(name string, defaultValue []int) []int {
 // FloatSlice returns a float64 array parameter by name.
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) FloatSlice(name string) ([]float64, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return nil, ErrUnknownParameter
 	}
@@ -205,7 +219,7 @@ func (r *ToolRequest) FloatSliceOr(name string, defaultValue []float64) []float6
 // BoolSlice returns a boolean array parameter by name.
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) BoolSlice(name string) ([]bool, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return nil, ErrUnknownParameter
 	}
@@ -234,7 +248,7 @@ func (r *ToolRequest) BoolSliceOr(name string, defaultValue []bool) []bool {
 // Object returns a parameter as a map[string]any (generic object).
 // Returns ErrUnknownParameter if the parameter doesn't exist.
 func (r *ToolRequest) Object(name string) (map[string]any, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return nil, ErrUnknownParameter
 	}
@@ -254,7 +268,7 @@ func (r *ToolRequest) ObjectOr(name string, defaultValue map[string]any) map[str
 
 // ObjectSlice returns a parameter as a slice of objects
 func (r *ToolRequest) ObjectSlice(name string) ([]map[string]any, error) {
-	val, ok := r.args[name]
+	val, ok := r.lookup(name)
 	if !ok {
 		return nil, ErrUnknownParameter
 	}
@@ -392,7 +406,8 @@ func (r *ToolRequest) GetObjectBoolProperty(objectName, propertyName string) (bo
 	return r.ObjectBool(objectName, propertyName)
 }
 
-// Args returns all arguments as a map
+// Args returns all arguments as a map, exactly as sent by the caller
+// (declared defaults are not merged in).
 func (r *ToolRequest) Args() map[string]any {
 	return r.args
 }
@@ -12,11 +12,17 @@ import (
 type ToolResponse struct {
 	Content           []ToolContent `json:"content"`
 	StructuredContent any           `json:"structuredContent,omitempty"`
+
+	// IsError marks the result as a tool-level failure (sent as isError). Unlike
+	// returning a ToolError, the content is still delivered to the model so it
+	// can see what went wrong and react.
+	IsError bool `json:"isError,omitempty"`
 }
 
 func NewToolResponseMulti(responses ...*ToolResponse) *ToolResponse {
 	var allContent []ToolContent
 	var structuredContent any
+	isError := false
 
 	for _, resp := range responses {
 		if resp.Content != nil {
@@ -25,11 +31,13 @@ func NewToolResponseMulti(responses ...*ToolResponse) *ToolResponse {
 		if resp.StructuredContent != nil {
 			structuredContent = resp.StructuredContent
 		}
+		isError = isError || resp.IsError
 	}
 
 	return &ToolResponse{
 		Content:           allContent,
 		StructuredContent: structuredContent,
+		IsError:           isError,
 	}
 }
 
@@ -37,6 +45,13 @@ func NewToolResponseText(text string) *ToolResponse {
 	return &ToolResponse{Content: []ToolContent{{Type: "text", Text: text}}}
 }
 
+// NewToolResponseError builds a text result flagged with isError. Use it for
+// failures the model should see and can act on (a command exiting non-zero, a
+// lookup finding nothing); return a ToolError for protocol-level failures.
+func NewToolResponseError(text string) *ToolResponse {
+	return &ToolResponse{Content: []ToolContent{{Type: "text", Text: text}}, IsError: true}
+}
+
 func NewToolResponseJSON(data any) *ToolResponse {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/snowballstem"
+	"github.com/blevesearch/snowballstem/english"
+)
+
+// ToolSearchMode selects how tool_search ranks tools.
+type ToolSearchMode int
+
+const (
+	// ToolSearchHeuristic ranks tools with substring, token and fuzzy matching
+	// over the name, description and keywords. This is the default and works
+	// well for small tool sets and partially typed words.
+	ToolSearchHeuristic ToolSearchMode = iota
+
+	// ToolSearchBM25 ranks tools with BM25 over an inverted index of stemmed
+	// words from the name, keywords and description, with stop words removed.
+	// Words are weighted by how rare they are across all tools, which keeps
+	// results focused when there are hundreds of tools.
+	ToolSearchBM25
+)
+
+// String returns a human-readable name for the search mode.
+func (m ToolSearchMode) String() string {
+	switch m {
+	case ToolSearchHeuristic:
+		return "heuristic"
+	case ToolSearchBM25:
+		return "bm25"
+	default:
+		return "unknown"
+	}
+}
+
+// SetToolSearchMode selects how tool_search ranks tools. Scores stay in the
+// 0–1 range in every mode, with 1 reserved for an exact name match. The
+// default is ToolSearchHeuristic. The mode applies to the built-in index; a
+// custom index set with SetToolIndex does its own ranking.
+func (s *Server) SetToolSearchMode(mode ToolSearchMode) {
+	s.internalRegistry.setSearchMode(mode)
+}
+
+// BM25 parameters: term frequency saturation and document length
+// normalisation, at their usual values.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field weights: a word in the tool name says more about the tool than the
+// same word in its description.
+const (
+	bm25NameWeight        = 3
+	bm25KeywordWeight     = 2
+	bm25DescriptionWeight = 1
+)
+
+// bm25Doc is one tool in a bm25Index: the weighted frequency of each term and
+// the weighted document length.
+type bm25Doc struct {
+	terms  map[string]float64
+	length float64
+}
+
+// bm25Index is an inverted index of tool text for BM25 ranking. It is not
+// safe for concurrent use; internalRegistry guards it with its own lock.
+type bm25Index struct {
+	docs        map[string]*bm25Doc
+	postings    map[string]map[string]float64 // term -> tool name -> weighted tf
+	totalLength float64
+}
+
+func newBM25Index() *bm25Index {
+	return &bm25Index{
+		docs:     make(map[string]*bm25Doc),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// add indexes a tool, replacing any previous entry with the same name.
+func (idx *bm25Index) add(name, description string, keywords []string) {
+	idx.remove(name)
+
+	doc := &bm25Doc{terms: make(map[string]float64)}
+	addTerms := func(text string, weight float64) {
+		for _, term := range analyzeSearchText(text) {
+			doc.terms[term] += weight
+			doc.length += weight
+		}
+	}
+	addTerms(name, bm25NameWeight)
+	for _, kw := range keywords {
+		addTerms(kw, bm25KeywordWeight)
+	}
+	addTerms(description, bm25DescriptionWeight)
+
+	idx.docs[name] = doc
+	idx.totalLength += doc.length
+	for term, tf := range doc.terms {
+		posting, ok := idx.postings[term]
+		if !ok {
+			posting = make(map[string]float64)
+			idx.postings[term] = posting
+		}
+		posting[name] = tf
+	}
+}
+
+// remove drops a tool from the index. Removing an unknown name is a no-op.
+func (idx *bm25Index) remove(name string) {
+	doc, ok := idx.docs[name]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		posting := idx.postings[term]
+		delete(posting, name)
+		if len(posting) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= doc.length
+	delete(idx.docs, name)
+}
+
+// bm25Score scores every tool in the given indexes against the query as if
+// they formed one corpus, so tools supplied per request (from providers) are
+// ranked on the same scale as registered ones. Names are looked up in the
+// indexes in order and the first match wins. Scores are divided by the
+// query's maximum attainable score, which keeps them in the 0–1 range.
+func bm25Score(query string, indexes ...*bm25Index) map[string]float64 {
+	terms := uniqueStrings(analyzeSearchText(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var docCount, totalLength float64
+	for _, idx := range indexes {
+		docCount += float64(len(idx.docs))
+		totalLength += idx.totalLength
+	}
+	if docCount == 0 {
+		return nil
+	}
+	avgLength := totalLength / docCount
+
+	scores := make(map[string]float64)
+	var maxScore float64
+	for _, term := range terms {
+		var df float64
+		for _, idx := range indexes {
+			df += float64(len(idx.postings[term]))
+		}
+		idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+		maxScore += idf * (bm25K1 + 1)
+		if df == 0 {
+			continue
+		}
+
+		for i, idx := range indexes {
+			for name, tf := range idx.postings[term] {
+				if shadowedInIndexes(name, indexes[:i]) {
+					continue
+				}
+				norm := 1 - bm25B + bm25B*idx.docs[name].length/avgLength
+				scores[name] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+			}
+		}
+	}
+
+	for name, score := range scores {
+		scores[name] = score / maxScore
+	}
+	return scores
+}
+
+func shadowedInIndexes(name string, indexes []*bm25Index) bool {
+	for _, idx := range indexes {
+		if _, ok := idx.docs[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeSearchText splits text into lower-case words (breaking identifiers
+// such as "send_email" and "sendEmail" apart), drops stop words and stems
+// what remains.
+func analyzeSearchText(text string) []string {
+	var terms []string
+	env := snowballstem.NewEnv("")
+	for _, word := range splitSearchWords(text) {
+		if searchStopWords[word] {
+			continue
+		}
+		env.SetCurrent(word)
+		english.Stem(env)
+		terms = append(terms, env.Current())
+	}
+	return terms
+}
+
+// splitSearchWords breaks text on anything that is not a letter or digit and
+// at lower-to-upper case changes, returning lower-case words.
+func splitSearchWords(text string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	var prev rune
+	for _, r := range text {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+		prev = r
+	}
+	flush()
+	return words
+}
+
+// uniqueStrings returns values without duplicates, in first-seen order. It
+// returns a new slice and leaves values untouched, as callers pass slices
+// shared with registered tools.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// searchStopWords are common English words that carry no meaning for tool
+// search and are left out of the index and queries.
+var searchStopWords = map[string]bool{
+	"a": true, "about": true, "above": true, "after": true, "again": true, "all": true,
+	"am": true, "an": true, "and": true, "any": true, "are": true, "as": true, "at": true,
+	"be": true, "because": true, "been": true, "before": true, "being": true, "below": true,
+	"between": true, "both": true, "but": true, "by": true, "can": true, "could": true,
+	"did": true, "do": true, "does": true, "doing": true, "during": true, "each": true,
+	"few": true, "for": true, "from": true, "further": true, "had": true, "has": true,
+	"have": true, "having": true, "he": true, "her": true, "here": true, "hers": true,
+	"him": true, "his": true, "how": true, "i": true, "if": true, "in": true, "into": true,
+	"is": true, "it": true, "its": true, "itself": true, "just": true, "me": true,
+	"more": true, "most": true, "my": true, "no": true, "nor": true, "not": true, "of": true,
+	"off": true, "on": true, "once": true, "only": true, "or": true, "other": true,
+	"our": true, "ours": true, "out": true, "over": true, "own": true, "same": true,
+	"she": true, "should": true, "so": true, "some": true, "such": true, "than": true,
+	"that": true, "the": true, "their": true, "theirs": true, "them": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "those": true, "through": true,
+	"to": true, "too": true, "under": true, "until": true, "up": true, "very": true,
+	"was": true, "we": true, "were": true, "what": true, "when": true, "where": true,
+	"which": true, "while": true, "who": true, "whom": true, "why": true, "will": true,
+	"with": true, "would": true, "you": true, "your": true, "yours": true,
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeSearchText(t *testing.T) {
+	tests := map[string][]string{
+		"Sending emails to the users": {"send", "email", "user"},
+		"sendEmail":                   {"send", "email"},
+		"list_repos-v2":               {"list", "repo", "v2"},
+		"the of and":                  nil,
+	}
+	for text, want := range tests {
+		if got := analyzeSearchText(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("analyzeSearchText(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestBM25IndexIncremental(t *testing.T) {
+	r := newInternalRegistry()
+	r.setSearchMode(ToolSearchBM25)
+	r.RegisterTool(NewTool("send_email", "Send an email message"), nil, "mail")
+	r.RegisterMCPTool(&MCPTool{Name: "create_invoice", Description: "Create an invoice for a customer"}, nil, "billing")
+
+	if got := r.Search(context.Background(), "emailing", 5); len(got) != 1 || got[0].Name != "send_email" {
+		t.Fatalf("stemmed query should match send_email, got %+v", got)
+	}
+
+	index := r.index.(*lexicalToolIndex).bm25
+	r.UnregisterTool("send_email")
+	if got := r.Search(context.Background(), "email", 5); len(got) != 0 {
+		t.Errorf("unregistered tool should be removed from the index, got %+v", got)
+	}
+	if _, ok := index.postings["email"]; ok {
+		t.Error("postings for removed tools should be dropped")
+	}
+
+	r.UnregisterTool("create_invoice")
+	r.applyIndexUpdates()
+	if index.totalLength != 0 || len(index.postings) != 0 {
+		t.Errorf("empty index should have no length or postings: %v %v", index.totalLength, index.postings)
+	}
+}
+
+func TestBM25Ranking(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.SetToolSearchMode(ToolSearchBM25)
+	for i := 0; i < 50; i++ {
+		server.RegisterTool(NewTool(fmt.Sprintf("report_%d", i), "Generate a report about the data").Discoverable("report"), nil)
+	}
+	server.RegisterTool(NewTool("send_email", "Send an email to a user").Discoverable("mail", "notify"), nil)
+	server.RegisterTool(NewTool("email_report", "Email a report to a list of recipients").Discoverable("report"), nil)
+
+	provider := &mockToolProvider{tools: []MCPTool{{
+		Name:        "notify_slack",
+		Description: "Post a notification message to Slack",
+		Keywords:    []string{"notify", "message"},
+		Visibility:  ToolVisibilityDiscoverable,
+	}}}
+	ctx := WithToolProviders(context.Background(), provider)
+
+	search := func(query string) []SearchResult {
+		t.Helper()
+		resp, err := server.CallTool(ctx, ToolSearchName, map[string]any{"query": query, "max_results": float64(3)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var results []SearchResult
+		if err := json.Unmarshal([]byte(resp.Content[0].Text), &results); err != nil {
+			t.Fatalf("unexpected response %q: %v", resp.Content[0].Text, err)
+		}
+		return results
+	}
+
+	results := search("email report")
+	if results[0].Name != "email_report" {
+		t.Errorf("the tool matching both rare and common words should rank first: %+v", results)
+	}
+	for _, r := range results {
+		if r.Score <= 0 || r.Score >= 1 {
+			t.Errorf("scores should be normalised to (0, 1) for non-exact matches: %+v", r)
+		}
+	}
+
+	if results := search("send_email"); results[0].Name != "send_email" || results[0].Score != 1 {
+		t.Errorf("exact name match should score 1: %+v", results)
+	}
+	if results := search("notify"); len(results) != 2 || results[0].Name != "notify_slack" {
+		t.Errorf("provider tools should be ranked with registered tools: %+v", results)
+	}
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import "strings"
+
+// ToolSearchFilter narrows tool_search to tools with the given metadata.
+// Empty fields match every tool, and string fields ignore case.
+type ToolSearchFilter struct {
+	Category  string // Set with ToolBuilder.Category
+	Tag       string // One of the tags set with ToolBuilder.Tags
+	Namespace string // The namespace of a remote server or provider
+	ReadOnly  bool   // Only tools annotated as read-only
+}
+
+// isZero reports whether the filter matches every tool.
+func (f ToolSearchFilter) isZero() bool {
+	return f == ToolSearchFilter{}
+}
+
+// matches reports whether a tool with the given metadata passes the filter.
+func (f ToolSearchFilter) matches(category string, tags []string, namespace string, readOnly bool) bool {
+	if f.Category != "" && !strings.EqualFold(f.Category, category) {
+		return false
+	}
+	if f.Namespace != "" && !strings.EqualFold(f.Namespace, namespace) {
+		return false
+	}
+	if f.ReadOnly && !readOnly {
+		return false
+	}
+	if f.Tag != "" {
+		for _, tag := range tags {
+			if strings.EqualFold(f.Tag, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// args returns the filter as tool_search arguments.
+func (f ToolSearchFilter) args() map[string]any {
+	args := make(map[string]any)
+	if f.Category != "" {
+		args["category"] = f.Category
+	}
+	if f.Tag != "" {
+		args["tag"] = f.Tag
+	}
+	if f.Namespace != "" {
+		args["namespace"] = f.Namespace
+	}
+	if f.ReadOnly {
+		args["read_only"] = true
+	}
+	return args
+}
+
+// ToolSearchFacets counts the tools matching a search by each facet value.
+// Tools without a category or namespace are not counted under those facets.
+type ToolSearchFacets struct {
+	Categories map[string]int `json:"categories"`
+	Tags       map[string]int `json:"tags"`
+	Namespaces map[string]int `json:"namespaces"`
+	ReadOnly   int            `json:"readOnly"` // Tools annotated as read-only
+}
+
+// FacetedSearchResults is the tool_search response when facets are requested.
+type FacetedSearchResults struct {
+	Results []SearchResult   `json:"results"`
+	Total   int              `json:"total"` // Matching tools before max_results was applied
+	Facets  ToolSearchFacets `json:"facets"`
+}
+
+// countFacets counts the facet values of results.
+func countFacets(results []SearchResult, namespaceOf func(name string) string) ToolSearchFacets {
+	facets := ToolSearchFacets{
+		Categories: make(map[string]int),
+		Tags:       make(map[string]int),
+		Namespaces: make(map[string]int),
+	}
+	for _, r := range results {
+		if r.Category != "" {
+			facets.Categories[r.Category]++
+		}
+		for _, tag := range uniqueStrings(r.Tags) {
+			facets.Tags[tag]++
+		}
+		if ns := namespaceOf(r.Name); ns != "" {
+			facets.Namespaces[ns]++
+		}
+		if r.ReadOnly {
+			facets.ReadOnly++
+		}
+	}
+	return facets
+}
+
+// toolNamespacer returns a function giving the namespace of a tool name: the
+// namespace of the remote server the name belongs to, or else the part before
+// DefaultNamespaceSeparator, as used by providers.
+func (s *Server) toolNamespacer() func(name string) string {
+	type prefix struct{ namespace, prefix string }
+	s.mu.RLock()
+	var prefixes []prefix
+	for _, rc := range s.remoteClients {
+		if rc.namespace != "" {
+			prefixes = append(prefixes, prefix{rc.namespace, rc.namespace + rc.client.separator})
+		}
+	}
+	s.mu.RUnlock()
+
+	return func(name string) string {
+		for _, p := range prefixes {
+			if strings.HasPrefix(name, p.prefix) {
+				return p.namespace
+			}
+		}
+		if namespace, _, ok := strings.Cut(name, DefaultNamespaceSeparator); ok {
+			return namespace
+		}
+		return ""
+	}
+}
@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func facetTestServer() *Server {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("list_repos", "List repositories").Discoverable("repo").Category("github").Tags("repos").ReadOnly(), nil)
+	server.RegisterTool(NewTool("create_issue", "Create an issue").Discoverable("issue").Category("github").Tags("issues"), nil)
+	server.RegisterTool(NewTool("list_invoices", "List invoices").Discoverable("billing").Category("billing").ReadOnly(), nil)
+	server.RegisterTool(NewTool("ping", "Check the server is up").ReadOnly(), nil)
+	return server
+}
+
+func searchToolNames(t *testing.T, ctx context.Context, server *Server, args map[string]any) []string {
+	t.Helper()
+	resp, err := server.CallTool(ctx, ToolSearchName, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasPrefix(resp.Content[0].Text, "No tools found") {
+		return nil
+	}
+	var results []SearchResult
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &results); err != nil {
+		t.Fatalf("unexpected response %q", resp.Content[0].Text)
+	}
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestToolSearchFilters(t *testing.T) {
+	server := facetTestServer()
+	ctx := WithToolProviders(context.Background(), &mockToolProvider{tools: []MCPTool{{
+		Name:        "acme__get_order",
+		Description: "Get an order",
+		Visibility:  ToolVisibilityDiscoverable,
+		Annotations: &ToolAnnotations{ReadOnlyHint: true},
+	}}})
+
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"category": "GitHub"}, "create_issue,list_repos"},
+		{map[string]any{"tag": "issues"}, "create_issue"},
+		{map[string]any{"read_only": true, "query": "list"}, "list_invoices,list_repos"},
+		{map[string]any{"category": "github", "read_only": true}, "list_repos"},
+		{map[string]any{"namespace": "acme"}, "acme__get_order"},
+		{map[string]any{"category": "unknown"}, ""},
+	}
+	for _, tt := range tests {
+		tt.args["max_results"] = 20
+		if got := strings.Join(searchToolNames(t, ctx, server, tt.args), ","); got != tt.want {
+			t.Errorf("tool_search %v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestToolSearchFacets(t *testing.T) {
+	server := facetTestServer()
+	resp, err := server.CallTool(context.Background(), ToolSearchName, map[string]any{"facets": true, "max_results": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var faceted FacetedSearchResults
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &faceted); err != nil {
+		t.Fatalf("unexpected response %q", resp.Content[0].Text)
+	}
+
+	if len(faceted.Results) != 1 || faceted.Total != 4 {
+		t.Errorf("expected 1 of 4 results, got %d of %d", len(faceted.Results), faceted.Total)
+	}
+	if faceted.Facets.Categories["github"] != 2 || faceted.Facets.Categories["billing"] != 1 {
+		t.Errorf("unexpected category counts: %v", faceted.Facets.Categories)
+	}
+	if faceted.Facets.Tags["repos"] != 1 || faceted.Facets.Tags["issues"] != 1 {
+		t.Errorf("unexpected tag counts: %v", faceted.Facets.Tags)
+	}
+	if faceted.Facets.ReadOnly != 3 {
+		t.Errorf("expected 3 read-only tools, got %d", faceted.Facets.ReadOnly)
+	}
+
+	// Facets are counted after filtering.
+	resp, _ = server.CallTool(context.Background(), ToolSearchName, map[string]any{"facets": true, "category": "unknown"})
+	if !strings.Contains(resp.Content[0].Text, `"results":[]`) || !strings.Contains(resp.Content[0].Text, `"total":0`) {
+		t.Errorf("empty faceted search should still return an object: %s", resp.Content[0].Text)
+	}
+}
+
+func TestToolAnnotationsInToolsList(t *testing.T) {
+	server := facetTestServer()
+	ts := httptest.NewServer(http.HandlerFunc(server.HandleRequest))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, nil, "up")
+	tools, err := client.ListTools(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools {
+		if tool.Name == "up__ping" {
+			if tool.Annotations == nil || !tool.Annotations.ReadOnlyHint {
+				t.Errorf("annotations should reach the client: %+v", tool.Annotations)
+			}
+			return
+		}
+	}
+	t.Errorf("ping not listed: %+v", tools)
+}
+
+func TestRemoteToolSearchFilters(t *testing.T) {
+	remote := facetTestServer()
+	remoteTS := httptest.NewServer(http.HandlerFunc(remote.HandleRequest))
+	defer remoteTS.Close()
+
+	server := NewServer("main", "1.0")
+	server.RegisterTool(NewTool("local_repo", "A local repo tool").Discoverable("repo").Category("github"), nil)
+	if err := server.ReplaceRemoteServers([]RemoteServerEntry{
+		{Client: NewClient(remoteTS.URL, nil, "gh"), Visibility: ToolVisibilityDiscoverable, RemoteSearch: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := searchToolNames(t, context.Background(), server, map[string]any{"category": "github", "read_only": true, "max_results": 20})
+	if strings.Join(got, ",") != "gh__list_repos" {
+		t.Errorf("remote search should honour filters, got %q", got)
+	}
+
+	got = searchToolNames(t, context.Background(), server, map[string]any{"namespace": "gh", "tag": "issues", "max_results": 20})
+	if strings.Join(got, ",") != "gh__create_issue" {
+		t.Errorf("namespace filter should select the remote, got %q", got)
+	}
+}
+
+func TestToolSearchFacetsLeaveToolTagsUnchanged(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("tagged", "A tagged tool").Discoverable("tagged").Tags("a", "a", "b"), nil)
+	server.RegisterTool(&ToolBuilder{name: "raw", description: "A raw tool", discoverable: true, tags: []string{"a", "a", "b"}}, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := server.CallTool(context.Background(), ToolSearchName, map[string]any{"facets": true, "max_results": 20}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := strings.Join(server.internalRegistry.tools["tagged"].tool.Tags, ","); got != "a,b" {
+		t.Errorf("Tags should be deduplicated once, got %q", got)
+	}
+	if got := strings.Join(server.internalRegistry.tools["raw"].tool.Tags, ","); got != "a,a,b" {
+		t.Errorf("faceted search changed the tool's tags to %q", got)
+	}
+}
+
+func TestSemanticToolSearchFacets(t *testing.T) {
+	server := facetTestServer()
+	server.SetSemanticToolSearch(&conceptEmbedder{})
+
+	resp, err := server.CallTool(context.Background(), ToolSearchName, map[string]any{"query": "ticket", "facets": true, "tag": "issues"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var faceted FacetedSearchResults
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &faceted); err != nil {
+		t.Fatalf("unexpected response %q", resp.Content[0].Text)
+	}
+	if len(faceted.Results) != 1 || faceted.Results[0].Name != "create_issue" {
+		t.Fatalf("expected create_issue, got %+v", faceted.Results)
+	}
+	if r := faceted.Results[0]; r.Category != "github" || strings.Join(r.Tags, ",") != "issues" {
+		t.Errorf("semantic results should carry category and tags: %+v", r)
+	}
+	if faceted.Facets.Categories["github"] != 1 || faceted.Facets.Tags["issues"] != 1 {
+		t.Errorf("unexpected facet counts: %+v", faceted.Facets)
+	}
+
+	resp, _ = server.CallTool(context.Background(), ToolSearchName, map[string]any{"query": "invoice", "facets": true, "read_only": true})
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &faceted); err != nil {
+		t.Fatalf("unexpected response %q", resp.Content[0].Text)
+	}
+	if faceted.Facets.ReadOnly != faceted.Total || faceted.Total == 0 {
+		t.Errorf("read-only facet should count every read-only match: %+v", faceted)
+	}
+}
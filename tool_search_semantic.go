@@ -0,0 +1,351 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Embedder turns text into embedding vectors for semantic tool search. The
+// ai package provides one backed by any ai.Client (ai.NewEmbedder), so OpenAI,
+// Ollama and the other providers can all be used.
+type Embedder interface {
+	// EmbeddingModel identifies the model producing the vectors. It is part of
+	// every cache key, so changing models never reuses stale vectors.
+	EmbeddingModel() string
+
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// VectorStore caches tool embeddings between searches and, for persistent
+// implementations, between restarts. Keys are derived from the embedding model
+// and the embedded text, so a tool whose description changes is re-embedded
+// under a new key.
+type VectorStore interface {
+	// Get returns the stored vectors for the keys that are present.
+	Get(ctx context.Context, keys []string) (map[string][]float64, error)
+
+	// Put stores vectors by key.
+	Put(ctx context.Context, vectors map[string][]float64) error
+}
+
+// DefaultSemanticLexicalWeight is the share of the lexical score blended into
+// semantic search results when WithLexicalWeight is not given.
+const DefaultSemanticLexicalWeight = 0.3
+
+// SemanticSearchOption configures semantic tool search.
+type SemanticSearchOption func(*semanticSearch)
+
+// WithVectorStore sets where tool embeddings are cached. The default is an
+// in-memory store, which re-embeds every tool after a restart; use
+// NewFileVectorStore or your own store to persist them.
+func WithVectorStore(store VectorStore) SemanticSearchOption {
+	return func(s *semanticSearch) { s.store = store }
+}
+
+// WithLexicalWeight sets how much of the lexical score (from the ranking
+// chosen with SetToolSearchMode) is blended into each result, from 0 (cosine
+// similarity only) to 1 (lexical only).
+func WithLexicalWeight(weight float64) SemanticSearchOption {
+	return func(s *semanticSearch) { s.lexicalWeight = math.Max(0, math.Min(1, weight)) }
+}
+
+// WithMinSimilarity drops results whose blended score is below min. The
+// default of 0 keeps every tool and relies on max_results to limit the list.
+func WithMinSimilarity(min float64) SemanticSearchOption {
+	return func(s *semanticSearch) { s.minScore = min }
+}
+
+// WithSemanticSearchErrorHandler sets the function that receives errors the
+// search recovers from: an embedder failure, after which the search falls
+// back to lexical ranking, and a failure to store new embeddings, which are
+// still used for the search at hand. Without it these errors are discarded.
+func WithSemanticSearchErrorHandler(fn func(error)) SemanticSearchOption {
+	return func(s *semanticSearch) { s.onError = fn }
+}
+
+// semanticSearch ranks tools by the cosine similarity between the query's
+// embedding and each tool's embedding.
+type semanticSearch struct {
+	embedder      Embedder
+	store         VectorStore
+	lexicalWeight float64
+	minScore      float64
+	onError       func(error) // Nil discards recovered errors
+}
+
+// SetSemanticToolSearch makes tool_search rank tools by meaning rather than
+// only by shared words, so a query for "ticket" can find a tool described in
+// terms of "issues". Each tool's name, description and keywords are embedded
+// once, on the first search that sees the tool, and cached in the vector store;
+// each search then embeds only the query. Results blend cosine similarity with
+// the lexical score (see WithLexicalWeight).
+//
+// If the embedder fails, the search falls back to lexical ranking and reports
+// the error to the WithSemanticSearchErrorHandler function, if any. Pass a nil
+// embedder to turn semantic search off again.
+//
+//	embedder := ai.NewEmbedder(ollamaClient, "nomic-embed-text")
+//	store, _ := mcp.NewFileVectorStore("tool-vectors.json")
+//	server.SetSemanticToolSearch(embedder, mcp.WithVectorStore(store))
+func (s *Server) SetSemanticToolSearch(embedder Embedder, opts ...SemanticSearchOption) {
+	var semantic *semanticSearch
+	if embedder != nil {
+		semantic = &semanticSearch{
+			embedder:      embedder,
+			store:         NewMemoryVectorStore(),
+			lexicalWeight: DefaultSemanticLexicalWeight,
+		}
+		for _, opt := range opts {
+			opt(semantic)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.semanticSearch = semantic
+}
+
+// reportError passes an error the search recovered from to the error handler.
+func (ss *semanticSearch) reportError(err error) {
+	if ss.onError != nil {
+		ss.onError(err)
+	}
+}
+
+// search ranks candidates against the query. lexical holds the lexical score
+// of each candidate that matched lexically.
+func (ss *semanticSearch) search(ctx context.Context, query string, candidates []searchCandidate, lexical map[string]float64, maxResults int) ([]SearchResult, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := ss.toolVectors(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	queryVectors, err := ss.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(queryVectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for 1 text", len(queryVectors))
+	}
+	queryVector := queryVectors[0]
+
+	queryLower := strings.ToLower(query)
+	results := make([]SearchResult, 0, len(candidates))
+	for i, c := range candidates {
+		similarity := math.Max(0, cosineSimilarity(queryVector, vectors[i]))
+		score := (1-ss.lexicalWeight)*similarity + ss.lexicalWeight*lexical[c.tool.Name]
+		if strings.ToLower(c.tool.Name) == queryLower {
+			score = 1.0
+		}
+		if score <= ss.minScore || score <= 0 {
+			continue
+		}
+		result := NewSearchResult(c.tool, score)
+		result.Keywords = c.keywords
+		results = append(results, result)
+	}
+
+	sortSearchResults(results)
+	if maxResults > 0 && len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	return results, nil
+}
+
+// toolVectors returns the embedding of each candidate, embedding and storing
+// those not yet in the store in a single batch.
+func (ss *semanticSearch) toolVectors(ctx context.Context, candidates []searchCandidate) ([][]float64, error) {
+	model := ss.embedder.EmbeddingModel()
+	keys := make([]string, len(candidates))
+	texts := make([]string, len(candidates))
+	for i, c := range candidates {
+		texts[i] = toolEmbeddingText(c.tool.Name, c.tool.Description, c.keywords)
+		sum := sha256.Sum256([]byte(model + "\x00" + texts[i]))
+		keys[i] = hex.EncodeToString(sum[:])
+	}
+
+	stored, err := ss.store.Get(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var missingKeys, missingTexts []string
+	pending := make(map[string]bool)
+	for i, key := range keys {
+		if _, ok := stored[key]; !ok && !pending[key] {
+			pending[key] = true
+			missingKeys = append(missingKeys, key)
+			missingTexts = append(missingTexts, texts[i])
+		}
+	}
+	if len(missingTexts) > 0 {
+		embedded, err := ss.embedder.Embed(ctx, missingTexts)
+		if err != nil {
+			return nil, err
+		}
+		if len(embedded) != len(missingTexts) {
+			return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(embedded), len(missingTexts))
+		}
+		fresh := make(map[string][]float64, len(missingKeys))
+		for i, key := range missingKeys {
+			fresh[key] = embedded[i]
+		}
+		if err := ss.store.Put(ctx, fresh); err != nil {
+			// The vectors are still usable for this search.
+			ss.reportError(fmt.Errorf("store tool embeddings: %w", err))
+		}
+		if stored == nil {
+			stored = make(map[string][]float64, len(fresh))
+		}
+		for key, vector := range fresh {
+			stored[key] = vector
+		}
+	}
+
+	vectors := make([][]float64, len(keys))
+	for i, key := range keys {
+		vectors[i] = stored[key]
+	}
+	return vectors, nil
+}
+
+// toolEmbeddingText is the text embedded for a tool. Identifier separators are
+// turned into spaces so the model sees words.
+func toolEmbeddingText(name, description string, keywords []string) string {
+	text := strings.Join(splitSearchWords(name), " ")
+	if description != "" {
+		text += ": " + description
+	}
+	if len(keywords) > 0 {
+		text += " (" + strings.Join(keywords, ", ") + ")"
+	}
+	return text
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// MemoryVectorStore is an in-memory VectorStore. It is the default store for
+// semantic tool search.
+type MemoryVectorStore struct {
+	mu      sync.RWMutex
+	vectors map[string][]float64
+}
+
+// NewMemoryVectorStore creates an empty in-memory vector store.
+func NewMemoryVectorStore() *MemoryVectorStore {
+	return &MemoryVectorStore{vectors: make(map[string][]float64)}
+}
+
+// Get returns the stored vectors for the keys that are present.
+func (m *MemoryVectorStore) Get(ctx context.Context, keys []string) (map[string][]float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	found := make(map[string][]float64, len(keys))
+	for _, key := range keys {
+		if v, ok := m.vectors[key]; ok {
+			found[key] = v
+		}
+	}
+	return found, nil
+}
+
+// Put stores vectors by key.
+func (m *MemoryVectorStore) Put(ctx context.Context, vectors map[string][]float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, v := range vectors {
+		m.vectors[key] = v
+	}
+	return nil
+}
+
+// FileVectorStore is a VectorStore that keeps vectors in memory and saves them
+// to a JSON file on every Put, so embeddings survive restarts. Entries are
+// never removed; delete the file to start afresh.
+type FileVectorStore struct {
+	path string
+	mem  *MemoryVectorStore
+	mu   sync.Mutex // Serialises writes to the file
+}
+
+// NewFileVectorStore opens the store at path, loading any vectors saved by a
+// previous run. A missing file is not an error.
+func NewFileVectorStore(path string) (*FileVectorStore, error) {
+	store := &FileVectorStore{path: path, mem: NewMemoryVectorStore()}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.mem.vectors); err != nil {
+		return nil, fmt.Errorf("read vector store %s: %w", path, err)
+	}
+	if store.mem.vectors == nil {
+		store.mem.vectors = make(map[string][]float64)
+	}
+	return store, nil
+}
+
+// Get returns the stored vectors for the keys that are present.
+func (f *FileVectorStore) Get(ctx context.Context, keys []string) (map[string][]float64, error) {
+	return f.mem.Get(ctx, keys)
+}
+
+// Put stores vectors by key and rewrites the file. The file is replaced
+// atomically, so a crash never leaves it half written.
+func (f *FileVectorStore) Put(ctx context.Context, vectors map[string][]float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.mem.Put(ctx, vectors)
+
+	f.mem.mu.RLock()
+	data, err := json.Marshal(f.mem.vectors)
+	f.mem.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
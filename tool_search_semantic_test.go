@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// conceptEmbedder maps synonyms onto the same vector dimension, standing in
+// for a real embedding model.
+type conceptEmbedder struct {
+	mu       sync.Mutex
+	embedded []string
+	err      error
+}
+
+var testConcepts = [][]string{
+	{"issue", "ticket", "bug"},
+	{"email", "mail", "message"},
+	{"invoice", "billing", "payment"},
+}
+
+func (e *conceptEmbedder) EmbeddingModel() string { return "concepts" }
+
+func (e *conceptEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.mu.Lock()
+	e.embedded = append(e.embedded, texts...)
+	e.mu.Unlock()
+
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		v := make([]float64, len(testConcepts)+1)
+		v[len(testConcepts)] = 0.1 // keeps every vector non-zero
+		for _, word := range splitSearchWords(text) {
+			for dim, synonyms := range testConcepts {
+				for _, s := range synonyms {
+					if strings.HasPrefix(word, s) {
+						v[dim]++
+					}
+				}
+			}
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+func semanticTestServer(embedder Embedder, opts ...SemanticSearchOption) *Server {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("create_issue", "Open a new issue in the tracker").Discoverable("tracker"), nil)
+	server.RegisterTool(NewTool("send_email", "Send an email").Discoverable("mail"), nil)
+	server.RegisterTool(NewTool("create_invoice", "Create an invoice").Discoverable("billing"), nil)
+	server.SetSemanticToolSearch(embedder, opts...)
+	return server
+}
+
+func searchTools(t *testing.T, server *Server, query string) []SearchResult {
+	t.Helper()
+	resp, err := server.CallTool(context.Background(), ToolSearchName, map[string]any{"query": query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []SearchResult
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &results); err != nil {
+		t.Fatalf("unexpected response %q", resp.Content[0].Text)
+	}
+	return results
+}
+
+func TestSemanticToolSearchFindsSynonyms(t *testing.T) {
+	embedder := &conceptEmbedder{}
+	server := semanticTestServer(embedder, WithMinSimilarity(0.5))
+
+	results := searchTools(t, server, "file a ticket")
+	if len(results) != 1 || results[0].Name != "create_issue" {
+		t.Fatalf("expected create_issue for a synonym query, got %+v", results)
+	}
+	if results[0].Score <= 0 || results[0].Score >= 1 {
+		t.Errorf("score should be in (0, 1): %v", results[0].Score)
+	}
+
+	// Tool vectors are cached: the second search only embeds the query.
+	embedder.embedded = nil
+	searchTools(t, server, "payment")
+	if !reflect.DeepEqual(embedder.embedded, []string{"payment"}) {
+		t.Errorf("only the query should be embedded, got %q", embedder.embedded)
+	}
+
+	if results := searchTools(t, server, "send_email"); results[0].Name != "send_email" || results[0].Score != 1 {
+		t.Errorf("exact name match should score 1: %+v", results)
+	}
+}
+
+func TestSemanticToolSearchBlendsLexicalScores(t *testing.T) {
+	server := semanticTestServer(&conceptEmbedder{}, WithLexicalWeight(1))
+	results := searchTools(t, server, "tracker")
+	if results[0].Name != "create_issue" {
+		t.Errorf("lexical-only weight should rank by keyword match: %+v", results)
+	}
+	if len(results) != 1 {
+		t.Errorf("tools with no lexical match should score 0 at weight 1: %+v", results)
+	}
+}
+
+func TestSemanticToolSearchFallsBackToLexical(t *testing.T) {
+	down := errors.New("embedding service down")
+	var reported []error
+	server := semanticTestServer(&conceptEmbedder{err: down}, WithSemanticSearchErrorHandler(func(err error) {
+		reported = append(reported, err)
+	}))
+	results := searchTools(t, server, "invoice")
+	if len(results) == 0 || results[0].Name != "create_invoice" {
+		t.Errorf("expected lexical results when the embedder fails, got %+v", results)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], down) {
+		t.Errorf("expected the embedder error to be reported, got %v", reported)
+	}
+}
+
+func TestSemanticToolSearchUsesCustomIndex(t *testing.T) {
+	server := semanticTestServer(&conceptEmbedder{})
+	index := &prefixIndex{tools: make(map[string]MCPTool)}
+	server.SetToolIndex(index)
+	index.Remove("create_issue")
+
+	for _, r := range searchTools(t, server, "file a ticket") {
+		if r.Name == "create_issue" {
+			t.Fatalf("tool left out of the custom index was found: %+v", r)
+		}
+	}
+	if results := searchTools(t, server, "payment"); len(results) == 0 || results[0].Name != "create_invoice" {
+		t.Errorf("expected create_invoice from the indexed tools, got %+v", results)
+	}
+}
+
+func TestFileVectorStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.json")
+	ctx := context.Background()
+
+	store, err := NewFileVectorStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, map[string][]float64{"a": {1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileVectorStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Get(ctx, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, map[string][]float64{"a": {1, 2}}) {
+		t.Errorf("vectors not persisted: %v", got)
+	}
+
+	// A server using the reopened store does not re-embed known tools.
+	embedder := &conceptEmbedder{}
+	server := semanticTestServer(embedder, WithVectorStore(store))
+	searchTools(t, server, "mail")
+	server = semanticTestServer(embedder, WithVectorStore(mustFileVectorStore(t, path)))
+	embedder.embedded = nil
+	searchTools(t, server, "mail")
+	if len(embedder.embedded) != 1 {
+		t.Errorf("tool vectors should be loaded from the file, embedded %q", embedder.embedded)
+	}
+}
+
+func mustFileVectorStore(t *testing.T, path string) *FileVectorStore {
+	t.Helper()
+	store, err := NewFileVectorStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ToolTransform rewrites how a proxied tool is presented and called. It lets a
+// gateway rename a remote tool, hide parameters the model should not control
+// and supply their values server-side. The advertised InputSchema is rewritten
+// to match.
+type ToolTransform struct {
+	// Name replaces the tool's name, before any namespace is applied. The
+	// original name is no longer callable. Empty keeps the original.
+	Name string
+
+	// Description replaces the tool's description when not empty.
+	Description string
+
+	// Remove lists parameters to hide. They are dropped from the schema and
+	// from the arguments of every call.
+	Remove []string
+
+	// Fixed sets parameters to fixed values. They are dropped from the schema
+	// and always sent with these values, whatever the caller passed.
+	Fixed map[string]any
+
+	// Defaults supplies values for parameters the caller leaves out. They stay
+	// in the schema, with the default advertised and no longer required.
+	Defaults map[string]any
+}
+
+// ToolTransforms maps original (un-namespaced) remote tool names to their
+// transforms. Tools without an entry are passed through unchanged.
+type ToolTransforms map[string]ToolTransform
+
+// exposedName returns the name the tool called original is advertised under.
+func (t ToolTransforms) exposedName(original string) string {
+	if tr, ok := t[original]; ok && tr.Name != "" {
+		return tr.Name
+	}
+	return original
+}
+
+// Validate reports transforms that would advertise two tools under one
+// name: two tools renamed to the same name, or a tool renamed to the name of
+// another that keeps its own.
+func (t ToolTransforms) Validate() error {
+	_, err := t.reverse()
+	return err
+}
+
+// reverse returns the original names of the renamed tools, by advertised
+// name, or an error if two tools would be advertised under one name.
+func (t ToolTransforms) reverse() (map[string]string, error) {
+	exposed := make(map[string]string, len(t))
+	for _, orig := range slices.Sorted(maps.Keys(t)) {
+		name := t.exposedName(orig)
+		if other, ok := exposed[name]; ok {
+			return nil, fmt.Errorf("tool transforms: %q and %q are both advertised as %q", other, orig, name)
+		}
+		exposed[name] = orig
+	}
+	renamed := make(map[string]string)
+	for name, orig := range exposed {
+		if name != orig {
+			renamed[name] = orig
+		}
+	}
+	return renamed, nil
+}
+
+// resolve maps an advertised tool name back to the original name and its
+// transform, using renamed from reverse. ok is false for the original name
+// of a renamed tool, which is not exposed.
+func (t ToolTransforms) resolve(renamed map[string]string, name string) (original string, tr ToolTransform, ok bool) {
+	if orig, found := renamed[name]; found {
+		return orig, t[orig], true
+	}
+	if tr, found := t[name]; found {
+		return name, tr, tr.Name == "" || tr.Name == name
+	}
+	return name, ToolTransform{}, true
+}
+
+// apply returns tool as the transform presents it. The tool's name is the
+// original one and is replaced by the caller.
+func (tr ToolTransform) apply(tool MCPTool) MCPTool {
+	if tr.Description != "" {
+		tool.Description = tr.Description
+	}
+	if len(tr.Remove) > 0 || len(tr.Fixed) > 0 || len(tr.Defaults) > 0 {
+		tool.InputSchema = tr.rewriteSchema(tool.InputSchema)
+	}
+	return tool
+}
+
+// rewriteSchema returns a copy of an object schema with removed and fixed
+// parameters dropped and defaults advertised. Schemas that are not JSON
+// objects are returned unchanged.
+func (tr ToolTransform) rewriteSchema(schema any) any {
+	obj, ok := schema.(map[string]any)
+	if !ok {
+		data, err := json.Marshal(schema)
+		if err != nil || json.Unmarshal(data, &obj) != nil || obj == nil {
+			return schema
+		}
+	}
+	obj = maps.Clone(obj)
+
+	hidden := make(map[string]bool, len(tr.Remove)+len(tr.Fixed))
+	for _, name := range tr.Remove {
+		hidden[name] = true
+	}
+	for name := range tr.Fixed {
+		hidden[name] = true
+	}
+
+	if props, ok := obj["properties"].(map[string]any); ok {
+		props = maps.Clone(props)
+		for name := range hidden {
+			delete(props, name)
+		}
+		for name, value := range tr.Defaults {
+			if prop, ok := props[name].(map[string]any); ok {
+				prop = maps.Clone(prop)
+				prop["default"] = value
+				props[name] = prop
+			}
+		}
+		obj["properties"] = props
+	}
+
+	if required, ok := obj["required"].([]any); ok {
+		obj["required"] = slices.DeleteFunc(slices.Clone(required), func(v any) bool {
+			name, _ := v.(string)
+			_, hasDefault := tr.Defaults[name]
+			return hidden[name] || hasDefault
+		})
+	} else if required, ok := obj["required"].([]string); ok {
+		obj["required"] = slices.DeleteFunc(slices.Clone(required), func(name string) bool {
+			_, hasDefault := tr.Defaults[name]
+			return hidden[name] || hasDefault
+		})
+	}
+	return obj
+}
+
+// arguments returns the arguments to send for a call made with args: removed
+// parameters dropped, fixed ones set and defaults filled in. args is not
+// modified.
+func (tr ToolTransform) arguments(args map[string]any) map[string]any {
+	if len(tr.Remove) == 0 && len(tr.Fixed) == 0 && len(tr.Defaults) == 0 {
+		return args
+	}
+	out := make(map[string]any, len(args)+len(tr.Fixed)+len(tr.Defaults))
+	maps.Copy(out, args)
+	for _, name := range tr.Remove {
+		delete(out, name)
+	}
+	for name, value := range tr.Defaults {
+		if _, ok := out[name]; !ok {
+			out[name] = value
+		}
+	}
+	maps.Copy(out, tr.Fixed)
+	return out
+}
+
+// applySearchResult rewrites a tool_search result from the remote in place so
+// it describes the tool as transformed.
+func (t ToolTransforms) applySearchResult(result map[string]any) {
+	name, _ := result["name"].(string)
+	tr, ok := t[name]
+	if !ok {
+		return
+	}
+	if tr.Name != "" {
+		result["name"] = tr.Name
+	}
+	if tr.Description != "" {
+		result["description"] = tr.Description
+	}
+	for _, key := range []string{"inputSchema", "input_schema"} {
+		if schema, ok := result[key]; ok && schema != nil {
+			result[key] = tr.rewriteSchema(schema)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// transformRemote starts a server whose "search" tool echoes its arguments
+// as JSON.
+func transformRemote(t *testing.T) *httptest.Server {
+	t.Helper()
+	remote := NewServer("crm", "1.0")
+	remote.RegisterTool(NewTool("search", "Search records",
+		String("query", "What to find", Required()),
+		String("tenant", "Tenant id", Required()),
+		Number("limit", "Maximum results"),
+		Boolean("debug", "Include debug output"),
+	), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		data, _ := json.Marshal(req.Args())
+		return NewToolResponseText(string(data)), nil
+	})
+	ts := httptest.NewServer(http.HandlerFunc(remote.HandleRequest))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+var testTransforms = ToolTransforms{
+	"search": {
+		Name:        "find_customers",
+		Description: "Find customers",
+		Remove:      []string{"debug"},
+		Fixed:       map[string]any{"tenant": "acme"},
+		Defaults:    map[string]any{"limit": 10},
+	},
+}
+
+func echoedArgs(t *testing.T, resp *ToolResponse, err error) map[string]any {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(resp.Content[0].Text), &args); err != nil {
+		t.Fatalf("unexpected response %q: %v", resp.Content[0].Text, err)
+	}
+	return args
+}
+
+func TestRemoteToolTransforms(t *testing.T) {
+	ts := transformRemote(t)
+	server := NewServer("gateway", "1.0")
+	if err := server.RegisterRemoteServer(NewClient(ts.URL, nil, "crm"), WithToolTransforms(testTransforms)); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tools := server.ListTools()
+	if len(tools) != 1 || tools[0].Name != "crm__find_customers" || tools[0].Description != "Find customers" {
+		t.Fatalf("unexpected tools %+v", tools)
+	}
+	schema := tools[0].InputSchema.(map[string]any)
+	props := schema["properties"].(map[string]any)
+	if _, ok := props["tenant"]; ok {
+		t.Error("fixed parameter should be hidden")
+	}
+	if _, ok := props["debug"]; ok {
+		t.Error("removed parameter should be hidden")
+	}
+	if limit := props["limit"].(map[string]any); limit["default"] != 10 {
+		t.Errorf("default should be advertised: %v", limit)
+	}
+	if required := schema["required"]; !reflect.DeepEqual(required, []any{"query"}) {
+		t.Errorf("only query should stay required, got %v", required)
+	}
+
+	resp, err := server.CallTool(ctx, "crm__find_customers", map[string]any{"query": "bob", "tenant": "evil", "debug": true})
+	args := echoedArgs(t, resp, err)
+	want := map[string]any{"query": "bob", "tenant": "acme", "limit": float64(10)}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("remote got %v, want %v", args, want)
+	}
+
+	resp, err = server.CallTool(ctx, "crm__find_customers", map[string]any{"query": "bob", "limit": 3})
+	if args := echoedArgs(t, resp, err); args["limit"] != float64(3) {
+		t.Errorf("an explicit value should win over the default: %v", args)
+	}
+
+	if _, err := server.CallTool(ctx, "crm__search", map[string]any{"query": "bob", "tenant": "x"}); err == nil {
+		t.Error("the original name of a renamed tool should not be callable")
+	}
+}
+
+func TestRemoteProviderToolTransforms(t *testing.T) {
+	ts := transformRemote(t)
+	provider := NewRemoteProvider(func(ctx context.Context) ([]RemoteProviderConfig, error) {
+		return []RemoteProviderConfig{{Name: "crm", URL: ts.URL, Transforms: testTransforms}}, nil
+	})
+	ctx := WithToolProviders(context.Background(), provider)
+
+	tools, err := provider.GetTools(ctx)
+	if err != nil || len(tools) != 1 || tools[0].Name != "crm__find_customers" {
+		t.Fatalf("unexpected tools %+v, %v", tools, err)
+	}
+
+	server := NewServer("gateway", "1.0")
+	resp, err := server.CallTool(ctx, "crm__find_customers", map[string]any{"query": "bob"})
+	if args := echoedArgs(t, resp, err); args["tenant"] != "acme" || args["limit"] != float64(10) {
+		t.Errorf("arguments should be injected: %v", args)
+	}
+}
+
+func TestToolTransformArgumentsDoNotModifyInput(t *testing.T) {
+	in := map[string]any{"query": "x", "debug": true}
+	out := testTransforms["search"].arguments(in)
+	if len(in) != 2 || in["debug"] != true {
+		t.Errorf("input was modified: %v", in)
+	}
+	if _, ok := out["debug"]; ok || out["tenant"] != "acme" {
+		t.Errorf("unexpected arguments %v", out)
+	}
+}
+
+func TestToolTransformsRejectCollidingNames(t *testing.T) {
+	ts := transformRemote(t)
+	for name, transforms := range map[string]ToolTransforms{
+		"two renames":             {"search": {Name: "find"}, "lookup": {Name: "find"}},
+		"rename onto a kept name": {"search": {Name: "lookup"}, "lookup": {Description: "Look up"}},
+	} {
+		if err := transforms.Validate(); err == nil {
+			t.Errorf("%s: expected a collision error", name)
+		}
+		server := NewServer("gateway", "1.0")
+		if err := server.RegisterRemoteServer(NewClient("http://unused", nil, "crm"), WithToolTransforms(transforms)); err == nil {
+			t.Errorf("%s: registration should fail", name)
+		}
+		client := NewClient(ts.URL, nil, "").WithToolTransforms(transforms)
+		if _, err := client.CallTool(context.Background(), "find", nil); err == nil || !strings.Contains(err.Error(), "both advertised") {
+			t.Errorf("%s: CallTool should return the collision, got %v", name, err)
+		}
+	}
+
+	// A swap is not a collision.
+	swap := ToolTransforms{"search": {Name: "lookup"}, "lookup": {Name: "search"}}
+	if err := swap.Validate(); err != nil {
+		t.Errorf("swap: %v", err)
+	}
+
+	// A rename onto a tool the remote has without a transform fails the list.
+	client := NewClient(ts.URL, nil, "").WithToolTransforms(ToolTransforms{"other": {Name: "search"}})
+	if _, err := client.ListTools(context.Background()); err == nil {
+		t.Error("ListTools should report the rename onto an existing tool")
+	}
+}
@@ -0,0 +1,522 @@
+package toolmetadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paularlott/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// Definitions is the content of a definitions file: any mix of tools,
+// prompts, resources and resource templates. The same layout is used for
+// JSON, TOML and YAML; keys match the field names case-insensitively (e.g.
+// "tools", "resourceTemplates", "mimeType").
+type Definitions struct {
+	Tools             []ToolDefinition
+	Prompts           []PromptDefinition
+	Resources         []ResourceDefinition
+	ResourceTemplates []ResourceTemplateDefinition
+}
+
+// ToolDefinition declares a tool. Handler names the ToolHandler in the
+// Handlers registry and defaults to the tool name.
+type ToolDefinition struct {
+	Name    string
+	Handler string
+	ToolMetadata
+}
+
+// PromptArgument declares an argument of a prompt.
+type PromptArgument struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// PromptDefinition declares a prompt. Handler names the PromptHandler in the
+// Handlers registry and defaults to the prompt name.
+type PromptDefinition struct {
+	Name        string
+	Description string
+	Handler     string
+	Arguments   []PromptArgument
+}
+
+// ResourceDefinition declares a static resource. Handler names the
+// ResourceHandler in the Handlers registry and defaults to the resource name.
+type ResourceDefinition struct {
+	URI         string
+	Name        string
+	Description string
+	MimeType    string
+	Handler     string
+}
+
+// ResourceTemplateDefinition declares a resource template. Handler names the
+// ResourceHandler in the Handlers registry and defaults to the template name.
+type ResourceTemplateDefinition struct {
+	URITemplate string
+	Name        string
+	Description string
+	MimeType    string
+	Handler     string
+}
+
+// ParseDefinitions decodes definitions in the given format: "json", "toml" or
+// "yaml" (also "yml").
+func ParseDefinitions(data []byte, format string) (*Definitions, error) {
+	var raw any
+	switch strings.ToLower(format) {
+	case "json":
+		raw = json.RawMessage(data)
+	case "toml":
+		var m map[string]any
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		raw = m
+	case "yaml", "yml":
+		var m map[string]any
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		raw = m
+	default:
+		return nil, fmt.Errorf("unsupported definitions format %q", format)
+	}
+
+	// TOML and YAML are decoded generically and re-encoded as JSON so that
+	// all three formats share one set of field names.
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.DisallowUnknownFields()
+	var defs Definitions
+	if err := dec.Decode(&defs); err != nil {
+		return nil, err
+	}
+	return &defs, nil
+}
+
+// LoadDefinitionsFile reads and decodes a definitions file, choosing the
+// format from its extension (.json, .toml, .yaml or .yml).
+func LoadDefinitionsFile(path string) (*Definitions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := ParseDefinitions(data, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return defs, nil
+}
+
+// isDefinitionsFile reports whether path has a supported extension.
+func isDefinitionsFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".toml", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// Handlers maps the handler names used in definitions files to Go handlers.
+// It is safe for concurrent use.
+type Handlers struct {
+	mu        sync.RWMutex
+	tools     map[string]mcp.ToolHandler
+	prompts   map[string]mcp.PromptHandler
+	resources map[string]mcp.ResourceHandler
+	versions  map[string]int // Times each kind and name was registered, so a reload sees replaced handlers
+}
+
+// NewHandlers creates an empty handler registry.
+func NewHandlers() *Handlers {
+	return &Handlers{
+		tools:     make(map[string]mcp.ToolHandler),
+		prompts:   make(map[string]mcp.PromptHandler),
+		resources: make(map[string]mcp.ResourceHandler),
+		versions:  make(map[string]int),
+	}
+}
+
+// Tool registers a tool handler under name.
+func (h *Handlers) Tool(name string, handler mcp.ToolHandler) *Handlers {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tools[name] = handler
+	h.versions["tool\x00"+name]++
+	return h
+}
+
+// Prompt registers a prompt handler under name.
+func (h *Handlers) Prompt(name string, handler mcp.PromptHandler) *Handlers {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prompts[name] = handler
+	h.versions["prompt\x00"+name]++
+	return h
+}
+
+// Resource registers a resource handler under name, for use by resources and
+// resource templates.
+func (h *Handlers) Resource(name string, handler mcp.ResourceHandler) *Handlers {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resources[name] = handler
+	h.versions["resource\x00"+name]++
+	return h
+}
+
+// version identifies the handler registered under kind and name, changing
+// whenever it is replaced.
+func (h *Handlers) version(kind, name string) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return fmt.Sprintf("%s#%d", name, h.versions[kind+"\x00"+name])
+}
+
+func (h *Handlers) tool(name string) (mcp.ToolHandler, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	handler, ok := h.tools[name]
+	return handler, ok
+}
+
+func (h *Handlers) prompt(name string) (mcp.PromptHandler, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	handler, ok := h.prompts[name]
+	return handler, ok
+}
+
+func (h *Handlers) resource(name string) (mcp.ResourceHandler, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	handler, ok := h.resources[name]
+	return handler, ok
+}
+
+// Loader registers the definitions found in a set of files and directories on
+// a Server, binding each entry to its handler. Calling Load again (or
+// watching for changes with Watch) replaces the previous registrations:
+// entries that disappeared from the files are unregistered.
+type Loader struct {
+	server   *mcp.Server
+	handlers *Handlers
+	paths    []string
+
+	// The registrations of the last load, each with its fingerprint
+	mu                sync.Mutex
+	tools             map[string]string
+	prompts           map[string]string
+	resources         map[string]string
+	resourceTemplates []loadedTemplate // In registration order, which decides matching
+}
+
+// loadedTemplate is a resource template registered by the last load.
+type loadedTemplate struct {
+	uriTemplate string
+	fingerprint string
+}
+
+// NewLoader creates a loader for the given files and directories. Directories
+// are scanned (non-recursively) for .json, .toml, .yaml and .yml files.
+func NewLoader(server *mcp.Server, handlers *Handlers, paths ...string) *Loader {
+	return &Loader{
+		server:   server,
+		handlers: handlers,
+		paths:    paths,
+	}
+}
+
+// boundDefinitions holds the definitions of a load with their handlers
+// resolved, ready to be registered.
+type boundDefinitions struct {
+	tools             []boundTool
+	prompts           []boundPrompt
+	resources         []boundResource
+	resourceTemplates []boundResourceTemplate
+}
+
+// Each bound entry carries the fingerprint of its definition (see
+// fingerprint).
+
+type boundTool struct {
+	registration *mcp.ToolRegistration
+	fingerprint  string
+}
+
+type boundPrompt struct {
+	builder     *mcp.PromptBuilder
+	handler     mcp.PromptHandler
+	fingerprint string
+}
+
+type boundResource struct {
+	builder     *mcp.ResourceBuilder
+	handler     mcp.ResourceHandler
+	fingerprint string
+}
+
+type boundResourceTemplate struct {
+	builder     *mcp.ResourceTemplateBuilder
+	handler     mcp.ResourceHandler
+	fingerprint string
+}
+
+// Load reads every definitions file and registers its entries on the server.
+// All files are parsed and every handler resolved before the server is
+// touched, so an invalid file leaves the previous registrations in place.
+func (l *Loader) Load() error {
+	files, err := l.files()
+	if err != nil {
+		return err
+	}
+
+	var all Definitions
+	for _, file := range files {
+		defs, err := LoadDefinitionsFile(file)
+		if err != nil {
+			return err
+		}
+		all.Tools = append(all.Tools, defs.Tools...)
+		all.Prompts = append(all.Prompts, defs.Prompts...)
+		all.Resources = append(all.Resources, defs.Resources...)
+		all.ResourceTemplates = append(all.ResourceTemplates, defs.ResourceTemplates...)
+	}
+
+	bound, err := l.bind(&all)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.apply(bound)
+	return nil
+}
+
+// files returns the definitions files under the loader's paths, in a stable
+// order.
+func (l *Loader) files() ([]string, error) {
+	var files []string
+	for _, path := range l.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var dirFiles []string
+		for _, entry := range entries {
+			if !entry.IsDir() && isDefinitionsFile(entry.Name()) {
+				dirFiles = append(dirFiles, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(dirFiles)
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+// bind builds every definition and resolves its handler.
+func (l *Loader) bind(defs *Definitions) (*boundDefinitions, error) {
+	bound := &boundDefinitions{}
+	seen := make(map[string]bool)
+	unique := func(kind, name string) error {
+		if name == "" {
+			return fmt.Errorf("%s without a name", kind)
+		}
+		key := kind + "\x00" + name
+		if seen[key] {
+			return fmt.Errorf("duplicate %s %q", kind, name)
+		}
+		seen[key] = true
+		return nil
+	}
+
+	for i := range defs.Tools {
+		def := &defs.Tools[i]
+		if err := unique("tool", def.Name); err != nil {
+			return nil, err
+		}
+		handler, ok := l.handlers.tool(handlerName(def.Handler, def.Name))
+		if !ok {
+			return nil, fmt.Errorf("tool %q: no handler named %q", def.Name, handlerName(def.Handler, def.Name))
+		}
+		tool, err := BuildMCPTool(def.Name, &def.ToolMetadata)
+		if err != nil {
+			return nil, err
+		}
+		bound.tools = append(bound.tools, boundTool{
+			registration: mcp.NewToolRegistration(tool, handler),
+			fingerprint:  fingerprint(def, l.handlers.version("tool", handlerName(def.Handler, def.Name))),
+		})
+	}
+
+	for _, def := range defs.Prompts {
+		if err := unique("prompt", def.Name); err != nil {
+			return nil, err
+		}
+		handler, ok := l.handlers.prompt(handlerName(def.Handler, def.Name))
+		if !ok {
+			return nil, fmt.Errorf("prompt %q: no handler named %q", def.Name, handlerName(def.Handler, def.Name))
+		}
+		builder := mcp.NewPrompt(def.Name, def.Description)
+		for _, arg := range def.Arguments {
+			builder.Argument(arg.Name, arg.Description, arg.Required)
+		}
+		bound.prompts = append(bound.prompts, boundPrompt{
+			builder:     builder,
+			handler:     handler,
+			fingerprint: fingerprint(def, l.handlers.version("prompt", handlerName(def.Handler, def.Name))),
+		})
+	}
+
+	for _, def := range defs.Resources {
+		if def.URI == "" {
+			return nil, fmt.Errorf("resource %q without a uri", def.Name)
+		}
+		if err := unique("resource", def.URI); err != nil {
+			return nil, err
+		}
+		handler, ok := l.handlers.resource(handlerName(def.Handler, def.Name))
+		if !ok {
+			return nil, fmt.Errorf("resource %q: no handler named %q", def.URI, handlerName(def.Handler, def.Name))
+		}
+		builder := mcp.NewResource(def.URI, def.Name, def.Description, def.MimeType)
+		bound.resources = append(bound.resources, boundResource{
+			builder:     builder,
+			handler:     handler,
+			fingerprint: fingerprint(def, l.handlers.version("resource", handlerName(def.Handler, def.Name))),
+		})
+	}
+
+	for _, def := range defs.ResourceTemplates {
+		if def.URITemplate == "" {
+			return nil, fmt.Errorf("resource template %q without a uriTemplate", def.Name)
+		}
+		if err := unique("resource template", def.URITemplate); err != nil {
+			return nil, err
+		}
+		handler, ok := l.handlers.resource(handlerName(def.Handler, def.Name))
+		if !ok {
+			return nil, fmt.Errorf("resource template %q: no handler named %q", def.URITemplate, handlerName(def.Handler, def.Name))
+		}
+		builder := mcp.NewResourceTemplate(def.URITemplate, def.Name, def.Description, def.MimeType)
+		bound.resourceTemplates = append(bound.resourceTemplates, boundResourceTemplate{
+			builder:     builder,
+			handler:     handler,
+			fingerprint: fingerprint(def, l.handlers.version("resource", handlerName(def.Handler, def.Name))),
+		})
+	}
+
+	return bound, nil
+}
+
+func handlerName(handler, name string) string {
+	if handler != "" {
+		return handler
+	}
+	return name
+}
+
+// apply swaps the previously loaded registrations for bound, touching only
+// the entries that changed so clients are told once, and only if anything
+// did. Must be called with l.mu held.
+func (l *Loader) apply(bound *boundDefinitions) {
+	l.server.BatchChanges(func() {
+		tools := make(map[string]string, len(bound.tools))
+		var changedTools []*mcp.ToolRegistration
+		for _, t := range bound.tools {
+			name := t.registration.Tool.Name()
+			tools[name] = t.fingerprint
+			if l.tools[name] != t.fingerprint {
+				changedTools = append(changedTools, t.registration)
+			}
+		}
+		for name := range l.tools {
+			if _, ok := tools[name]; !ok {
+				l.server.UnregisterTool(name)
+			}
+		}
+		l.server.RegisterTools(changedTools...)
+		l.tools = tools
+
+		prompts := make(map[string]string, len(bound.prompts))
+		for _, p := range bound.prompts {
+			name := p.builder.Name()
+			prompts[name] = p.fingerprint
+			if l.prompts[name] != p.fingerprint {
+				l.server.RegisterPrompt(p.builder, p.handler)
+			}
+		}
+		for name := range l.prompts {
+			if _, ok := prompts[name]; !ok {
+				l.server.UnregisterPrompt(name)
+			}
+		}
+		l.prompts = prompts
+
+		resources := make(map[string]string, len(bound.resources))
+		for _, r := range bound.resources {
+			uri := r.builder.URI()
+			resources[uri] = r.fingerprint
+			if l.resources[uri] != r.fingerprint {
+				l.server.RegisterResource(r.builder, r.handler)
+			}
+		}
+		for uri := range l.resources {
+			if _, ok := resources[uri]; !ok {
+				l.server.UnregisterResource(uri)
+			}
+		}
+		l.resources = resources
+
+		// Templates match in registration order, so any change re-registers
+		// them all.
+		templates := make([]loadedTemplate, len(bound.resourceTemplates))
+		for i, t := range bound.resourceTemplates {
+			templates[i] = loadedTemplate{
+				uriTemplate: t.builder.URITemplate(),
+				fingerprint: t.fingerprint,
+			}
+		}
+		if !slices.Equal(templates, l.resourceTemplates) {
+			for _, old := range l.resourceTemplates {
+				l.server.UnregisterResourceTemplate(old.uriTemplate)
+			}
+			for _, t := range bound.resourceTemplates {
+				l.server.RegisterResourceTemplate(t.builder, t.handler)
+			}
+		}
+		l.resourceTemplates = templates
+	})
+}
+
+// fingerprint identifies a registration by every field of its definition and
+// the version of its handler (see Handlers.version), so a reload can tell
+// whether it changed.
+func fingerprint(definition any, handler string) string {
+	data, _ := json.Marshal(definition)
+	return handler + "\n" + string(data)
+}
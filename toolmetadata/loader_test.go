@@ -0,0 +1,360 @@
+package toolmetadata
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paularlott/mcp"
+)
+
+const tomlDefinitions = `
+[[tools]]
+name = "create_user"
+description = "Create a user"
+handler = "users.create"
+
+[[tools.parameters]]
+name = "name"
+type = "string"
+required = true
+minLength = 2
+
+[[tools.parameters]]
+name = "role"
+type = "string"
+enum = ["admin", "member"]
+default = "member"
+
+[[tools.parameters]]
+name = "address"
+type = "object"
+
+[[tools.parameters.properties]]
+name = "city"
+type = "string"
+required = true
+
+[[tools.output]]
+name = "id"
+type = "string"
+required = true
+
+[[prompts]]
+name = "welcome"
+description = "Welcome message"
+
+[[prompts.arguments]]
+name = "user"
+required = true
+
+[[resourceTemplates]]
+uriTemplate = "users://{id}"
+name = "user"
+mimeType = "application/json"
+`
+
+const yamlDefinitions = `
+tools:
+  - name: list_orders
+    description: List orders
+    parameters:
+      - name: items
+        type: array:object
+        minItems: 1
+        properties:
+          - name: sku
+            type: string
+            required: true
+resources:
+  - uri: docs://readme
+    name: readme
+    mimeType: text/plain
+`
+
+const jsonDefinitions = `{
+  "tools": [
+    {"name": "ping", "description": "Ping", "parameters": [
+      {"name": "count", "type": "integer", "minimum": 1, "maximum": 5}
+    ]}
+  ]
+}`
+
+func testHandlers() *Handlers {
+	tool := func(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+		return mcp.NewToolResponseText("ok"), nil
+	}
+	resource := func(ctx context.Context, req *mcp.ResourceRequest) (*mcp.ResourceResponse, error) {
+		return mcp.NewResourceResponseText(req.URI(), "content", "text/plain"), nil
+	}
+	return NewHandlers().
+		Tool("users.create", tool).
+		Tool("list_orders", tool).
+		Tool("ping", tool).
+		Prompt("welcome", func(ctx context.Context, req *mcp.PromptRequest) (*mcp.PromptResponse, error) {
+			return mcp.NewPromptResponseText("Welcome " + req.StringOr("user", "")), nil
+		}).
+		Resource("user", resource).
+		Resource("readme", resource)
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func toolNames(server *mcp.Server) map[string]mcp.MCPTool {
+	tools := make(map[string]mcp.MCPTool)
+	for _, tool := range server.ListTools() {
+		tools[tool.Name] = tool
+	}
+	return tools
+}
+
+func TestParseDefinitionsFormats(t *testing.T) {
+	for format, data := range map[string]string{"toml": tomlDefinitions, "yaml": yamlDefinitions, "json": jsonDefinitions} {
+		defs, err := ParseDefinitions([]byte(data), format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(defs.Tools) != 1 {
+			t.Errorf("%s: expected one tool, got %d", format, len(defs.Tools))
+		}
+	}
+
+	if _, err := ParseDefinitions([]byte(`{"tools": [{"name": "x", "unknown": 1}]}`), "json"); err == nil {
+		t.Error("expected an error for unknown keys")
+	}
+	if _, err := ParseDefinitions([]byte(`x`), "ini"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestLoaderRegistersDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "users.toml", tomlDefinitions)
+	writeFile(t, dir, "orders.yaml", yamlDefinitions)
+	writeFile(t, dir, "ping.json", jsonDefinitions)
+	writeFile(t, dir, "notes.txt", "ignored")
+
+	server := mcp.NewServer("test", "1.0")
+	if err := NewLoader(server, testHandlers(), dir).Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tools := toolNames(server)
+	create, ok := tools["create_user"]
+	if !ok || tools["list_orders"].Name == "" || tools["ping"].Name == "" {
+		t.Fatalf("missing tools: %v", tools)
+	}
+	props := create.InputSchema.(map[string]any)["properties"].(map[string]any)
+	if props["name"].(map[string]any)["minLength"] != 2 || props["role"].(map[string]any)["default"] != "member" {
+		t.Errorf("constraints not applied: %v", props)
+	}
+	if address := props["address"].(map[string]any); address["required"].([]string)[0] != "city" {
+		t.Errorf("object properties not applied: %v", address)
+	}
+	if create.OutputSchema == nil {
+		t.Error("output schema not applied")
+	}
+
+	ctx := context.Background()
+	if _, err := server.CallTool(ctx, "create_user", map[string]any{"name": "A"}); err == nil {
+		t.Error("minLength should be enforced")
+	}
+	if _, err := server.CallTool(ctx, "list_orders", map[string]any{"items": []any{map[string]any{"sku": "a"}}}); err != nil {
+		t.Errorf("list_orders: %v", err)
+	}
+
+	resp, err := server.GetPrompt(ctx, "welcome", map[string]string{"user": "Ada"})
+	if err != nil || len(resp.Messages) != 1 {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+	if len(server.ListResources(ctx)) != 1 || len(server.ListResourceTemplates(ctx)) != 1 {
+		t.Error("resources not registered")
+	}
+}
+
+func TestLoaderReloadReplacesRegistrations(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "defs.toml", tomlDefinitions)
+
+	server := mcp.NewServer("test", "1.0")
+	loader := NewLoader(server, testHandlers(), path)
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A definition with an unknown handler fails and keeps the old state.
+	writeFile(t, dir, "defs.toml", `[[tools]]
+name = "other"
+description = "Other"
+`)
+	if err := loader.Load(); err == nil || !strings.Contains(err.Error(), `no handler named "other"`) {
+		t.Fatalf("expected missing handler error, got %v", err)
+	}
+	if _, ok := toolNames(server)["create_user"]; !ok {
+		t.Fatal("failed reload should keep the previous tools")
+	}
+
+	writeFile(t, dir, "defs.toml", pingTOML)
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	tools := toolNames(server)
+	if _, ok := tools["create_user"]; ok {
+		t.Error("removed tool should be unregistered")
+	}
+	if _, ok := tools["ping"]; !ok {
+		t.Error("new tool should be registered")
+	}
+	if len(server.ListPrompts(context.Background())) != 0 || len(server.ListResourceTemplates(context.Background())) != 0 {
+		t.Error("removed prompts and templates should be unregistered")
+	}
+}
+
+func TestLoaderNotifiesOnlyOnChange(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "defs.toml", tomlDefinitions)
+
+	server := mcp.NewServer("test", "1.0")
+	loader := NewLoader(server, testHandlers(), path)
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	defer clientWriter.Close()
+	go func() { _ = server.ServeStream(context.Background(), serverReader, serverWriter) }()
+
+	var tools, prompts, resources atomic.Int32
+	client := mcp.NewStreamClient(clientReader, clientWriter, "")
+	defer client.Close()
+	client.OnToolsChanged(func() { tools.Add(1) }).
+		OnPromptsChanged(func() { prompts.Add(1) }).
+		OnResourcesChanged(func() { resources.Add(1) })
+	ctx := context.Background()
+	if err := client.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reloading unchanged files registers nothing and sends nothing.
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing one tool sends a single tools notification.
+	writeFile(t, dir, "defs.toml", strings.Replace(tomlDefinitions, `"Create a user"`, `"Create an account"`, 1))
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for tools.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := client.ListTools(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if tools.Load() != 1 || prompts.Load() != 0 || resources.Load() != 0 {
+		t.Errorf("got %d tools, %d prompts and %d resources notifications, want 1, 0 and 0",
+			tools.Load(), prompts.Load(), resources.Load())
+	}
+	if toolNames(server)["create_user"].Description != "Create an account" {
+		t.Error("changed tool not re-registered")
+	}
+}
+
+func TestLoaderReloadsDiscoverabilityAndHandlers(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "defs.toml", pingTOML)
+
+	server := mcp.NewServer("test", "1.0")
+	handlers := testHandlers()
+	loader := NewLoader(server, handlers, path)
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := toolNames(server)["ping"]; !ok {
+		t.Fatal("ping should be listed")
+	}
+
+	// Only discoverable and keywords change; neither is in the tool's JSON.
+	writeFile(t, dir, "defs.toml", pingTOML+"discoverable = true\nkeywords = [\"health\"]\n")
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := toolNames(server)["ping"]; ok {
+		t.Error("ping should have been re-registered as discoverable")
+	}
+	resp, err := server.CallTool(context.Background(), mcp.ToolSearchName, map[string]any{"query": "health"})
+	if err != nil || !strings.Contains(resp.Content[0].Text, `"ping"`) {
+		t.Errorf("ping should be found by its new keyword: %v, %v", resp, err)
+	}
+
+	// A handler replaced under the same name is picked up too.
+	handlers.Tool("ping", func(ctx context.Context, req *mcp.ToolRequest) (*mcp.ToolResponse, error) {
+		return mcp.NewToolResponseText("pong"), nil
+	})
+	if err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := server.CallTool(context.Background(), "ping", nil); err != nil || resp.Content[0].Text != "pong" {
+		t.Errorf("replaced handler not bound: %v, %v", resp, err)
+	}
+}
+
+const pingTOML = `[[tools]]
+name = "ping"
+description = "Ping"
+`
+
+func TestLoaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "defs.json", jsonDefinitions)
+
+	server := mcp.NewServer("test", "1.0")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan struct{}, 1)
+	err := NewLoader(server, testHandlers(), path).Watch(ctx,
+		WithReloadDelay(20*time.Millisecond),
+		WithReloadHandler(func() {
+			select {
+			case reloaded <- struct{}{}:
+			default:
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := toolNames(server)["ping"]; !ok {
+		t.Fatal("initial load missing")
+	}
+
+	writeFile(t, dir, "defs.json", `{"tools": [{"name": "list_orders", "description": "List orders"}]}`)
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after file change")
+	}
+	tools := toolNames(server)
+	if _, ok := tools["list_orders"]; !ok {
+		t.Error("reloaded tool missing")
+	}
+	if _, ok := tools["ping"]; ok {
+		t.Error("old tool should be removed on reload")
+	}
+}
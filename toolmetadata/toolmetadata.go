@@ -12,6 +12,24 @@ type ToolParameter struct {
 	Type        string
 	Description string
 	Required    bool
+
+	// Properties are the fields of an "object" parameter, or of each element
+	// of an "array:object" parameter. Without properties the object is generic.
+	Properties []ToolParameter
+
+	// Constraints; unset values are not emitted. On primitive arrays the
+	// value constraints (Enum through Format) apply to each element.
+	Enum        []any
+	Minimum     *float64
+	Maximum     *float64
+	MinLength   *int
+	MaxLength   *int
+	Pattern     string
+	Format      string
+	MinItems    *int
+	MaxItems    *int
+	UniqueItems bool
+	Default     any
 }
 
 // ToolMetadata defines metadata for an MCP tool
@@ -19,14 +37,18 @@ type ToolMetadata struct {
 	Description  string
 	Keywords     []string
 	Parameters   []ToolParameter
+	Output       []ToolParameter // Structured output schema, optional
 	Discoverable bool
+	Category     string   // tool_search facet, optional
+	Tags         []string // tool_search facets, optional
+	ReadOnly     bool     // Annotates the tool as not modifying anything
 }
 
 // validTypeList is a human-readable list of accepted parameter type strings,
 // used in error messages.
 const validTypeList = "string, int, integer, float, number, bool, boolean, " +
 	"array:string, array:int, array:integer, array:float, array:number, " +
-	"array:bool, array:boolean"
+	"array:bool, array:boolean, object, array:object"
 
 // BuildMCPTool creates an mcp.ToolBuilder from ToolMetadata.
 // Returns an error if any parameter declares an unknown type.
@@ -40,24 +62,51 @@ func BuildMCPTool(toolName string, meta *ToolMetadata) (*mcp.ToolBuilder, error)
 		params = append(params, p)
 	}
 
+	if len(meta.Output) > 0 {
+		output, err := convertParameters(meta.Output)
+		if err != nil {
+			return nil, fmt.Errorf("tool %q: output: %w", toolName, err)
+		}
+		params = append(params, mcp.Output(output...))
+	}
+
 	tool := mcp.NewTool(toolName, meta.Description, params...)
 
 	if meta.Discoverable {
 		tool.Discoverable(meta.Keywords...)
 	}
+	if meta.Category != "" {
+		tool.Category(meta.Category)
+	}
+	if len(meta.Tags) > 0 {
+		tool.Tags(meta.Tags...)
+	}
+	if meta.ReadOnly {
+		tool.ReadOnly()
+	}
 
 	return tool, nil
 }
 
+// convertParameters converts a list of ToolParameters into mcp.Parameters.
+func convertParameters(params []ToolParameter) ([]mcp.Parameter, error) {
+	result := make([]mcp.Parameter, 0, len(params))
+	for _, param := range params {
+		p, err := convertParameter(param)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
 // convertParameter converts a TOML ToolParameter into an mcp.Parameter.
 // "int"/"integer" and "array:int"/"array:integer" map to the integer
 // JSON Schema type, while "float"/"number" and their array variants map to
 // the number type. Unknown type strings produce an error.
 func convertParameter(param ToolParameter) (mcp.Parameter, error) {
-	var options []mcp.Option
-	if param.Required {
-		options = append(options, mcp.Required())
-	}
+	options := parameterOptions(param)
 
 	switch param.Type {
 	case "string":
@@ -76,8 +125,67 @@ func convertParameter(param ToolParameter) (mcp.Parameter, error) {
 		return mcp.NumberArray(param.Name, param.Description, options...), nil
 	case "array:bool", "array:boolean":
 		return mcp.BooleanArray(param.Name, param.Description, options...), nil
+	case "object", "array:object":
+		properties, err := convertParameters(param.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %q: %w", param.Name, err)
+		}
+		args := make([]any, 0, len(properties)+len(options))
+		for _, p := range properties {
+			args = append(args, p)
+		}
+		for _, o := range options {
+			args = append(args, o)
+		}
+		if param.Type == "object" {
+			return mcp.Object(param.Name, param.Description, args...), nil
+		}
+		return mcp.ObjectArray(param.Name, param.Description, args...), nil
 	default:
 		return nil, fmt.Errorf("parameter %q: unknown type %q. Valid types: %s",
 			param.Name, param.Type, validTypeList)
 	}
 }
+
+// parameterOptions returns the mcp options for the required flag and the
+// constraints set on param.
+func parameterOptions(param ToolParameter) []mcp.Option {
+	var options []mcp.Option
+	if param.Required {
+		options = append(options, mcp.Required())
+	}
+	if len(param.Enum) > 0 {
+		options = append(options, mcp.Enum(param.Enum...))
+	}
+	if param.Minimum != nil {
+		options = append(options, mcp.Minimum(*param.Minimum))
+	}
+	if param.Maximum != nil {
+		options = append(options, mcp.Maximum(*param.Maximum))
+	}
+	if param.MinLength != nil {
+		options = append(options, mcp.MinLength(*param.MinLength))
+	}
+	if param.MaxLength != nil {
+		options = append(options, mcp.MaxLength(*param.MaxLength))
+	}
+	if param.Pattern != "" {
+		options = append(options, mcp.Pattern(param.Pattern))
+	}
+	if param.Format != "" {
+		options = append(options, mcp.Format(param.Format))
+	}
+	if param.MinItems != nil {
+		options = append(options, mcp.MinItems(*param.MinItems))
+	}
+	if param.MaxItems != nil {
+		options = append(options, mcp.MaxItems(*param.MaxItems))
+	}
+	if param.UniqueItems {
+		options = append(options, mcp.UniqueItems())
+	}
+	if param.Default != nil {
+		options = append(options, mcp.Default(param.Default))
+	}
+	return options
+}
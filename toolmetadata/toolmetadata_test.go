@@ -172,6 +172,28 @@ func TestBuildMCPTool_Integration(t *testing.T) {
 	}
 }
 
+func TestBuildMCPTool_Facets(t *testing.T) {
+	meta := &ToolMetadata{
+		Description:  "List repositories",
+		Discoverable: true,
+		Category:     "github",
+		Tags:         []string{"repos"},
+		ReadOnly:     true,
+	}
+
+	tool, err := BuildMCPTool("list_repos", meta)
+	if err != nil {
+		t.Fatalf("BuildMCPTool returned error: %v", err)
+	}
+	mcpTool := tool.ToMCPTool()
+	if mcpTool.Category != "github" || len(mcpTool.Tags) != 1 || mcpTool.Tags[0] != "repos" {
+		t.Errorf("category and tags not set: %q %q", mcpTool.Category, mcpTool.Tags)
+	}
+	if mcpTool.Annotations == nil || !mcpTool.Annotations.ReadOnlyHint {
+		t.Errorf("read-only annotation not set: %+v", mcpTool.Annotations)
+	}
+}
+
 // TestBuildMCPTool_SchemaOutput verifies the emitted JSON Schema types, in
 // particular that "int"/"integer" -> "integer" (not "number") and that
 // "float"/"number" -> "number" (not "integer").
@@ -0,0 +1,146 @@
+package toolmetadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultReloadDelay is how long Watch waits after the last file change
+// before reloading, so that editors writing a file in several steps trigger a
+// single reload.
+const DefaultReloadDelay = 200 * time.Millisecond
+
+// WatchOption configures Watch.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	delay   time.Duration
+	onError func(error)
+	onLoad  func()
+}
+
+// WithReloadDelay sets the debounce delay between a change and the reload.
+func WithReloadDelay(d time.Duration) WatchOption {
+	return func(o *watchOptions) { o.delay = d }
+}
+
+// WithReloadErrorHandler sets the function that receives reload and watcher
+// errors. A failed reload keeps the previous registrations.
+func WithReloadErrorHandler(fn func(error)) WatchOption {
+	return func(o *watchOptions) { o.onError = fn }
+}
+
+// WithReloadHandler sets a function called after each successful reload.
+func WithReloadHandler(fn func()) WatchOption {
+	return func(o *watchOptions) { o.onLoad = fn }
+}
+
+// Watch loads the definitions and then reloads them whenever a watched file
+// changes, until ctx is cancelled. Each reload replaces the previous
+// registrations and notifies connected clients once for each list that
+// changed.
+//
+// Files are watched through their parent directory so that editors that save
+// by renaming a temporary file over the original are handled. Watch returns
+// after the initial load; watching continues in the background.
+func (l *Loader) Watch(ctx context.Context, opts ...WatchOption) error {
+	options := watchOptions{delay: DefaultReloadDelay}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if err := l.Load(); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// Directories to watch, and for each the file names of interest (nil means
+	// every definitions file in the directory).
+	dirs := make(map[string]map[string]bool)
+	for _, path := range l.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			watcher.Close()
+			return err
+		}
+		if info.IsDir() {
+			dirs[filepath.Clean(path)] = nil
+			continue
+		}
+		dir := filepath.Dir(path)
+		names, exists := dirs[dir]
+		if exists && names == nil {
+			continue
+		}
+		if names == nil {
+			names = make(map[string]bool)
+			dirs[dir] = names
+		}
+		names[filepath.Base(path)] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	relevant := func(name string) bool {
+		names, ok := dirs[filepath.Dir(name)]
+		if !ok {
+			return false
+		}
+		if names == nil {
+			return isDefinitionsFile(name)
+		}
+		return names[filepath.Base(name)]
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(options.delay)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod || !relevant(event.Name) {
+					continue
+				}
+				timer.Reset(options.delay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if options.onError != nil {
+					options.onError(err)
+				}
+			case <-timer.C:
+				if err := l.Load(); err != nil {
+					if options.onError != nil {
+						options.onError(err)
+					}
+				} else if options.onLoad != nil {
+					options.onLoad()
+				}
+			}
+		}
+	}()
+
+	return nil
+}
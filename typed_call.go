@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/paularlott/mcp/toon"
+)
+
+// ToolCallError is returned by CallTyped when the tool reports a failure with
+// an isError result. Content holds what the tool returned.
+type ToolCallError struct {
+	Tool    string
+	Content []ToolContent
+}
+
+func (e *ToolCallError) Error() string {
+	if text := firstText(e.Content); text != "" {
+		return fmt.Sprintf("tool %s failed: %s", e.Tool, text)
+	}
+	return fmt.Sprintf("tool %s failed", e.Tool)
+}
+
+// CallTyped calls the tool called name on client and decodes its result into
+// Out. The arguments are taken from in, which may be nil, a map, or anything
+// that encodes as a JSON object, such as a struct with json tags; tools
+// created with NewTypedTool accept their In type here.
+//
+// The result is decoded from structuredContent when the tool returns it, and
+// otherwise from the first text content, read as JSON or else as TOON. A
+// string Out receives the text as it is. A result with neither decodes to the
+// zero Out.
+//
+// A failure reported with isError is returned as a *ToolCallError, and a
+// JSON-RPC error from the server as a *ToolError; use errors.As to inspect
+// either.
+//
+//	weather, err := mcp.CallTyped[WeatherOut](ctx, client, "weather", WeatherIn{City: "Oslo"})
+func CallTyped[Out any](ctx context.Context, client *Client, name string, in any) (Out, error) {
+	var out Out
+
+	args, err := typedArguments(in)
+	if err != nil {
+		return out, fmt.Errorf("encode arguments for %s: %w", name, err)
+	}
+
+	resp, err := client.CallTool(ctx, name, args)
+	if err != nil {
+		return out, err
+	}
+	if resp.IsError {
+		return out, &ToolCallError{Tool: name, Content: resp.Content}
+	}
+
+	if err := decodeTypedResult(resp, &out); err != nil {
+		return out, fmt.Errorf("decode result of %s: %w", name, err)
+	}
+	return out, nil
+}
+
+// typedArguments converts in to a tool call's arguments.
+func typedArguments(in any) (map[string]any, error) {
+	switch v := in.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return v, nil
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	var args map[string]any
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, errors.New("arguments must encode as a JSON object")
+	}
+	return args, nil
+}
+
+// decodeTypedResult decodes resp into out, which points to the caller's Out.
+func decodeTypedResult(resp *ToolResponse, out any) error {
+	if resp.StructuredContent != nil {
+		return remarshal(resp.StructuredContent, out)
+	}
+
+	text := firstText(resp.Content)
+	if s, ok := out.(*string); ok {
+		*s = text
+		return nil
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	jsonErr := json.Unmarshal([]byte(text), out)
+	if jsonErr == nil {
+		return nil
+	}
+	value, err := toon.Decode(text)
+	if err != nil {
+		return jsonErr
+	}
+	return remarshal(value, out)
+}
+
+// remarshal decodes value into out by way of its JSON encoding.
+func remarshal(value any, out any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// firstText returns the text of the first text content, or "".
+func firstText(content []ToolContent) string {
+	for _, c := range content {
+		if c.Type == "text" {
+			return c.Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type forecastIn struct {
+	City string `json:"city"`
+	Days int    `json:"days,omitempty"`
+}
+
+type forecastOut struct {
+	City  string    `json:"city"`
+	Temps []float64 `json:"temps"`
+}
+
+func typedCallClient(t *testing.T) *Client {
+	t.Helper()
+	server := NewServer("typed", "1")
+	server.RegisterTool(NewTypedTool("structured", "Structured", func(ctx context.Context, in forecastIn) (forecastOut, error) {
+		return forecastOut{City: in.City, Temps: make([]float64, in.Days)}, nil
+	}))
+	forecast := forecastOut{City: "Oslo", Temps: []float64{1.5, -2}}
+	server.RegisterTool(NewTool("json", "JSON text"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseJSON(forecast), nil
+	})
+	server.RegisterTool(NewTool("toon", "TOON text"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseTOON(forecast), nil
+	})
+	server.RegisterTool(NewTool("text", "Plain text"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseText("hello there"), nil
+	})
+	server.RegisterTool(NewTool("soft", "isError"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return NewToolResponseError("city not found"), nil
+	})
+	server.RegisterTool(NewTool("hard", "ToolError"), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		return nil, NewToolErrorInvalidParams("bad city")
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(server.HandleRequest))
+	t.Cleanup(ts.Close)
+	return NewClient(ts.URL, nil, "")
+}
+
+func TestCallTyped(t *testing.T) {
+	client := typedCallClient(t)
+	ctx := context.Background()
+
+	out, err := CallTyped[forecastOut](ctx, client, "structured", forecastIn{City: "Bergen", Days: 3})
+	if err != nil || out.City != "Bergen" || len(out.Temps) != 3 {
+		t.Errorf("structured: %+v, %v", out, err)
+	}
+
+	for _, name := range []string{"json", "toon"} {
+		out, err := CallTyped[forecastOut](ctx, client, name, nil)
+		if err != nil || out.City != "Oslo" || len(out.Temps) != 2 || out.Temps[0] != 1.5 || out.Temps[1] != -2 {
+			t.Errorf("%s: %+v, %v", name, out, err)
+		}
+	}
+
+	if text, err := CallTyped[string](ctx, client, "text", map[string]any{}); err != nil || text != "hello there" {
+		t.Errorf("text: %q, %v", text, err)
+	}
+}
+
+func TestCallTypedErrors(t *testing.T) {
+	client := typedCallClient(t)
+	ctx := context.Background()
+
+	_, err := CallTyped[forecastOut](ctx, client, "soft", nil)
+	var callErr *ToolCallError
+	if !errors.As(err, &callErr) || callErr.Tool != "soft" || !strings.Contains(err.Error(), "city not found") {
+		t.Errorf("isError: %v", err)
+	}
+
+	_, err = CallTyped[forecastOut](ctx, client, "hard", nil)
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInvalidParams {
+		t.Errorf("ToolError: %v", err)
+	}
+
+	if _, err := CallTyped[forecastOut](ctx, client, "json", []string{"not", "an", "object"}); err == nil {
+		t.Error("non-object arguments were accepted")
+	}
+	if _, err := CallTyped[forecastOut](ctx, client, "text", nil); err == nil {
+		t.Error("plain text decoded into a struct")
+	}
+}
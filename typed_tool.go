@@ -0,0 +1,308 @@
+package mcp
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedToolHandler handles calls to a tool created with NewTypedTool. The
+// arguments are decoded into In, and the returned Out becomes the response.
+type TypedToolHandler[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// NewTypedTool creates a tool whose input and output schemas are derived from
+// the In and Out types, and a handler that decodes the call arguments into In
+// and converts the returned Out into the response. The results can be passed
+// straight to RegisterTool:
+//
+//	type WeatherIn struct {
+//		City  string `json:"city" description:"City name" required:"true"`
+//		Units string `json:"units,omitempty" enum:"metric,imperial"`
+//	}
+//	type WeatherOut struct {
+//		Temperature float64   `json:"temperature"`
+//		ObservedAt  time.Time `json:"observed_at"`
+//	}
+//
+//	server.RegisterTool(mcp.NewTypedTool("weather", "Get the weather", getWeather))
+//
+// Schemas follow encoding/json: the json tag sets the property name ("-"
+// skips the field) and embedded structs are flattened. Further tags are:
+//   - description: the property description
+//   - enum: comma-separated allowed values (applied to the elements of slices)
+//   - required: "true" to mark the property as required
+//
+// Nested structs, slices, arrays, maps with string keys, pointers and
+// time.Time (a "date-time" string) are supported. Pointer, slice and map
+// fields also accept null, as that is how encoding/json writes nil values.
+// When Out is a struct or map it is returned as structuredContent and
+// advertised as the output schema; other types are returned as text. A nil
+// Out is returned as an empty object.
+func NewTypedTool[In, Out any](name, description string, handler TypedToolHandler[In, Out]) (*ToolBuilder, ToolHandler) {
+	inType := reflect.TypeFor[In]()
+	outType := reflect.TypeFor[Out]()
+
+	inputSchema := typeSchema(inType)
+	if inputSchema["type"] != "object" {
+		// Tool arguments are always an object; a non-struct In cannot be described.
+		inputSchema = map[string]any{"type": "object", "additionalProperties": true}
+	}
+
+	structured := isObjectType(outType)
+	var outputSchema map[string]any
+	if structured {
+		outputSchema = typeSchema(outType)
+	}
+
+	tool := &ToolBuilder{
+		name:         name,
+		description:  description,
+		inputSchema:  inputSchema,
+		outputSchema: outputSchema,
+	}
+
+	return tool, func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		var in In
+		if args := req.Args(); len(args) > 0 {
+			data, err := json.Marshal(args)
+			if err != nil {
+				return nil, NewToolErrorInvalidParams("invalid arguments: " + err.Error())
+			}
+			if err := json.Unmarshal(data, &in); err != nil {
+				return nil, NewToolErrorInvalidParams("invalid arguments: " + err.Error())
+			}
+		}
+
+		out, err := handler(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+
+		if structured {
+			// A nil pointer or map would encode as null, which is not an object
+			if v := reflect.ValueOf(out); (v.Kind() == reflect.Pointer || v.Kind() == reflect.Map) && v.IsNil() {
+				return NewToolResponseStructured(map[string]any{}), nil
+			}
+			return NewToolResponseStructured(out), nil
+		}
+		return NewToolResponseAuto(out), nil
+	}
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// isObjectType reports whether values of t encode as JSON objects.
+func isObjectType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return !t.Implements(jsonMarshalerType) && !reflect.PointerTo(t).Implements(jsonMarshalerType)
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String
+	}
+	return false
+}
+
+// typeSchema returns the JSON Schema describing how encoding/json encodes
+// values of type t.
+func typeSchema(t reflect.Type) map[string]any {
+	return (&typeSchemaBuilder{visiting: make(map[reflect.Type]bool)}).schema(t)
+}
+
+type typeSchemaBuilder struct {
+	visiting map[reflect.Type]bool
+}
+
+func (b *typeSchemaBuilder) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": FormatDateTime}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		// Custom JSON encoding; the shape is unknown.
+		return map[string]any{}
+	}
+	if t.Kind() != reflect.String && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes []byte as a base64 string
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": b.valueSchema(t.Elem())}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String && !t.Key().Implements(textMarshalerType) {
+			return map[string]any{}
+		}
+		return map[string]any{"type": "object", "additionalProperties": b.valueSchema(t.Elem())}
+	case reflect.Struct:
+		return b.structSchema(t)
+	}
+	// Interfaces and anything else accept any JSON value.
+	return map[string]any{}
+}
+
+// valueSchema is schema for a value nested in a struct, slice or map, where
+// nil pointers, slices and maps encode as null and so null is allowed too.
+func (b *typeSchemaBuilder) valueSchema(t reflect.Type) map[string]any {
+	schema := b.schema(t)
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []any{typ, "null"}
+		}
+	}
+	return schema
+}
+
+// allowsNull reports whether schema lists "null" among its types.
+func allowsNull(schema map[string]any) bool {
+	types, _ := schema["type"].([]any)
+	for _, typ := range types {
+		if typ == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *typeSchemaBuilder) structSchema(t reflect.Type) map[string]any {
+	if b.visiting[t] {
+		// Recursive type; stop descending.
+		return map[string]any{"type": "object"}
+	}
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+
+	properties := make(map[string]any)
+	var required []string
+	b.addFields(t, properties, &required)
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the properties for the exported fields of struct type t,
+// flattening untagged embedded structs the way encoding/json does.
+func (b *typeSchemaBuilder) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	// Embedded structs are added after the direct fields so that an outer
+	// field shadows an embedded one with the same name.
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, exists := properties[name]; exists {
+			continue
+		}
+
+		prop := b.valueSchema(field.Type)
+		if desc := field.Tag.Get("description"); desc != "" {
+			prop["description"] = desc
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			target := prop
+			if items, ok := prop["items"].(map[string]any); ok {
+				target = items
+			}
+			values := parseEnumTag(enum, field.Type)
+			if allowsNull(target) {
+				values = append(values, nil)
+			}
+			target["enum"] = values
+		}
+		properties[name] = prop
+
+		if req, _ := strconv.ParseBool(field.Tag.Get("required")); req {
+			*required = append(*required, name)
+		}
+	}
+
+	for _, ft := range embedded {
+		b.addFields(ft, properties, required)
+	}
+}
+
+// parseEnumTag splits a comma-separated enum tag and converts each value to
+// the JSON type of t (or of its elements for slices and arrays).
+func parseEnumTag(tag string, t reflect.Type) []any {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	parts := strings.Split(tag, ",")
+	values := make([]any, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		var value any = part
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if f, err := strconv.ParseFloat(part, 64); err == nil {
+				value = f
+			}
+		case reflect.Bool:
+			if v, err := strconv.ParseBool(part); err == nil {
+				value = v
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
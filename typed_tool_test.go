@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type typedAddress struct {
+	City string `json:"city" description:"City name" required:"true"`
+	Zip  *int   `json:"zip,omitempty"`
+}
+
+type typedBase struct {
+	ID    string `json:"id" required:"true"`
+	Notes string `json:"notes"`
+}
+
+type typedInput struct {
+	typedBase
+	Notes    []string          `json:"notes,omitempty" enum:"a,b"`
+	Level    int               `json:"level" enum:"1,2,3"`
+	Address  *typedAddress     `json:"address,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Since    time.Time         `json:"since"`
+	Payload  []byte            `json:"payload,omitempty"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+type typedOutput struct {
+	Echo  string    `json:"echo" required:"true"`
+	Count int       `json:"count"`
+	At    time.Time `json:"at"`
+}
+
+func TestTypedToolSchemas(t *testing.T) {
+	tool, _ := NewTypedTool("typed", "Typed tool", func(ctx context.Context, in typedInput) (typedOutput, error) {
+		return typedOutput{}, nil
+	})
+
+	schema := tool.BuildSchema()
+	props := schema["properties"].(map[string]any)
+	for _, name := range []string{"id", "notes", "level", "address", "labels", "since", "payload"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("missing property %q", name)
+		}
+	}
+	for _, name := range []string{"Ignored", "internal", "typedBase"} {
+		if _, ok := props[name]; ok {
+			t.Errorf("unexpected property %q", name)
+		}
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"id"}) {
+		t.Errorf("unexpected required: %v", schema["required"])
+	}
+
+	notes := props["notes"].(map[string]any)
+	if !reflect.DeepEqual(notes["type"], []any{"array", "null"}) || !reflect.DeepEqual(notes["items"].(map[string]any)["enum"], []any{"a", "b"}) {
+		t.Errorf("outer field should shadow embedded one, got %v", notes)
+	}
+	if level := props["level"].(map[string]any); !reflect.DeepEqual(level["enum"], []any{1.0, 2.0, 3.0}) {
+		t.Errorf("unexpected level enum: %v", level)
+	}
+	address := props["address"].(map[string]any)
+	if !reflect.DeepEqual(address["type"], []any{"object", "null"}) || !reflect.DeepEqual(address["required"], []string{"city"}) {
+		t.Errorf("unexpected address schema: %v", address)
+	}
+	if city := address["properties"].(map[string]any)["city"].(map[string]any); city["description"] != "City name" {
+		t.Errorf("missing description: %v", city)
+	}
+	if labels := props["labels"].(map[string]any); labels["additionalProperties"].(map[string]any)["type"] != "string" {
+		t.Errorf("unexpected labels schema: %v", labels)
+	}
+	if since := props["since"].(map[string]any); since["type"] != "string" || since["format"] != "date-time" {
+		t.Errorf("unexpected since schema: %v", since)
+	}
+
+	out := tool.BuildOutputSchema()
+	if out == nil || !reflect.DeepEqual(out["required"], []string{"echo"}) {
+		t.Errorf("unexpected output schema: %v", out)
+	}
+}
+
+func TestTypedToolCall(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.SetOutputValidation(OutputValidationError)
+	server.RegisterTool(NewTypedTool("typed", "Typed tool", func(ctx context.Context, in typedInput) (*typedOutput, error) {
+		if in.Address == nil || in.Address.City != "Paris" {
+			return nil, errors.New("address not decoded")
+		}
+		return &typedOutput{Echo: in.ID, Count: len(in.Notes), At: in.Since}, nil
+	}))
+
+	resp, err := server.CallTool(context.Background(), "typed", map[string]any{
+		"id":      "x1",
+		"notes":   []any{"a", "b"},
+		"address": map[string]any{"city": "Paris"},
+		"since":   "2026-01-02T03:04:05Z",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, ok := resp.StructuredContent.(*typedOutput)
+	if !ok || out.Echo != "x1" || out.Count != 2 || out.At.Year() != 2026 {
+		t.Fatalf("unexpected structured content: %#v", resp.StructuredContent)
+	}
+
+	_, err = server.CallTool(context.Background(), "typed", map[string]any{"id": "x1", "level": 7})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInvalidParams {
+		t.Fatalf("expected enum violation, got %v", err)
+	}
+}
+
+func TestTypedToolScalarOutput(t *testing.T) {
+	tool, handler := NewTypedTool("greet", "Greet", func(ctx context.Context, in struct {
+		Name string `json:"name"`
+	}) (string, error) {
+		return "Hello, " + in.Name, nil
+	})
+	if tool.BuildOutputSchema() != nil {
+		t.Fatal("scalar output should not have an output schema")
+	}
+	resp, err := handler(context.Background(), NewToolRequest(map[string]any{"name": "Ada"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StructuredContent != nil || len(resp.Content) != 1 || resp.Content[0].Text != "Hello, Ada" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+type typedNode struct {
+	Value    string       `json:"value"`
+	Children []*typedNode `json:"children,omitempty"`
+}
+
+func TestTypedToolRecursiveType(t *testing.T) {
+	schema := typeSchema(reflect.TypeFor[typedNode]())
+	children := schema["properties"].(map[string]any)["children"].(map[string]any)
+	if !reflect.DeepEqual(children["items"].(map[string]any)["type"], []any{"object", "null"}) {
+		t.Fatalf("unexpected recursive schema: %v", children)
+	}
+}
+
+type typedList struct {
+	Items  []string       `json:"items" required:"true"`
+	Counts map[string]int `json:"counts"`
+	Next   *string        `json:"next" enum:"a,b"`
+}
+
+func TestTypedToolNilValues(t *testing.T) {
+	server := NewServer("test", "1.0")
+	server.SetOutputValidation(OutputValidationError)
+	server.RegisterTool(NewTypedTool("list", "List tool", func(ctx context.Context, in typedList) (typedList, error) {
+		if in.Next != nil {
+			return typedList{}, errors.New("null should decode as a nil pointer")
+		}
+		return typedList{Items: nil}, nil
+	}))
+
+	resp, err := server.CallTool(context.Background(), "list", map[string]any{"items": []any{}, "next": nil})
+	if err != nil {
+		t.Fatalf("nil values should be valid input and output: %v", err)
+	}
+	if out, ok := resp.StructuredContent.(typedList); !ok || out.Items != nil {
+		t.Fatalf("unexpected structured content: %#v", resp.StructuredContent)
+	}
+
+	_, err = server.CallTool(context.Background(), "list", map[string]any{"items": []any{}, "next": "c"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorCodeInvalidParams {
+		t.Fatalf("expected enum violation, got %v", err)
+	}
+}
+
+func TestTypedToolNilOutput(t *testing.T) {
+	_, pointer := NewTypedTool("pointer", "", func(ctx context.Context, in struct{}) (*typedOutput, error) {
+		return nil, nil
+	})
+	_, mapped := NewTypedTool("map", "", func(ctx context.Context, in struct{}) (map[string]int, error) {
+		return nil, nil
+	})
+	for name, handler := range map[string]ToolHandler{"pointer": pointer, "map": mapped} {
+		resp, err := handler(context.Background(), NewToolRequest(nil))
+		if err != nil || !reflect.DeepEqual(resp.StructuredContent, map[string]any{}) {
+			t.Errorf("%s: nil output should be an empty object, got %#v, %v", name, resp, err)
+		}
+	}
+}
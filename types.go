@@ -62,12 +62,31 @@ type serverInfo struct {
 }
 
 type MCPTool struct {
-	Name         string         `json:"name"`
-	Description  string         `json:"description"`
-	InputSchema  any            `json:"inputSchema"`
-	OutputSchema any            `json:"outputSchema,omitempty"`
-	Keywords     []string       `json:"-"` // For discovery search, not serialized to clients
-	Visibility   ToolVisibility `json:"-"` // Native or Discoverable
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	InputSchema  any              `json:"inputSchema"`
+	OutputSchema any              `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+	Keywords     []string         `json:"-"` // For discovery search, not serialized to clients
+	Category     string           `json:"-"` // tool_search facet
+	Tags         []string         `json:"-"` // tool_search facets
+	Visibility   ToolVisibility   `json:"-"` // Native or Discoverable
+}
+
+// ToolAnnotations are hints about a tool's behaviour, as defined by the MCP
+// specification. They are hints only: clients should not rely on them for
+// tools from servers they do not trust.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint,omitempty"`    // The tool does not modify its environment
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"` // Updates may be destructive; the spec default is true
+	IdempotentHint  bool   `json:"idempotentHint,omitempty"`  // Repeated calls with the same arguments have no further effect
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`   // The tool interacts with external entities; the spec default is true
+}
+
+// readOnly reports whether the tool is annotated as read-only.
+func (t *MCPTool) readOnly() bool {
+	return t.Annotations != nil && t.Annotations.ReadOnlyHint
 }
 
 type ToolCallParams struct {
@@ -104,20 +123,24 @@ type ResourceContent struct {
 // A resource is data the server can serve to clients by URI, such as a file,
 // a configuration document, or a database record.
 type MCPResource struct {
-	URI         string `json:"uri"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	MimeType    string `json:"mimeType,omitempty"`
+	URI         string         `json:"uri"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	MimeType    string         `json:"mimeType,omitempty"`
+	Keywords    []string       `json:"-"` // For resource_search, not serialized to clients
+	Visibility  ToolVisibility `json:"-"` // Native or Discoverable
 }
 
 // MCPResourceTemplate describes a parameterized resource exposed via
 // resources/templates/list. The URITemplate may contain {var} placeholders
 // (RFC 6570 level 1) that clients expand to concrete URIs and then read.
 type MCPResourceTemplate struct {
-	URITemplate string `json:"uriTemplate"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	MimeType    string `json:"mimeType,omitempty"`
+	URITemplate string         `json:"uriTemplate"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	MimeType    string         `json:"mimeType,omitempty"`
+	Keywords    []string       `json:"-"` // For resource_search, not serialized to clients
+	Visibility  ToolVisibility `json:"-"` // Native or Discoverable
 }
 
 // resourceReadParams is the params object for resources/read.
@@ -131,6 +154,8 @@ type MCPPrompt struct {
 	Name        string              `json:"name"`
 	Description string              `json:"description,omitempty"`
 	Arguments   []MCPPromptArgument `json:"arguments,omitempty"`
+	Keywords    []string            `json:"-"` // For prompt_search, not serialized to clients
+	Visibility  ToolVisibility      `json:"-"` // Native or Discoverable
 }
 
 // MCPPromptArgument describes one argument a prompt accepts.
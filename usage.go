@@ -0,0 +1,268 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ToolUsage holds the usage counts recorded for one tool.
+type ToolUsage struct {
+	Impressions int64 `json:"impressions"` // Times the tool was returned by tool_search
+	Selections  int64 `json:"selections"`  // execute_tool calls following a search that returned it
+	Calls       int64 `json:"calls"`       // Calls made directly or through execute_tool
+	Errors      int64 `json:"errors"`      // Calls that failed or returned isError
+}
+
+// ErrorRate returns the fraction of calls that failed.
+func (u ToolUsage) ErrorRate() float64 {
+	if u.Calls == 0 {
+		return 0
+	}
+	return float64(u.Errors) / float64(u.Calls)
+}
+
+// SelectionRate returns the fraction of search impressions that were followed
+// by an execute_tool call of the tool.
+func (u ToolUsage) SelectionRate() float64 {
+	if u.Impressions == 0 {
+		return 0
+	}
+	return float64(u.Selections) / float64(u.Impressions)
+}
+
+// UsageStore records tool usage for SetUsageStore. Implementations must be
+// safe for concurrent use; a store shared by several servers (a database, for
+// example) aggregates their usage.
+type UsageStore interface {
+	// RecordImpressions counts one impression for each tool returned by a
+	// tool_search call.
+	RecordImpressions(ctx context.Context, tools []string) error
+
+	// RecordCall counts a call of tool. selected is set when the call was made
+	// through execute_tool after a search that returned the tool, and failed
+	// when the call returned an error or an isError result.
+	RecordCall(ctx context.Context, tool string, selected, failed bool) error
+
+	// Usage returns the counts of every tool recorded so far.
+	Usage(ctx context.Context) (map[string]ToolUsage, error)
+}
+
+// MemoryUsageStore is the in-memory UsageStore. Counts are lost on restart.
+type MemoryUsageStore struct {
+	mu    sync.Mutex
+	usage map[string]*ToolUsage
+}
+
+// NewMemoryUsageStore creates an empty in-memory usage store.
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{usage: make(map[string]*ToolUsage)}
+}
+
+func (m *MemoryUsageStore) tool(name string) *ToolUsage {
+	u, ok := m.usage[name]
+	if !ok {
+		u = &ToolUsage{}
+		m.usage[name] = u
+	}
+	return u
+}
+
+// RecordImpressions implements UsageStore.
+func (m *MemoryUsageStore) RecordImpressions(ctx context.Context, tools []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range tools {
+		m.tool(name).Impressions++
+	}
+	return nil
+}
+
+// RecordCall implements UsageStore.
+func (m *MemoryUsageStore) RecordCall(ctx context.Context, tool string, selected, failed bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u := m.tool(tool)
+	u.Calls++
+	if selected {
+		u.Selections++
+	}
+	if failed {
+		u.Errors++
+	}
+	return nil
+}
+
+// Usage implements UsageStore.
+func (m *MemoryUsageStore) Usage(ctx context.Context) (map[string]ToolUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage := make(map[string]ToolUsage, len(m.usage))
+	for name, u := range m.usage {
+		usage[name] = *u
+	}
+	return usage, nil
+}
+
+const (
+	// usageSelectionWindow is how long after a tool_search an execute_tool
+	// call still counts as selecting one of its results.
+	usageSelectionWindow = 10 * time.Minute
+
+	// usagePriorImpressions smooths the selection rate used for boosting, so
+	// a tool chosen once from a single impression is not ranked first.
+	usagePriorImpressions = 5
+)
+
+// usageTracker links tool_search results to the execute_tool calls that
+// follow them within a session, and passes the counts to the store.
+type usageTracker struct {
+	store UsageStore
+	boost float64 // Weight of the selection rate in search scores; 0 for none
+
+	mu        sync.Mutex
+	surfaced  map[string]map[string]time.Time // Session -> tool -> last returned by tool_search
+	lastPrune time.Time
+}
+
+func newUsageTracker(store UsageStore, boost float64) *usageTracker {
+	return &usageTracker{store: store, boost: boost, surfaced: make(map[string]map[string]time.Time)}
+}
+
+// recordSearch records the impressions of the tools a search returned.
+func (u *usageTracker) recordSearch(ctx context.Context, results []SearchResult) {
+	if len(results) == 0 {
+		return
+	}
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+	}
+
+	// Without a session a later call cannot be tied to this search, and
+	// sessionless clients would otherwise share one pool of selections.
+	if session := sessionIDFromContext(ctx); session != "" {
+		now := time.Now()
+		u.mu.Lock()
+		if now.Sub(u.lastPrune) > usageSelectionWindow {
+			u.pruneLocked(now)
+		}
+		tools, ok := u.surfaced[session]
+		if !ok {
+			tools = make(map[string]time.Time)
+			u.surfaced[session] = tools
+		}
+		for _, name := range names {
+			tools[name] = now
+		}
+		u.mu.Unlock()
+	}
+
+	_ = u.store.RecordImpressions(ctx, names)
+}
+
+// pruneLocked forgets search results older than the selection window.
+func (u *usageTracker) pruneLocked(now time.Time) {
+	for session, tools := range u.surfaced {
+		for name, at := range tools {
+			if now.Sub(at) > usageSelectionWindow {
+				delete(tools, name)
+			}
+		}
+		if len(tools) == 0 {
+			delete(u.surfaced, session)
+		}
+	}
+	u.lastPrune = now
+}
+
+// recordCall records a call of tool. A call through execute_tool selects the
+// tool if a recent search in the same session returned it; each search
+// result can be selected once. Calls without a session never select.
+func (u *usageTracker) recordCall(ctx context.Context, tool string, viaExecuteTool bool, resp *ToolResponse, err error) {
+	selected := false
+	if session := sessionIDFromContext(ctx); viaExecuteTool && session != "" {
+		u.mu.Lock()
+		if at, ok := u.surfaced[session][tool]; ok {
+			selected = time.Since(at) <= usageSelectionWindow
+			delete(u.surfaced[session], tool)
+		}
+		u.mu.Unlock()
+	}
+	failed := err != nil || (resp != nil && resp.IsError)
+	_ = u.store.RecordCall(ctx, tool, selected, failed)
+}
+
+// boostResults raises the scores of tools that searches often lead to, then
+// re-sorts results. A score s becomes s + boost*rate*(1-s), where rate is the
+// smoothed selection rate, so scores stay within 0 to 1.
+func (u *usageTracker) boostResults(ctx context.Context, results []SearchResult) {
+	if u.boost <= 0 || len(results) == 0 {
+		return
+	}
+	usage, err := u.store.Usage(ctx)
+	if err != nil {
+		return
+	}
+	for i := range results {
+		tu, ok := usage[results[i].Name]
+		if !ok || tu.Selections == 0 {
+			continue
+		}
+		rate := float64(tu.Selections) / float64(tu.Impressions+usagePriorImpressions)
+		results[i].Score += u.boost * min(rate, 1) * (1 - results[i].Score)
+	}
+	sortSearchResults(results)
+}
+
+// SetUsageStore turns on usage analytics, recording tool_search impressions,
+// calls, errors and which search results are followed by an execute_tool call
+// in store. NewMemoryUsageStore gives an in-memory store. Pass nil to turn
+// analytics off, which is the default.
+//
+// boost is the weight, from 0 to 1, given to how often searches lead to each
+// tool when ranking tool_search results. 0 leaves scores unchanged; 1 moves
+// the score of a tool that every search led to most of the way to 1.
+func (s *Server) SetUsageStore(store UsageStore, boost float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if store == nil {
+		s.usage = nil
+		return
+	}
+	s.usage = newUsageTracker(store, min(max(boost, 0), 1))
+}
+
+// ToolUsage returns the usage recorded for each tool, or nil if usage
+// analytics are off. See SetUsageStore.
+func (s *Server) ToolUsage(ctx context.Context) (map[string]ToolUsage, error) {
+	usage := s.usageTracker()
+	if usage == nil {
+		return nil, nil
+	}
+	return usage.store.Usage(ctx)
+}
+
+func (s *Server) usageTracker() *usageTracker {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.usage
+}
+
+// sessionIDKey is the context key for the MCP session ID of a request.
+type sessionIDKey struct{}
+
+// withSessionID attaches the MCP session ID of a request to ctx.
+func withSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, sessionID)
+}
+
+// sessionIDFromContext returns the MCP session ID attached to ctx, or "" for
+// requests without sessions (stdio, or HTTP without a session manager).
+func sessionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	return id
+}
+
+// executeToolKey marks the context of a call made through execute_tool.
+type executeToolKey struct{}
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func usageTestServer() *Server {
+	server := NewServer("test", "1.0")
+	server.RegisterTool(NewTool("send_email", "Send an email message").Discoverable("mail"),
+		func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+			return NewToolResponseText("sent"), nil
+		})
+	server.RegisterTool(NewTool("send_sms", "Send an SMS message").Discoverable("text"),
+		func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+			return nil, errors.New("gateway down")
+		})
+	server.SetUsageStore(NewMemoryUsageStore(), 0)
+	return server
+}
+
+func TestToolUsageRecording(t *testing.T) {
+	server := usageTestServer()
+	ctx := withSessionID(context.Background(), "s1")
+
+	if _, err := server.CallTool(ctx, ToolSearchName, map[string]any{"query": "send"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.CallTool(ctx, ExecuteToolName, map[string]any{"name": "send_email"}); err != nil {
+		t.Fatal(err)
+	}
+	// A second call is not a second selection of the same search result.
+	server.CallTool(ctx, ExecuteToolName, map[string]any{"name": "send_email"})
+	server.CallTool(ctx, "send_sms", nil)
+
+	usage, err := server.ToolUsage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email, sms := usage["send_email"], usage["send_sms"]
+	if email != (ToolUsage{Impressions: 1, Selections: 1, Calls: 2}) {
+		t.Errorf("unexpected send_email usage %+v", email)
+	}
+	if sms != (ToolUsage{Impressions: 1, Calls: 1, Errors: 1}) {
+		t.Errorf("unexpected send_sms usage %+v", sms)
+	}
+	if email.SelectionRate() != 1 || sms.ErrorRate() != 1 {
+		t.Errorf("unexpected rates %v %v", email.SelectionRate(), sms.ErrorRate())
+	}
+	if _, ok := usage[ToolSearchName]; ok {
+		t.Error("meta-tools should not be recorded")
+	}
+}
+
+func TestToolUsageSessions(t *testing.T) {
+	server := usageTestServer()
+	a := withSessionID(context.Background(), "a")
+	b := withSessionID(context.Background(), "b")
+
+	server.CallTool(a, ToolSearchName, map[string]any{"query": "email"})
+	server.CallTool(b, ExecuteToolName, map[string]any{"name": "send_email"})
+
+	usage, _ := server.ToolUsage(context.Background())
+	if usage["send_email"].Selections != 0 {
+		t.Errorf("a call in another session should not select: %+v", usage["send_email"])
+	}
+
+	// Without a session searches and calls cannot be paired up.
+	none := context.Background()
+	server.CallTool(none, ToolSearchName, map[string]any{"query": "email"})
+	server.CallTool(none, ExecuteToolName, map[string]any{"name": "send_email"})
+
+	usage, _ = server.ToolUsage(context.Background())
+	if email := usage["send_email"]; email.Selections != 0 || email.Impressions != 2 || email.Calls != 2 {
+		t.Errorf("sessionless calls should be counted but never select: %+v", email)
+	}
+}
+
+func TestToolUsageBoost(t *testing.T) {
+	server := usageTestServer()
+	store := NewMemoryUsageStore()
+	server.SetUsageStore(store, 1)
+	ctx := withSessionID(context.Background(), "s1")
+
+	search := func() []SearchResult {
+		resp, err := server.CallTool(ctx, ToolSearchName, map[string]any{"query": "send message"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var results []SearchResult
+		if err := json.Unmarshal([]byte(resp.Content[0].Text), &results); err != nil {
+			t.Fatalf("unexpected response %q", resp.Content[0].Text)
+		}
+		return results
+	}
+
+	before := search()
+	if len(before) != 2 {
+		t.Fatalf("expected 2 results, got %+v", before)
+	}
+	loser := before[1].Name
+	for range 5 {
+		search()
+		server.CallTool(ctx, ExecuteToolName, map[string]any{"name": loser})
+	}
+
+	after := search()
+	if after[0].Name != loser {
+		t.Errorf("frequently chosen %s should rank first, got %+v", loser, after)
+	}
+	if after[0].Score > 1 {
+		t.Errorf("boosted score should stay within 1, got %v", after[0].Score)
+	}
+}
+
+func TestToolUsageOff(t *testing.T) {
+	server := usageTestServer()
+	server.SetUsageStore(nil, 0)
+	server.CallTool(context.Background(), "send_email", nil)
+	if usage, err := server.ToolUsage(context.Background()); usage != nil || err != nil {
+		t.Errorf("usage should be nil when off, got %v, %v", usage, err)
+	}
+}
@@ -2,6 +2,9 @@ package mcp
 
 // ToolVisibility defines how a tool is exposed to clients.
 // This controls whether tools appear in tools/list or only via tool_search.
+// Resources and prompts use the same values: discoverable ones are left out
+// of resources/list and prompts/list and found with resource_search and
+// prompt_search.
 type ToolVisibility int
 
 const (
@@ -29,3 +32,11 @@ func (v ToolVisibility) String() string {
 		return "unknown"
 	}
 }
+
+// visibilityFor returns the visibility for a builder's discoverable flag.
+func visibilityFor(discoverable bool) ToolVisibility {
+	if discoverable {
+		return ToolVisibilityDiscoverable
+	}
+	return ToolVisibilityNative
+}